@@ -0,0 +1,419 @@
+// Command dagjose inspects and converts DAG-JOSE blocks, and verifies or decrypts the JWS and JWE objects they contain.
+//
+// Usage:
+//
+//	dagjose inspect [-from format] [file]
+//	dagjose cid [-from format] [-hash name] [file]
+//	dagjose convert [-from format] [-to format] [file]
+//	dagjose verify [-from format] [-jwks file] [-payload cid] [file]
+//	dagjose decrypt [-from format] -jwks file [file]
+//
+// Input is read from the given file, or from standard input if no file or "-" is given. Input formats are `cbor` (a
+// DAG-JOSE block), `json` (the general or flattened JSON serialization) and `compact`. Output formats are the same, plus
+// `flattened` for the flattened JSON serialization.
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/ceramicnetwork/go-dag-jose/dagjose"
+	"github.com/ipfs/go-cid"
+	"github.com/ipld/go-ipld-prime/codec/dagjson"
+	"github.com/ipld/go-ipld-prime/datamodel"
+	"github.com/ipld/go-ipld-prime/node/basicnode"
+	"github.com/multiformats/go-multihash"
+)
+
+const dagJOSECodec = 0x85
+
+const usage = `usage: dagjose <command> [flags] [file]
+
+Commands:
+  inspect   print a JWS or JWE as DAG-JSON, with decoded protected headers
+  cid       print the CID of a DAG-JOSE block
+  convert   convert between cbor, json, flattened and compact serializations
+  verify    verify the signatures of a JWS with keys from a JWK Set or did:key
+  decrypt   decrypt a JWE with keys from a JWK Set and print the cleartext as DAG-JSON
+
+Run 'dagjose <command> -h' for the flags of a command.
+`
+
+// errUsage is returned for invalid command lines, after the usage has been printed.
+var errUsage = errors.New("invalid usage")
+
+func main() {
+	if err := run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr); errors.Is(err, errUsage) || errors.Is(err, flag.ErrHelp) {
+		os.Exit(2)
+	} else if err != nil {
+		fmt.Fprintf(os.Stderr, "dagjose: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string, stdin io.Reader, stdout io.Writer, stderr io.Writer) error {
+	if len(args) == 0 {
+		fmt.Fprint(stderr, usage)
+		return errUsage
+	}
+	cmd := command{name: args[0], stdin: stdin, stdout: stdout}
+	cmd.flags = flag.NewFlagSet("dagjose "+cmd.name, flag.ContinueOnError)
+	cmd.flags.SetOutput(stderr)
+	cmd.flags.StringVar(&cmd.from, "from", "cbor", "input `format`: cbor, json or compact")
+	switch cmd.name {
+	case "inspect":
+		return cmd.run(args[1:], cmd.inspect)
+	case "cid":
+		cmd.flags.StringVar(&cmd.hash, "hash", "sha2-256", "multihash function `name`")
+		return cmd.run(args[1:], cmd.cid)
+	case "convert":
+		cmd.flags.StringVar(&cmd.to, "to", "json", "output `format`: cbor, json, flattened or compact")
+		return cmd.run(args[1:], cmd.convert)
+	case "verify":
+		cmd.flags.StringVar(&cmd.jwks, "jwks", "", "JWK Set `file` with the verification keys (did:key `kid` values are always resolved)")
+		cmd.flags.StringVar(&cmd.payload, "payload", "", "`cid` of the payload of a JWS with a detached payload, e.g. one signed with b64=false")
+		return cmd.run(args[1:], cmd.verify)
+	case "decrypt":
+		cmd.flags.StringVar(&cmd.jwks, "jwks", "", "JWK Set `file` with the decryption keys (required)")
+		return cmd.run(args[1:], cmd.decrypt)
+	default:
+		fmt.Fprintf(stderr, "unknown command: %s\n\n%s", cmd.name, usage)
+		return errUsage
+	}
+}
+
+type command struct {
+	name   string
+	flags  *flag.FlagSet
+	stdin  io.Reader
+	stdout io.Writer
+
+	from    string
+	to      string
+	hash    string
+	jwks    string
+	payload string
+}
+
+func (c *command) run(args []string, action func(input []byte) error) error {
+	if err := c.flags.Parse(args); err != nil {
+		return err
+	} else if c.flags.NArg() > 1 {
+		fmt.Fprintf(c.flags.Output(), "too many arguments\n")
+		c.flags.Usage()
+		return errUsage
+	}
+	var input []byte
+	var err error
+	if path := c.flags.Arg(0); path == "" || path == "-" {
+		input, err = io.ReadAll(c.stdin)
+	} else {
+		input, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return err
+	}
+	return action(input)
+}
+
+// readNode parses the input in the format selected with `-from`.
+func (c *command) readNode(input []byte) (datamodel.Node, error) {
+	return c.readDetachedNode(input, cid.Undef)
+}
+
+// readDetachedNode is like readNode, but parses JSON and compact input with a detached payload if the given payload CID
+// is defined.
+func (c *command) readDetachedNode(input []byte, payload cid.Cid) (datamodel.Node, error) {
+	switch c.from {
+	case "cbor":
+		nb := basicnode.Prototype.Any.NewBuilder()
+		if err := dagjose.Decode(nb, bytes.NewReader(input)); err != nil {
+			return nil, err
+		}
+		return nb.Build(), nil
+	case "json":
+		if payload.Defined() {
+			return dagjose.FromDetachedJSON(bytes.NewReader(input), payload)
+		}
+		return dagjose.FromJSON(bytes.NewReader(input))
+	case "compact":
+		compact := strings.TrimSpace(string(input))
+		switch strings.Count(compact, ".") {
+		case 2:
+			if payload.Defined() {
+				return dagjose.ParseDetachedCompactJWS(compact, payload)
+			}
+			return dagjose.ParseCompactJWS(compact)
+		case 4:
+			return dagjose.ParseCompactJWE(compact)
+		default:
+			return nil, errors.New("invalid compact serialization")
+		}
+	default:
+		return nil, fmt.Errorf("unsupported input format: %s", c.from)
+	}
+}
+
+func (c *command) inspect(input []byte) error {
+	n, err := c.readNode(input)
+	if err != nil {
+		return err
+	}
+	// Use the representation produced by Decode, in which the binary fields are base64url strings
+	buf := bytes.Buffer{}
+	if err := dagjose.Encode(n, &buf); err != nil {
+		return err
+	}
+	nb := basicnode.Prototype.Any.NewBuilder()
+	if err := dagjose.Decode(nb, &buf); err != nil {
+		return err
+	}
+	if n, err = withDecodedHeaders(nb.Build()); err != nil {
+		return err
+	}
+	return writeDAGJSON(c.stdout, n)
+}
+
+func (c *command) cid(input []byte) error {
+	code, found := multihash.Names[c.hash]
+	if !found {
+		return fmt.Errorf("unknown multihash function: %s", c.hash)
+	}
+	if c.from != "cbor" {
+		// Compute the CID of the block this object would be stored as
+		if n, err := c.readNode(input); err != nil {
+			return err
+		} else {
+			buf := bytes.Buffer{}
+			if err := dagjose.Encode(n, &buf); err != nil {
+				return err
+			}
+			input = buf.Bytes()
+		}
+	} else if _, err := c.readNode(input); err != nil {
+		return err
+	}
+	sum, err := cid.Prefix{Version: 1, Codec: dagJOSECodec, MhType: code, MhLength: -1}.Sum(input)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(c.stdout, sum.String())
+	return err
+}
+
+func (c *command) convert(input []byte) error {
+	n, err := c.readNode(input)
+	if err != nil {
+		return err
+	}
+	switch c.to {
+	case "cbor":
+		return dagjose.Encode(n, c.stdout)
+	case "json", "flattened":
+		mode := dagjose.FlattenNever
+		if c.to == "flattened" {
+			mode = dagjose.FlattenAlways
+		}
+		if out, err := dagjose.ToJSON(n, mode); err != nil {
+			return err
+		} else {
+			return writeIndentedJSON(c.stdout, out)
+		}
+	case "compact":
+		var compact string
+		if isJWE(n) {
+			compact, err = dagjose.ToCompactJWE(n)
+		} else {
+			compact, err = dagjose.ToCompactJWS(n)
+		}
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(c.stdout, compact)
+		return err
+	default:
+		return fmt.Errorf("unsupported output format: %s", c.to)
+	}
+}
+
+func (c *command) verify(input []byte) error {
+	payload := cid.Undef
+	if c.payload != "" {
+		if parsed, err := cid.Decode(c.payload); err != nil {
+			return fmt.Errorf("invalid payload CID: %w", err)
+		} else {
+			payload = parsed
+		}
+	}
+	n, err := c.readDetachedNode(input, payload)
+	if err != nil {
+		return err
+	}
+	resolver := dagjose.ChainedKeyResolver{dagjose.DIDKeyResolver{}}
+	if c.jwks != "" {
+		if jwks, err := dagjose.NewJWKSetFileResolver(c.jwks); err != nil {
+			return err
+		} else {
+			resolver = append(resolver, jwks)
+		}
+	}
+	var results []dagjose.SignatureVerification
+	if payload.Defined() {
+		results, err = dagjose.VerifyDetachedJWSWithResolver(context.Background(), n, payload, resolver)
+	} else {
+		results, err = dagjose.VerifyJWSWithResolver(context.Background(), n, resolver)
+	}
+	if err != nil {
+		return err
+	}
+	invalid := 0
+	for idx, result := range results {
+		if result.Valid() {
+			fmt.Fprintf(c.stdout, "signature %d: valid\n", idx)
+		} else {
+			fmt.Fprintf(c.stdout, "signature %d: invalid: %v\n", idx, result.Err)
+			invalid++
+		}
+	}
+	if invalid > 0 {
+		return fmt.Errorf("%d of %d signatures are invalid", invalid, len(results))
+	}
+	return nil
+}
+
+func (c *command) decrypt(input []byte) error {
+	if c.jwks == "" {
+		return errors.New("-jwks is required")
+	}
+	n, err := c.readNode(input)
+	if err != nil {
+		return err
+	}
+	resolver, err := dagjose.NewJWKSetFileResolver(c.jwks)
+	if err != nil {
+		return err
+	}
+	nb := basicnode.Prototype.Any.NewBuilder()
+	if err := dagjose.DecryptJWE(context.Background(), n, resolver, nb); err != nil {
+		return err
+	}
+	return writeDAGJSON(c.stdout, nb.Build())
+}
+
+func isJWE(n datamodel.Node) bool {
+	ciphertext, err := n.LookupByString("ciphertext")
+	return err == nil && !ciphertext.IsAbsent()
+}
+
+// withDecodedHeaders returns a copy of the given JWS or JWE, in the representation produced by Decode, with every
+// `protected` field replaced by the header parameters it contains.
+func withDecodedHeaders(n datamodel.Node) (datamodel.Node, error) {
+	nb := basicnode.Prototype.Map.NewBuilder()
+	ma, err := nb.BeginMap(n.Length())
+	if err != nil {
+		return nil, err
+	}
+	itr := n.MapIterator()
+	for !itr.Done() {
+		k, v, err := itr.Next()
+		if err != nil {
+			return nil, err
+		}
+		key, err := k.AsString()
+		if err != nil {
+			return nil, err
+		}
+		switch key {
+		case "protected":
+			v, err = decodeHeader(v, isJWE(n))
+		case "signatures":
+			v, err = withDecodedSignatureHeaders(v)
+		}
+		if err != nil {
+			return nil, err
+		} else if err := ma.AssembleKey().AssignString(key); err != nil {
+			return nil, err
+		} else if err := ma.AssembleValue().AssignNode(v); err != nil {
+			return nil, err
+		}
+	}
+	if err := ma.Finish(); err != nil {
+		return nil, err
+	}
+	return nb.Build(), nil
+}
+
+func withDecodedSignatureHeaders(signatures datamodel.Node) (datamodel.Node, error) {
+	nb := basicnode.Prototype.List.NewBuilder()
+	la, err := nb.BeginList(signatures.Length())
+	if err != nil {
+		return nil, err
+	}
+	itr := signatures.ListIterator()
+	for !itr.Done() {
+		idx, sig, err := itr.Next()
+		if err != nil {
+			return nil, err
+		}
+		if decoded, err := withDecodedHeaders(sig); err != nil {
+			return nil, fmt.Errorf("signature %d: %w", idx, err)
+		} else if err := la.AssembleValue().AssignNode(decoded); err != nil {
+			return nil, err
+		}
+	}
+	if err := la.Finish(); err != nil {
+		return nil, err
+	}
+	return nb.Build(), nil
+}
+
+// decodeHeader returns the parameters of the given base64url encoded protected header of a JWE or JWS signature.
+func decodeHeader(protected datamodel.Node, jwe bool) (datamodel.Node, error) {
+	encoded, err := protected.AsString()
+	if err != nil {
+		return nil, fmt.Errorf("invalid protected header: %w", err)
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("invalid protected header: %w", err)
+	}
+	var header *dagjose.ProtectedHeader
+	if jwe {
+		header, err = (&dagjose.JWE{Protected: raw}).ProtectedHeader()
+	} else {
+		header, err = (&dagjose.Signature{Protected: raw}).ProtectedHeader()
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid protected header: %w", err)
+	} else if n, err := header.Node(); err != nil {
+		return nil, fmt.Errorf("invalid protected header: %w", err)
+	} else {
+		return n, nil
+	}
+}
+
+func writeDAGJSON(w io.Writer, n datamodel.Node) error {
+	buf := bytes.Buffer{}
+	if err := dagjson.Encode(n, &buf); err != nil {
+		return err
+	}
+	return writeIndentedJSON(w, buf.Bytes())
+}
+
+func writeIndentedJSON(w io.Writer, compact []byte) error {
+	buf := bytes.Buffer{}
+	if err := json.Indent(&buf, compact, "", "  "); err != nil {
+		return err
+	}
+	buf.WriteByte('\n')
+	_, err := buf.WriteTo(w)
+	return err
+}
@@ -0,0 +1,211 @@
+package main
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ceramicnetwork/go-dag-jose/dagjose"
+	gojose "github.com/go-jose/go-jose/v4"
+	"github.com/ipfs/go-cid"
+	"github.com/ipld/go-ipld-prime/fluent"
+	"github.com/ipld/go-ipld-prime/node/basicnode"
+	"github.com/stretchr/testify/require"
+)
+
+func writeTemp(t *testing.T, name string, data []byte) string {
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, data, 0o600))
+	return path
+}
+
+func runCommand(t *testing.T, stdin []byte, args ...string) (string, error) {
+	stdout, stderr := bytes.Buffer{}, bytes.Buffer{}
+	err := run(args, bytes.NewReader(stdin), &stdout, &stderr)
+	return stdout.String(), err
+}
+
+// fixtures returns a signed JWS block, an encrypted JWE block and a JWK Set file with the keys for both
+func fixtures(t *testing.T) ([]byte, []byte, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	jwks, err := dagjose.MarshalJWKSet([]gojose.JSONWebKey{{Key: key, KeyID: "key"}})
+	require.NoError(t, err)
+
+	jws, err := dagjose.SignCID(cid.NewCidV1(cid.Raw, []byte{0, 0}), dagjose.Signer{Algorithm: gojose.ES256, Key: key, KeyID: "key"})
+	require.NoError(t, err)
+	jwsBlock := bytes.Buffer{}
+	require.NoError(t, dagjose.Encode(jws, &jwsBlock))
+
+	cleartext := fluent.MustBuildMap(basicnode.Prototype.Map, 1, func(ma fluent.MapAssembler) {
+		ma.AssembleEntry("secret").AssignString("hello")
+	})
+	jwe, err := dagjose.EncryptNode(cleartext, dagjose.EncryptOptions{},
+		dagjose.Recipient{Algorithm: gojose.ECDH_ES_A256KW, Key: &key.PublicKey, KeyID: "key"},
+	)
+	require.NoError(t, err)
+	jweBlock := bytes.Buffer{}
+	require.NoError(t, dagjose.Encode(jwe, &jweBlock))
+	return jwsBlock.Bytes(), jweBlock.Bytes(), writeTemp(t, "keys.json", jwks)
+}
+
+func TestInspect(t *testing.T) {
+	jws, jwe, _ := fixtures(t)
+	out, err := runCommand(t, jws, "inspect")
+	require.NoError(t, err)
+	require.Contains(t, out, `"alg": "ES256"`)
+	require.Contains(t, out, `"kid": "key"`)
+	require.Contains(t, out, `"link"`)
+
+	out, err = runCommand(t, nil, "inspect", writeTemp(t, "jwe.cbor", jwe))
+	require.NoError(t, err)
+	require.Contains(t, out, `"enc": "A256GCM"`)
+
+	_, err = runCommand(t, []byte("not a block"), "inspect")
+	require.Error(t, err)
+}
+
+// A block whose protected header is not JSON is still valid DAG-JOSE, and should be reported as an error
+func TestInspectInvalidProtectedHeader(t *testing.T) {
+	link := cid.NewCidV1(cid.Raw, []byte{0, 0})
+	jws := fluent.MustBuildMap(basicnode.Prototype.Map, 3, func(ma fluent.MapAssembler) {
+		ma.AssembleEntry("payload").AssignString(base64.RawURLEncoding.EncodeToString(link.Bytes()))
+		ma.AssembleEntry("protected").AssignString(base64.RawURLEncoding.EncodeToString([]byte("not json")))
+		ma.AssembleEntry("signature").AssignString(base64.RawURLEncoding.EncodeToString([]byte("signature")))
+	})
+	block := bytes.Buffer{}
+	require.NoError(t, dagjose.Encode(jws, &block))
+
+	_, err := runCommand(t, block.Bytes(), "inspect")
+	require.ErrorContains(t, err, "invalid protected header")
+}
+
+// Protected headers are plain JSON, so objects that look like DAG-JSON links or bytes should be printed as they are
+func TestInspectProtectedHeaderIsJSON(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	jws, err := dagjose.SignCID(cid.NewCidV1(cid.Raw, []byte{0, 0}), dagjose.Signer{
+		Algorithm: gojose.ES256,
+		Key:       key,
+		Protected: map[string]interface{}{"link": map[string]interface{}{"/": "not a cid"}},
+	})
+	require.NoError(t, err)
+	block := bytes.Buffer{}
+	require.NoError(t, dagjose.Encode(jws, &block))
+
+	out, err := runCommand(t, block.Bytes(), "inspect")
+	require.NoError(t, err)
+	require.Contains(t, out, `"/": "not a cid"`)
+}
+
+func TestCID(t *testing.T) {
+	jws, _, _ := fixtures(t)
+	out, err := runCommand(t, jws, "cid")
+	require.NoError(t, err)
+	c, err := cid.Decode(strings.TrimSpace(out))
+	require.NoError(t, err)
+	expected, err := cid.Prefix{Version: 1, Codec: dagJOSECodec, MhType: 0x12, MhLength: -1}.Sum(jws)
+	require.NoError(t, err)
+	require.Equal(t, expected, c)
+
+	out, err = runCommand(t, jws, "cid", "-hash", "sha2-512")
+	require.NoError(t, err)
+	c, err = cid.Decode(strings.TrimSpace(out))
+	require.NoError(t, err)
+	require.Equal(t, uint64(0x13), c.Prefix().MhType)
+
+	_, err = runCommand(t, jws, "cid", "-hash", "unknown")
+	require.Error(t, err)
+}
+
+// Converting through every serialization should produce the original block
+func TestConvertRoundTrip(t *testing.T) {
+	jws, jwe, _ := fixtures(t)
+	for _, block := range [][]byte{jws, jwe} {
+		from := "cbor"
+		input := block
+		for _, to := range []string{"json", "flattened", "compact", "cbor"} {
+			out, err := runCommand(t, input, "convert", "-from", from, "-to", to)
+			require.NoError(t, err, "%s to %s", from, to)
+			input, from = []byte(out), to
+			if to == "flattened" {
+				from = "json"
+			}
+		}
+		require.Equal(t, block, input)
+	}
+}
+
+func TestVerify(t *testing.T) {
+	jws, jwe, jwks := fixtures(t)
+	out, err := runCommand(t, jws, "verify", "-jwks", jwks)
+	require.NoError(t, err)
+	require.Equal(t, "signature 0: valid\n", out)
+
+	// Without the JWK Set, the key cannot be resolved
+	out, err = runCommand(t, jws, "verify")
+	require.Error(t, err)
+	require.Contains(t, out, "signature 0: invalid")
+
+	_, err = runCommand(t, jwe, "verify", "-jwks", jwks)
+	require.Error(t, err)
+}
+
+// A JWS signed with `b64: false` is exported with a detached payload, which is supplied with -payload
+func TestVerifyDetachedPayload(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	jwks, err := dagjose.MarshalJWKSet([]gojose.JSONWebKey{{Key: key, KeyID: "key"}})
+	require.NoError(t, err)
+	path := writeTemp(t, "keys.json", jwks)
+	payload := cid.NewCidV1(cid.Raw, []byte{0, 0})
+	jws, err := dagjose.SignCID(payload, dagjose.Signer{
+		Algorithm: gojose.ES256,
+		Key:       key,
+		KeyID:     "key",
+		Protected: map[string]interface{}{"b64": false, "crit": []string{"b64"}},
+	})
+	require.NoError(t, err)
+	compact, err := dagjose.ToCompactJWS(jws)
+	require.NoError(t, err)
+	detachedJSON, err := dagjose.ToJSON(jws, dagjose.FlattenAlways)
+	require.NoError(t, err)
+
+	for from, input := range map[string][]byte{"compact": []byte(compact), "json": detachedJSON} {
+		out, err := runCommand(t, input, "verify", "-from", from, "-jwks", path, "-payload", payload.String())
+		require.NoError(t, err, from)
+		require.Equal(t, "signature 0: valid\n", out)
+
+		_, err = runCommand(t, input, "verify", "-from", from, "-jwks", path)
+		require.Error(t, err, from)
+		_, err = runCommand(t, input, "verify", "-from", from, "-jwks", path, "-payload", cid.NewCidV1(cid.Raw, []byte{0, 1}).String())
+		require.Error(t, err, from)
+	}
+	_, err = runCommand(t, []byte(compact), "verify", "-from", "compact", "-payload", "not a cid")
+	require.ErrorContains(t, err, "invalid payload CID")
+}
+
+func TestDecrypt(t *testing.T) {
+	_, jwe, jwks := fixtures(t)
+	out, err := runCommand(t, jwe, "decrypt", "-jwks", jwks)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"secret":"hello"}`, out)
+
+	_, err = runCommand(t, jwe, "decrypt")
+	require.Error(t, err)
+}
+
+func TestUsage(t *testing.T) {
+	_, err := runCommand(t, nil)
+	require.ErrorIs(t, err, errUsage)
+	_, err = runCommand(t, nil, "unknown")
+	require.ErrorIs(t, err, errUsage)
+	_, err = runCommand(t, nil, "inspect", "a", "b")
+	require.ErrorIs(t, err, errUsage)
+}
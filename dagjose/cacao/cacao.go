@@ -0,0 +1,260 @@
+// Package cacao verifies Chain Agnostic Capability Objects (CACAO) referenced by DAG-JOSE JWS objects.
+//
+// A JWS signed with a session key carries a `cap` protected header parameter with the `ipfs://` URI of a CACAO, in
+// which an Ethereum or Solana account delegates authority to the `did:key` of the session key by signing a Sign-In
+// with Ethereum (SIWE) or Sign-In with Solana (SIWS) message.
+// See: https://chainagnostic.org/CAIPs/caip-74
+package cacao
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/ceramicnetwork/go-dag-jose/dagjose"
+	"github.com/ipfs/go-cid"
+	"github.com/ipld/go-ipld-prime"
+	"github.com/ipld/go-ipld-prime/datamodel"
+	"github.com/ipld/go-ipld-prime/fluent"
+	cidlink "github.com/ipld/go-ipld-prime/linking/cid"
+	"github.com/ipld/go-ipld-prime/node/basicnode"
+)
+
+const (
+	// HeaderTypeEIP4361 is the header type of CACAOs signed with a Sign-In with Ethereum message.
+	HeaderTypeEIP4361 = "eip4361"
+	// HeaderTypeCAIP122 is the header type of CACAOs signed with a Sign-In with X message, used here for Solana.
+	HeaderTypeCAIP122 = "caip122"
+	// SignatureTypeEIP191 is the signature type of Ethereum `personal_sign` signatures.
+	SignatureTypeEIP191 = "eip191"
+	// SignatureTypeSolanaEd25519 is the signature type of Solana Ed25519 signatures.
+	SignatureTypeSolanaEd25519 = "solana:ed25519"
+
+	capabilityURIPrefix = "ipfs://"
+)
+
+// Cacao is a Chain Agnostic Capability Object.
+type Cacao struct {
+	// Header is the `h` field.
+	Header Header
+	// Payload is the `p` field, i.e. the fields of the signed message.
+	Payload Payload
+	// Signature is the `s` field.
+	Signature Signature
+}
+
+// Header is the header of a CACAO.
+type Header struct {
+	// Type is the `t` field, e.g. HeaderTypeEIP4361.
+	Type string
+}
+
+// Payload contains the fields of the message signed by the issuer of a CACAO. Optional fields are empty if absent.
+type Payload struct {
+	// Domain is the `domain` field, i.e. the domain requesting the signature.
+	Domain string
+	// Issuer is the `iss` field, i.e. the did:pkh of the signing account.
+	Issuer string
+	// Audience is the `aud` field, i.e. the DID that authority is delegated to.
+	Audience string
+	// Version is the `version` field.
+	Version string
+	// Nonce is the `nonce` field.
+	Nonce string
+	// IssuedAt is the `iat` field, as an RFC 3339 timestamp.
+	IssuedAt string
+	// NotBefore is the optional `nbf` field, as an RFC 3339 timestamp.
+	NotBefore string
+	// ExpirationTime is the optional `exp` field, as an RFC 3339 timestamp.
+	ExpirationTime string
+	// Statement is the optional `statement` field.
+	Statement string
+	// RequestID is the optional `requestId` field.
+	RequestID string
+	// Resources is the optional `resources` field, i.e. the URIs the capability applies to.
+	Resources []string
+}
+
+// Signature is the signature of a CACAO.
+type Signature struct {
+	// Type is the `t` field, e.g. SignatureTypeEIP191.
+	Type string
+	// Signature is the `s` field.
+	Signature []byte
+}
+
+// FromNode parses a CACAO from its IPLD representation.
+func FromNode(n datamodel.Node) (*Cacao, error) {
+	c := &Cacao{}
+	if h, err := lookupMap(n, "h"); err != nil {
+		return nil, err
+	} else if c.Header.Type, err = lookupString(h, "t", true); err != nil {
+		return nil, err
+	}
+	p, err := lookupMap(n, "p")
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range []struct {
+		key      string
+		value    *string
+		required bool
+	}{
+		{"domain", &c.Payload.Domain, true},
+		{"iss", &c.Payload.Issuer, true},
+		{"aud", &c.Payload.Audience, true},
+		{"version", &c.Payload.Version, true},
+		{"nonce", &c.Payload.Nonce, true},
+		{"iat", &c.Payload.IssuedAt, true},
+		{"nbf", &c.Payload.NotBefore, false},
+		{"exp", &c.Payload.ExpirationTime, false},
+		{"statement", &c.Payload.Statement, false},
+		{"requestId", &c.Payload.RequestID, false},
+	} {
+		if *f.value, err = lookupString(p, f.key, f.required); err != nil {
+			return nil, err
+		}
+	}
+	if resources, err := lookupOptional(p, "resources"); err != nil {
+		return nil, err
+	} else if resources != nil {
+		if resources.Kind() != datamodel.Kind_List {
+			return nil, errors.New("invalid CACAO: `resources` is not a list")
+		}
+		c.Payload.Resources = make([]string, 0, resources.Length())
+		itr := resources.ListIterator()
+		for !itr.Done() {
+			_, v, err := itr.Next()
+			if err != nil {
+				return nil, err
+			}
+			if resource, err := v.AsString(); err != nil {
+				return nil, fmt.Errorf("invalid CACAO: `resources` entry is not a string: %w", err)
+			} else {
+				c.Payload.Resources = append(c.Payload.Resources, resource)
+			}
+		}
+	}
+	if s, err := lookupMap(n, "s"); err != nil {
+		return nil, err
+	} else if c.Signature.Type, err = lookupString(s, "t", true); err != nil {
+		return nil, err
+	} else if signature, err := s.LookupByString("s"); err != nil {
+		return nil, fmt.Errorf("invalid CACAO: missing `s.s` field: %w", err)
+	} else if c.Signature.Signature, err = signature.AsBytes(); err != nil {
+		return nil, fmt.Errorf("invalid CACAO: `s.s` is not bytes: %w", err)
+	}
+	return c, nil
+}
+
+// ToNode returns the IPLD representation of the CACAO, which is stored as DAG-CBOR.
+func (c *Cacao) ToNode() (datamodel.Node, error) {
+	return fluent.BuildMap(basicnode.Prototype.Map, 3, func(ma fluent.MapAssembler) {
+		ma.AssembleEntry("h").CreateMap(1, func(ma fluent.MapAssembler) {
+			ma.AssembleEntry("t").AssignString(c.Header.Type)
+		})
+		ma.AssembleEntry("p").CreateMap(-1, func(ma fluent.MapAssembler) {
+			for _, f := range []struct{ key, value string }{
+				{"domain", c.Payload.Domain},
+				{"iss", c.Payload.Issuer},
+				{"aud", c.Payload.Audience},
+				{"version", c.Payload.Version},
+				{"nonce", c.Payload.Nonce},
+				{"iat", c.Payload.IssuedAt},
+				{"nbf", c.Payload.NotBefore},
+				{"exp", c.Payload.ExpirationTime},
+				{"statement", c.Payload.Statement},
+				{"requestId", c.Payload.RequestID},
+			} {
+				if f.value != "" {
+					ma.AssembleEntry(f.key).AssignString(f.value)
+				}
+			}
+			if c.Payload.Resources != nil {
+				ma.AssembleEntry("resources").CreateList(int64(len(c.Payload.Resources)), func(la fluent.ListAssembler) {
+					for _, resource := range c.Payload.Resources {
+						la.AssembleValue().AssignString(resource)
+					}
+				})
+			}
+		})
+		ma.AssembleEntry("s").CreateMap(2, func(ma fluent.MapAssembler) {
+			ma.AssembleEntry("t").AssignString(c.Signature.Type)
+			ma.AssembleEntry("s").AssignBytes(c.Signature.Signature)
+		})
+	})
+}
+
+// Load loads the CACAO with the given CID through the given LinkSystem.
+func Load(ctx context.Context, ls ipld.LinkSystem, c cid.Cid) (*Cacao, error) {
+	if n, err := ls.Load(ipld.LinkContext{Ctx: ctx}, cidlink.Link{Cid: c}, basicnode.Prototype.Any); err != nil {
+		return nil, err
+	} else {
+		return FromNode(n)
+	}
+}
+
+// CapabilityCID returns the CID of the CACAO referenced by the `cap` protected header parameter of the given
+// signature. An error is returned if the signature has no `cap` or if it is not an `ipfs://` URI.
+func CapabilityCID(sig dagjose.DecodedSignature) (cid.Cid, error) {
+	if header, err := sig.ProtectedHeader(); err != nil {
+		return cid.Undef, err
+	} else {
+		return capabilityCID(header)
+	}
+}
+
+func capabilityCID(header *dagjose.ProtectedHeader) (cid.Cid, error) {
+	uri, found := header.Extra["cap"]
+	if !found {
+		return cid.Undef, errors.New("missing `cap` header parameter")
+	}
+	if uri, castOk := uri.(string); !castOk || !strings.HasPrefix(uri, capabilityURIPrefix) {
+		return cid.Undef, fmt.Errorf("invalid `cap` header parameter: %v", uri)
+	} else if c, err := cid.Decode(strings.TrimPrefix(uri, capabilityURIPrefix)); err != nil {
+		return cid.Undef, fmt.Errorf("invalid `cap` header parameter: %w", err)
+	} else {
+		return c, nil
+	}
+}
+
+func lookupOptional(n datamodel.Node, key string) (datamodel.Node, error) {
+	if value, err := n.LookupByString(key); err != nil {
+		if _, notFound := err.(datamodel.ErrNotExists); notFound {
+			return nil, nil
+		}
+		return nil, err
+	} else if value.IsNull() || value.IsAbsent() {
+		return nil, nil
+	} else {
+		return value, nil
+	}
+}
+
+func lookupMap(n datamodel.Node, key string) (datamodel.Node, error) {
+	if n.Kind() != datamodel.Kind_Map {
+		return nil, errors.New("invalid CACAO: not a map")
+	} else if value, err := lookupOptional(n, key); err != nil {
+		return nil, err
+	} else if value == nil || value.Kind() != datamodel.Kind_Map {
+		return nil, fmt.Errorf("invalid CACAO: missing or invalid `%s` field", key)
+	} else {
+		return value, nil
+	}
+}
+
+func lookupString(n datamodel.Node, key string, required bool) (string, error) {
+	if value, err := lookupOptional(n, key); err != nil {
+		return "", err
+	} else if value == nil {
+		if required {
+			return "", fmt.Errorf("invalid CACAO: missing `%s` field", key)
+		}
+		return "", nil
+	} else if str, err := value.AsString(); err != nil {
+		return "", fmt.Errorf("invalid CACAO: `%s` is not a string: %w", key, err)
+	} else {
+		return str, nil
+	}
+}
@@ -0,0 +1,316 @@
+package cacao
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/hex"
+	"testing"
+	"time"
+
+	"github.com/ceramicnetwork/go-dag-jose/dagjose"
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"github.com/decred/dcrd/dcrec/secp256k1/v4/ecdsa"
+	gojose "github.com/go-jose/go-jose/v4"
+	"github.com/ipfs/go-cid"
+	"github.com/ipld/go-ipld-prime"
+	"github.com/ipld/go-ipld-prime/datamodel"
+	cidlink "github.com/ipld/go-ipld-prime/linking/cid"
+	"github.com/ipld/go-ipld-prime/node/basicnode"
+	"github.com/ipld/go-ipld-prime/storage/memstore"
+	"github.com/mr-tron/base58"
+	"github.com/multiformats/go-multibase"
+	"github.com/multiformats/go-multihash"
+	"github.com/multiformats/go-varint"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	issuedAt   = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	expiration = issuedAt.Add(24 * time.Hour)
+)
+
+func memoryLinkSystem() ipld.LinkSystem {
+	ls := cidlink.DefaultLinkSystem()
+	store := &memstore.Store{}
+	ls.SetReadStorage(store)
+	ls.SetWriteStorage(store)
+	return ls
+}
+
+func storeNode(t *testing.T, ls ipld.LinkSystem, n datamodel.Node, codec uint64) cid.Cid {
+	lnk, err := ls.Store(ipld.LinkContext{}, cidlink.LinkPrototype{Prefix: cid.Prefix{
+		Version:  1,
+		Codec:    codec,
+		MhType:   multihash.SHA2_256,
+		MhLength: -1,
+	}}, n)
+	require.NoError(t, err)
+	return lnk.(cidlink.Link).Cid
+}
+
+func sessionKey(t *testing.T) (ed25519.PrivateKey, string) {
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	identifier, err := multibase.Encode(multibase.Base58BTC, append(varint.ToUvarint(0xed), publicKey...))
+	require.NoError(t, err)
+	return privateKey, "did:key:" + identifier
+}
+
+func unsignedCacao(headerType, issuer, audience string) *Cacao {
+	return &Cacao{
+		Header: Header{Type: headerType},
+		Payload: Payload{
+			Domain:         "app.example.com",
+			Issuer:         issuer,
+			Audience:       audience,
+			Version:        "1",
+			Nonce:          "32891757",
+			IssuedAt:       issuedAt.Format(time.RFC3339),
+			ExpirationTime: expiration.Format(time.RFC3339),
+			Statement:      "Give this application access to some of your data",
+			Resources:      []string{"ceramic://*"},
+		},
+	}
+}
+
+func signEthereum(t *testing.T, c *Cacao, key *secp256k1.PrivateKey) {
+	message, err := c.Message()
+	require.NoError(t, err)
+	compact := ecdsa.SignCompact(key, ethereumMessageHash(message), false)
+	// Move the recovery ID to the end, as in Ethereum signatures
+	c.Signature = Signature{Type: SignatureTypeEIP191, Signature: append(compact[1:], compact[0])}
+}
+
+// signedJWS stores the CACAO and returns a JWS signed by the session key with a `cap` header referencing it
+func signedJWS(t *testing.T, ls ipld.LinkSystem, c *Cacao, key ed25519.PrivateKey, did string) datamodel.Node {
+	capability, err := c.ToNode()
+	require.NoError(t, err)
+	capCID := storeNode(t, ls, capability, cid.DagCBOR)
+	payload := storeNode(t, ls, basicnode.NewString("commit"), cid.DagCBOR)
+	jws, err := dagjose.SignCID(payload, dagjose.Signer{
+		Algorithm: gojose.EdDSA,
+		Key:       key,
+		KeyID:     did + "#" + did[len("did:key:"):],
+		Protected: map[string]interface{}{"cap": "ipfs://" + capCID.String()},
+	})
+	require.NoError(t, err)
+	buf := bytes.Buffer{}
+	require.NoError(t, dagjose.Encode(jws, &buf))
+	nb := basicnode.Prototype.Any.NewBuilder()
+	require.NoError(t, dagjose.Decode(nb, &buf))
+	return nb.Build()
+}
+
+func TestMessage(t *testing.T) {
+	// Example from EIP-4361
+	c := &Cacao{
+		Header: Header{Type: HeaderTypeEIP4361},
+		Payload: Payload{
+			Domain:    "service.org",
+			Issuer:    "did:pkh:eip155:1:0xe5A12547fe4E872D192E3eCecb76F2Ce1aeA4946",
+			Audience:  "https://service.org/login",
+			Version:   "1",
+			Nonce:     "32891757",
+			IssuedAt:  "2021-09-30T16:25:24.000Z",
+			Statement: "I accept the ServiceOrg Terms of Service: https://service.org/tos",
+			Resources: []string{
+				"ipfs://Qme7ss3ARVgxv6rXqVPiikMJ8u2NLgmgszg13pYrDKEoiu",
+				"https://example.com/my-web2-claim.json",
+			},
+		},
+	}
+	message, err := c.Message()
+	require.NoError(t, err)
+	require.Equal(t, `service.org wants you to sign in with your Ethereum account:
+0xe5A12547fe4E872D192E3eCecb76F2Ce1aeA4946
+
+I accept the ServiceOrg Terms of Service: https://service.org/tos
+
+URI: https://service.org/login
+Version: 1
+Chain ID: 1
+Nonce: 32891757
+Issued At: 2021-09-30T16:25:24.000Z
+Resources:
+- ipfs://Qme7ss3ARVgxv6rXqVPiikMJ8u2NLgmgszg13pYrDKEoiu
+- https://example.com/my-web2-claim.json`, message)
+
+	c.Header.Type = HeaderTypeCAIP122
+	_, err = c.Message()
+	require.Error(t, err)
+}
+
+func TestEthereumAddress(t *testing.T) {
+	keyBytes, err := hex.DecodeString("4c0883a69102937d6231471b5dbb6204fe5129617082792ae468d01a3f362318")
+	require.NoError(t, err)
+	key := secp256k1.PrivKeyFromBytes(keyBytes)
+	require.Equal(t, "0x2c7536e3605d9c16a7a3d7b1898e529396a65c23", ethereumAddress(key.PubKey().SerializeUncompressed()))
+}
+
+func TestCacaoNodeRoundTrip(t *testing.T) {
+	c := unsignedCacao(HeaderTypeEIP4361, "did:pkh:eip155:1:0x2c7536E3605D9C16a7a3D7b1898e529396a65c23", "did:key:z6Mk")
+	c.Signature = Signature{Type: SignatureTypeEIP191, Signature: []byte{1, 2, 3}}
+	n, err := c.ToNode()
+	require.NoError(t, err)
+	parsed, err := FromNode(n)
+	require.NoError(t, err)
+	require.Equal(t, c, parsed)
+
+	_, err = FromNode(basicnode.NewString("not a CACAO"))
+	require.Error(t, err)
+}
+
+func TestVerifyJWSWithEthereumCacao(t *testing.T) {
+	ctx := context.Background()
+	ls := memoryLinkSystem()
+	accountKey, err := secp256k1.GeneratePrivateKey()
+	require.NoError(t, err)
+	issuer := "did:pkh:eip155:1:" + ethereumAddress(accountKey.PubKey().SerializeUncompressed())
+	key, did := sessionKey(t)
+	opts := VerifyOptions{AtTime: issuedAt.Add(time.Hour), Resources: []string{"ceramic://kjzl6cwe1jw14"}}
+
+	c := unsignedCacao(HeaderTypeEIP4361, issuer, did)
+	signEthereum(t, c, accountKey)
+	capabilities, err := VerifyJWS(ctx, ls, signedJWS(t, ls, c, key, did), opts)
+	require.NoError(t, err)
+	require.Len(t, capabilities, 1)
+	require.Equal(t, did, capabilities[0].Payload.Audience)
+
+	// Issued in the future, expired, not yet valid, or not granting the required resources
+	_, err = VerifyJWS(ctx, ls, signedJWS(t, ls, c, key, did), VerifyOptions{AtTime: issuedAt.Add(-time.Second)})
+	require.ErrorContains(t, err, "issued in the future")
+	_, err = VerifyJWS(ctx, ls, signedJWS(t, ls, c, key, did), VerifyOptions{AtTime: issuedAt.Add(-time.Second), ClockSkew: time.Minute})
+	require.NoError(t, err)
+	_, err = VerifyJWS(ctx, ls, signedJWS(t, ls, c, key, did), VerifyOptions{AtTime: expiration.Add(time.Second)})
+	require.ErrorContains(t, err, "expired")
+	_, err = VerifyJWS(ctx, ls, signedJWS(t, ls, c, key, did), VerifyOptions{AtTime: expiration.Add(time.Second), ClockSkew: time.Minute})
+	require.NoError(t, err)
+	notBefore := *c
+	notBefore.Payload.NotBefore = issuedAt.Add(2 * time.Hour).Format(time.RFC3339)
+	signEthereum(t, &notBefore, accountKey)
+	_, err = VerifyJWS(ctx, ls, signedJWS(t, ls, &notBefore, key, did), opts)
+	require.ErrorContains(t, err, "not valid before")
+	narrow := *c
+	narrow.Payload.Resources = []string{"ceramic://other"}
+	signEthereum(t, &narrow, accountKey)
+	_, err = VerifyJWS(ctx, ls, signedJWS(t, ls, &narrow, key, did), opts)
+	require.ErrorContains(t, err, "does not grant access")
+
+	// Signed by another account, or tampered with after signing
+	otherKey, err := secp256k1.GeneratePrivateKey()
+	require.NoError(t, err)
+	forged := *c
+	signEthereum(t, &forged, otherKey)
+	_, err = VerifyJWS(ctx, ls, signedJWS(t, ls, &forged, key, did), opts)
+	require.ErrorContains(t, err, "verification failed")
+	tampered := *c
+	tampered.Payload.Statement = "Give this application access to all of your data"
+	_, err = VerifyJWS(ctx, ls, signedJWS(t, ls, &tampered, key, did), opts)
+	require.ErrorContains(t, err, "verification failed")
+
+	// Signed by a session key that is not the audience
+	otherSessionKey, otherDID := sessionKey(t)
+	_, err = VerifyJWS(ctx, ls, signedJWS(t, ls, c, otherSessionKey, otherDID), opts)
+	require.ErrorContains(t, err, "not the CACAO audience")
+}
+
+func TestVerifyJWSWithSolanaCacao(t *testing.T) {
+	ctx := context.Background()
+	ls := memoryLinkSystem()
+	accountPublicKey, accountKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	issuer := "did:pkh:solana:4sGjMW1sUnHzSxGspuhpqLDx6wiyjNtZ:" + base58.Encode(accountPublicKey)
+	key, did := sessionKey(t)
+
+	c := unsignedCacao(HeaderTypeCAIP122, issuer, did)
+	message, err := c.Message()
+	require.NoError(t, err)
+	require.Contains(t, message, "sign in with your Solana account")
+	c.Signature = Signature{Type: SignatureTypeSolanaEd25519, Signature: ed25519.Sign(accountKey, []byte(message))}
+	_, err = VerifyJWS(ctx, ls, signedJWS(t, ls, c, key, did), VerifyOptions{AtTime: issuedAt})
+	require.NoError(t, err)
+
+	c.Signature.Type = SignatureTypeEIP191
+	_, err = VerifyJWS(ctx, ls, signedJWS(t, ls, c, key, did), VerifyOptions{AtTime: issuedAt})
+	require.Error(t, err)
+}
+
+func TestVerifyJWSRequiresCapability(t *testing.T) {
+	key, did := sessionKey(t)
+	jws, err := dagjose.SignCID(cid.NewCidV1(cid.Raw, []byte{0, 0}), dagjose.Signer{Algorithm: gojose.EdDSA, Key: key, KeyID: did})
+	require.NoError(t, err)
+	_, err = VerifyJWS(context.Background(), memoryLinkSystem(), jws, VerifyOptions{})
+	require.ErrorContains(t, err, "missing `cap`")
+}
+
+// ceramicCacao returns a CACAO shaped like those created by Ceramic's did-session, with the given statement
+func ceramicCacao(statement string) *Cacao {
+	return &Cacao{
+		Header: Header{Type: HeaderTypeEIP4361},
+		Payload: Payload{
+			Domain:         "app.ceramic.network",
+			Issuer:         "did:pkh:eip155:1:0x2c7536E3605D9C16a7a3D7b1898e529396a65c23",
+			Audience:       "did:key:z6MkrBdNdwUPnXDVD1DCxedzVVBpaGi8aSmoXFAeKNgtAer8",
+			Version:        "1",
+			Nonce:          "Tz2ZW7ilZS",
+			IssuedAt:       "2024-01-01T00:00:00.000Z",
+			ExpirationTime: "2024-01-08T00:00:00.000Z",
+			Statement:      statement,
+			Resources:      []string{"ceramic://*"},
+		},
+	}
+}
+
+// Fixed CACAOs laid out as by @didtools/cacao, with and without a statement. The signatures are by the account of the
+// private key in TestEthereumAddress.
+func TestVerifyCeramicCacaoFixtures(t *testing.T) {
+	fixtures := []struct {
+		statement string
+		message   string
+		signature string
+	}{
+		{
+			statement: "Give this application access to some of your data on Ceramic",
+			message: `app.ceramic.network wants you to sign in with your Ethereum account:
+0x2c7536E3605D9C16a7a3D7b1898e529396a65c23
+
+Give this application access to some of your data on Ceramic
+
+URI: did:key:z6MkrBdNdwUPnXDVD1DCxedzVVBpaGi8aSmoXFAeKNgtAer8
+Version: 1
+Chain ID: 1
+Nonce: Tz2ZW7ilZS
+Issued At: 2024-01-01T00:00:00.000Z
+Expiration Time: 2024-01-08T00:00:00.000Z
+Resources:
+- ceramic://*`,
+			signature: "fbb0a821e9151caf92e34ff905d64b3cad220d052edb0db0d68e782de11fdbcc194189abebbb7d4e7dde3ffa7980eb2f1c7518e7c64cc6c0ac0947d0b70554b31b",
+		},
+		{
+			message: `app.ceramic.network wants you to sign in with your Ethereum account:
+0x2c7536E3605D9C16a7a3D7b1898e529396a65c23
+
+URI: did:key:z6MkrBdNdwUPnXDVD1DCxedzVVBpaGi8aSmoXFAeKNgtAer8
+Version: 1
+Chain ID: 1
+Nonce: Tz2ZW7ilZS
+Issued At: 2024-01-01T00:00:00.000Z
+Expiration Time: 2024-01-08T00:00:00.000Z
+Resources:
+- ceramic://*`,
+			signature: "abaaaa68e51dfaba0c08b223adf6e0761a33d5cf392c27accd5984425ced9ec81d34112866d996d2479cac4e86183c4e97685616ca7cca0b5c2f6597268046741c",
+		},
+	}
+	for _, fixture := range fixtures {
+		c := ceramicCacao(fixture.statement)
+		message, err := c.Message()
+		require.NoError(t, err)
+		require.Equal(t, fixture.message, message)
+		signature, err := hex.DecodeString(fixture.signature)
+		require.NoError(t, err)
+		c.Signature = Signature{Type: SignatureTypeEIP191, Signature: signature}
+		require.NoError(t, c.Verify(VerifyOptions{AtTime: issuedAt.Add(time.Hour), Resources: []string{"ceramic://kjzl6cwe1jw14"}}))
+	}
+}
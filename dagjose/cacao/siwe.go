@@ -0,0 +1,155 @@
+package cacao
+
+import (
+	"crypto/ed25519"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4/ecdsa"
+	"github.com/mr-tron/base58"
+	"golang.org/x/crypto/sha3"
+)
+
+const (
+	didPKHPrefix = "did:pkh:"
+	// Ethereum signatures are R || S || V, where V is the recovery ID, possibly offset by 27.
+	ethereumSignatureSize = 65
+	// decred's compact signatures are prefixed with 27 + the recovery ID, plus 4 for compressed public keys.
+	compactSignatureRecoveryOffset = 27
+)
+
+// account is a blockchain account parsed from a did:pkh.
+// See: https://github.com/w3c-ccg/did-pkh/blob/main/did-pkh-method-draft.md
+type account struct {
+	namespace string
+	reference string
+	address   string
+}
+
+func parseDIDPKH(did string) (*account, error) {
+	if !strings.HasPrefix(did, didPKHPrefix) {
+		return nil, fmt.Errorf("not a did:pkh: %s", did)
+	}
+	parts := strings.Split(strings.TrimPrefix(did, didPKHPrefix), ":")
+	if len(parts) != 3 || parts[0] == "" || parts[1] == "" || parts[2] == "" {
+		return nil, fmt.Errorf("invalid did:pkh: %s", did)
+	}
+	return &account{namespace: parts[0], reference: parts[1], address: parts[2]}, nil
+}
+
+// Message returns the message the issuer signed, reconstructed from the payload. CACAOs of type HeaderTypeEIP4361 use
+// the Sign-In with Ethereum format, and CACAOs of type HeaderTypeCAIP122 the Sign-In with Solana format, laid out as by
+// the @didtools/cacao formatter that Ceramic clients sign with.
+// See: https://eips.ethereum.org/EIPS/eip-4361#message-format
+func (c *Cacao) Message() (string, error) {
+	issuer, err := parseDIDPKH(c.Payload.Issuer)
+	if err != nil {
+		return "", err
+	}
+	var chain string
+	switch {
+	case c.Header.Type == HeaderTypeEIP4361 && issuer.namespace == "eip155":
+		chain = "Ethereum"
+	case c.Header.Type == HeaderTypeCAIP122 && issuer.namespace == "solana":
+		chain = "Solana"
+	default:
+		return "", fmt.Errorf("unsupported CACAO type %s for %s accounts", c.Header.Type, issuer.namespace)
+	}
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%s wants you to sign in with your %s account:\n%s\n\n", c.Payload.Domain, chain, issuer.address)
+	// As in the formatter used by Ceramic (@didtools/cacao), an absent statement drops the statement line along with
+	// the blank line that follows it, rather than leaving an empty statement as in the EIP-4361 grammar.
+	if c.Payload.Statement != "" {
+		sb.WriteString(c.Payload.Statement + "\n\n")
+	}
+	fmt.Fprintf(&sb, "URI: %s\nVersion: %s\nChain ID: %s\nNonce: %s\nIssued At: %s",
+		c.Payload.Audience, c.Payload.Version, issuer.reference, c.Payload.Nonce, c.Payload.IssuedAt)
+	if c.Payload.ExpirationTime != "" {
+		sb.WriteString("\nExpiration Time: " + c.Payload.ExpirationTime)
+	}
+	if c.Payload.NotBefore != "" {
+		sb.WriteString("\nNot Before: " + c.Payload.NotBefore)
+	}
+	if c.Payload.RequestID != "" {
+		sb.WriteString("\nRequest ID: " + c.Payload.RequestID)
+	}
+	if len(c.Payload.Resources) > 0 {
+		sb.WriteString("\nResources:")
+		for _, resource := range c.Payload.Resources {
+			sb.WriteString("\n- " + resource)
+		}
+	}
+	return sb.String(), nil
+}
+
+// verifySignature verifies that the issuer of the CACAO signed its message.
+func (c *Cacao) verifySignature() error {
+	message, err := c.Message()
+	if err != nil {
+		return err
+	}
+	issuer, _ := parseDIDPKH(c.Payload.Issuer)
+	switch c.Signature.Type {
+	case SignatureTypeEIP191:
+		if issuer.namespace != "eip155" {
+			return fmt.Errorf("invalid signature type %s for %s accounts", c.Signature.Type, issuer.namespace)
+		} else if address, err := recoverEthereumAddress(message, c.Signature.Signature); err != nil {
+			return err
+		} else if !strings.EqualFold(address, issuer.address) {
+			return errors.New("CACAO signature verification failed")
+		}
+		return nil
+	case SignatureTypeSolanaEd25519:
+		if issuer.namespace != "solana" {
+			return fmt.Errorf("invalid signature type %s for %s accounts", c.Signature.Type, issuer.namespace)
+		} else if publicKey, err := base58.Decode(issuer.address); err != nil || len(publicKey) != ed25519.PublicKeySize {
+			return fmt.Errorf("invalid Solana address: %s", issuer.address)
+		} else if !ed25519.Verify(publicKey, []byte(message), c.Signature.Signature) {
+			return errors.New("CACAO signature verification failed")
+		}
+		return nil
+	default:
+		return fmt.Errorf("unsupported CACAO signature type: %s", c.Signature.Type)
+	}
+}
+
+// recoverEthereumAddress returns the hex-encoded address of the account that produced the given `personal_sign`
+// signature of the message.
+// See: https://eips.ethereum.org/EIPS/eip-191
+func recoverEthereumAddress(message string, signature []byte) (string, error) {
+	if len(signature) != ethereumSignatureSize {
+		return "", fmt.Errorf("invalid signature length: %d", len(signature))
+	}
+	recoveryID := signature[ethereumSignatureSize-1]
+	if recoveryID >= compactSignatureRecoveryOffset {
+		recoveryID -= compactSignatureRecoveryOffset
+	}
+	if recoveryID > 1 {
+		return "", fmt.Errorf("invalid signature recovery ID: %d", signature[ethereumSignatureSize-1])
+	}
+	compact := make([]byte, 0, ethereumSignatureSize)
+	compact = append(compact, compactSignatureRecoveryOffset+recoveryID)
+	compact = append(compact, signature[:ethereumSignatureSize-1]...)
+	publicKey, _, err := ecdsa.RecoverCompact(compact, ethereumMessageHash(message))
+	if err != nil {
+		return "", fmt.Errorf("invalid signature: %w", err)
+	}
+	return ethereumAddress(publicKey.SerializeUncompressed()), nil
+}
+
+// ethereumMessageHash returns the hash signed by `personal_sign` for the given message.
+func ethereumMessageHash(message string) []byte {
+	hasher := sha3.NewLegacyKeccak256()
+	hasher.Write([]byte("\x19Ethereum Signed Message:\n" + strconv.Itoa(len(message)) + message))
+	return hasher.Sum(nil)
+}
+
+// ethereumAddress returns the hex-encoded address for the given uncompressed secp256k1 public key, i.e. the last 20
+// bytes of the Keccak-256 hash of its coordinates.
+func ethereumAddress(uncompressedPublicKey []byte) string {
+	hasher := sha3.NewLegacyKeccak256()
+	hasher.Write(uncompressedPublicKey[1:])
+	return fmt.Sprintf("0x%x", hasher.Sum(nil)[12:])
+}
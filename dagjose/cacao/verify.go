@@ -0,0 +1,120 @@
+package cacao
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ceramicnetwork/go-dag-jose/dagjose"
+	"github.com/ipld/go-ipld-prime"
+	"github.com/ipld/go-ipld-prime/datamodel"
+)
+
+// VerifyOptions configures the verification of a CACAO.
+type VerifyOptions struct {
+	// AtTime is the time at which the CACAO must be valid. If zero, the current time is used.
+	AtTime time.Time
+	// ClockSkew is the tolerance applied when checking the `iat`, `nbf` and `exp` fields.
+	ClockSkew time.Duration
+	// Resources lists URIs that the CACAO must grant access to. A CACAO resource ending in `*` grants access to all URIs
+	// starting with the part before the `*`.
+	Resources []string
+}
+
+// Verify verifies the signature of the CACAO, checks that it is valid at the configured time, i.e. that it was issued
+// before that time and is within its validity period, and that its resources cover the required resources.
+func (c *Cacao) Verify(opts VerifyOptions) error {
+	if err := c.verifySignature(); err != nil {
+		return err
+	}
+	at := opts.AtTime
+	if at.IsZero() {
+		at = time.Now()
+	}
+	if iat, err := time.Parse(time.RFC3339, c.Payload.IssuedAt); err != nil {
+		return fmt.Errorf("invalid CACAO `iat`: %w", err)
+	} else if at.Add(opts.ClockSkew).Before(iat) {
+		return fmt.Errorf("CACAO is issued in the future at %s", c.Payload.IssuedAt)
+	}
+	if c.Payload.NotBefore != "" {
+		if nbf, err := time.Parse(time.RFC3339, c.Payload.NotBefore); err != nil {
+			return fmt.Errorf("invalid CACAO `nbf`: %w", err)
+		} else if at.Add(opts.ClockSkew).Before(nbf) {
+			return fmt.Errorf("CACAO is not valid before %s", c.Payload.NotBefore)
+		}
+	}
+	if c.Payload.ExpirationTime != "" {
+		if exp, err := time.Parse(time.RFC3339, c.Payload.ExpirationTime); err != nil {
+			return fmt.Errorf("invalid CACAO `exp`: %w", err)
+		} else if !at.Add(-opts.ClockSkew).Before(exp) {
+			return fmt.Errorf("CACAO expired at %s", c.Payload.ExpirationTime)
+		}
+	}
+	for _, required := range opts.Resources {
+		if !c.grants(required) {
+			return fmt.Errorf("CACAO does not grant access to %s", required)
+		}
+	}
+	return nil
+}
+
+func (c *Cacao) grants(resource string) bool {
+	for _, granted := range c.Payload.Resources {
+		if granted == resource {
+			return true
+		} else if prefix, isWildcard := strings.CutSuffix(granted, "*"); isWildcard && strings.HasPrefix(resource, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// VerifyJWS verifies a JWS signed with delegated authority. For every signature, the CACAO referenced by the `cap`
+// protected header parameter is loaded through the given LinkSystem and verified with the given options, the `kid` of
+// the signature must be a did:key for the `aud` of the CACAO, and the signature must be valid for that did:key.
+//
+// The loaded CACAOs are returned in the same order as the signatures. An error is returned if any signature fails
+// these checks.
+func VerifyJWS(ctx context.Context, ls ipld.LinkSystem, n datamodel.Node, opts VerifyOptions) ([]*Cacao, error) {
+	var jws dagjose.JWS
+	if err := jws.FromNode(n); err != nil {
+		return nil, err
+	} else if len(jws.Signatures) == 0 {
+		return nil, errors.New("JWS has no signatures")
+	}
+	results, err := dagjose.VerifyJWSWithResolver(ctx, n, dagjose.DIDKeyResolver{})
+	if err != nil {
+		return nil, err
+	}
+	capabilities := make([]*Cacao, 0, len(jws.Signatures))
+	for idx, sig := range jws.Signatures {
+		header, err := sig.ProtectedHeader()
+		if err != nil {
+			return nil, fmt.Errorf("signature %d: %w", idx, err)
+		}
+		capCID, err := capabilityCID(header)
+		if err != nil {
+			return nil, fmt.Errorf("signature %d: %w", idx, err)
+		}
+		capability, err := Load(ctx, ls, capCID)
+		if err != nil {
+			return nil, fmt.Errorf("signature %d: loading CACAO: %w", idx, err)
+		}
+		if err := capability.Verify(opts); err != nil {
+			return nil, fmt.Errorf("signature %d: %w", idx, err)
+		} else if signer, _, _ := strings.Cut(header.Kid, "#"); signer == "" || signer != withoutFragment(capability.Payload.Audience) {
+			return nil, fmt.Errorf("signature %d: signer %s is not the CACAO audience %s", idx, signer, capability.Payload.Audience)
+		} else if !results[idx].Valid() {
+			return nil, fmt.Errorf("signature %d: %w", idx, results[idx].Err)
+		}
+		capabilities = append(capabilities, capability)
+	}
+	return capabilities, nil
+}
+
+func withoutFragment(did string) string {
+	did, _, _ = strings.Cut(did, "#")
+	return did
+}
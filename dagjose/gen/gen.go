@@ -1,8 +1,15 @@
 package main
 
 import (
+	"bytes"
 	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
 	"os"
+	"path/filepath"
+	"sort"
+	"strings"
 
 	"github.com/ipld/go-ipld-prime/datamodel"
 	"github.com/ipld/go-ipld-prime/schema"
@@ -15,32 +22,39 @@ func main() {
 
 	// -- Common types -->
 
+	ts.Accumulate(schema.SpawnBool("Bool"))
 	ts.Accumulate(schema.SpawnString("String"))
 	ts.Accumulate(schema.SpawnBytes("Bytes"))
 	ts.Accumulate(schema.SpawnInt("Int"))
 	ts.Accumulate(schema.SpawnFloat("Float"))
 	ts.Accumulate(schema.SpawnLink("Link"))
-	ts.Accumulate(schema.SpawnMap("Map", "String", "Any", false))
-	ts.Accumulate(schema.SpawnList("List", "Any", false))
+	// Map values and list elements are nullable because there is no null type that could be a member of `Any`. See
+	// patchGenerated for the changes this requires in the generated code.
+	ts.Accumulate(schema.SpawnMap("Map", "String", "Any", true))
+	ts.Accumulate(schema.SpawnList("List", "Any", true))
 
 	// The `Any` union represents a wildcard nested type that can contain any type of scalar or recursive information
 	// including itself (as map values or list elements).
 	ts.Accumulate(schema.SpawnUnion("Any",
 		[]schema.TypeName{
+			"Bool",
 			"String",
 			"Bytes",
 			"Int",
 			"Float",
 			"Map",
 			"List",
+			"Link",
 		},
 		schema.SpawnUnionRepresentationKinded(map[datamodel.Kind]schema.TypeName{
+			datamodel.Kind_Bool:   "Bool",
 			datamodel.Kind_String: "String",
 			datamodel.Kind_Bytes:  "Bytes",
 			datamodel.Kind_Int:    "Int",
 			datamodel.Kind_Float:  "Float",
 			datamodel.Kind_Map:    "Map",
 			datamodel.Kind_List:   "List",
+			datamodel.Kind_Link:   "Link",
 		}),
 	))
 
@@ -137,4 +151,137 @@ func main() {
 		// This is important for the `Any` union to work correctly
 		CfgUnionMemlayout: map[schema.TypeName]string{"Any": "interface"},
 	})
+	patchGenerated(os.Args[1])
+}
+
+// patchGenerated applies the changes to the generated code that can't be expressed in the schema.
+//
+// `Any` map values and list elements must accept nulls, since headers may contain them, but neither the schema DSL nor
+// gengo (as of go-ipld-prime v0.21) has a null type that could be a member of the `Any` union. Map and List are thus
+// spawned with nullable values instead. However, the representation assembler gengo generates for kinded unions ignores
+// whether the union is nullable in its context, and rejects nulls even where the parent assembler has set the
+// `allowNull` state. The patch adds the missing case, mirroring what gengo generates for the assemblers of other
+// nullable types.
+//
+// The patch matches the generated source verbatim, so any change in gengo's output makes generation fail here rather
+// than silently producing code in which `Any` rejects nulls. If that happens, update the matched text after checking
+// that the generated assembler still needs the patch. TestRoundTripHeaderKinds covers the patched behavior.
+func patchGenerated(dir string) {
+	minimaFile := filepath.Join(dir, "ipldsch_minima.go")
+	minima, err := os.ReadFile(minimaFile)
+	if err != nil {
+		panic(err)
+	}
+	// The patch relies on the assembler state that gengo uses to mark nullable positions
+	if err := expectOnce(minima, "allowNull = schema.Maybe("); err != nil {
+		panic(fmt.Errorf("unable to patch generated code for dagjose.Any: %s: %w", minimaFile, err))
+	}
+	file := filepath.Join(dir, "ipldsch_satisfaction.go")
+	src, err := os.ReadFile(file)
+	if err != nil {
+		panic(err)
+	}
+	const generated = `func (na *_Any__ReprAssembler) AssignNull() error {
+	switch *na.m {
+	case schema.Maybe_Value, schema.Maybe_Null:`
+	const patched = `func (na *_Any__ReprAssembler) AssignNull() error {
+	switch *na.m {
+	case allowNull:
+		*na.m = schema.Maybe_Null
+		return nil
+	case schema.Maybe_Value, schema.Maybe_Null:`
+	if err := expectOnce(src, generated); err != nil {
+		panic(fmt.Errorf("unable to patch generated code for dagjose.Any: %s: %w", file, err))
+	}
+	src = bytes.Replace(src, []byte(generated), []byte(patched), 1)
+	if src, err = returnErrorsInsteadOfPanics(src); err != nil {
+		panic(err)
+	}
+	if err := os.WriteFile(file, src, 0644); err != nil {
+		panic(err)
+	}
+}
+
+// expectOnce returns an error unless the given text appears exactly once in the source.
+func expectOnce(src []byte, text string) error {
+	if count := bytes.Count(src, []byte(text)); count != 1 {
+		return fmt.Errorf("expected exactly one occurrence of %q, found %d", text, count)
+	}
+	return nil
+}
+
+// returnErrorsInsteadOfPanics rewrites the generated assemblers so that methods that can return an error do so instead
+// of panicking when they are used out of order, e.g. when assigning into an assembler that's already finished. This
+// guarantees that a codec driving the assemblers with unexpected data can never crash the process.
+func returnErrorsInsteadOfPanics(src []byte) ([]byte, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "", src, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+	type replacement struct {
+		start, end int
+		text       string
+	}
+	var replacements []replacement
+	for _, decl := range file.Decls {
+		fn, castOk := decl.(*ast.FuncDecl)
+		if !castOk || fn.Recv == nil || fn.Body == nil || fn.Type.Results == nil {
+			continue
+		}
+		recv := fn.Recv.List[0].Type
+		if star, castOk := recv.(*ast.StarExpr); castOk {
+			recv = star.X
+		}
+		if ident, castOk := recv.(*ast.Ident); !castOk || !strings.HasSuffix(ident.Name, "Assembler") {
+			continue
+		}
+		results := fn.Type.Results.List
+		if ident, castOk := results[len(results)-1].Type.(*ast.Ident); !castOk || ident.Name != "error" {
+			continue
+		}
+		// All the other results of the assembler methods are interfaces or pointers, whose zero value is nil
+		var values []string
+		var unsupported error
+		for _, result := range results[:len(results)-1] {
+			switch result.Type.(type) {
+			case *ast.SelectorExpr, *ast.StarExpr, *ast.InterfaceType:
+			default:
+				unsupported = fmt.Errorf("%s: unsupported result type", fset.Position(result.Pos()))
+			}
+			for n := 0; n < max(1, len(result.Names)); n++ {
+				values = append(values, "nil")
+			}
+		}
+		found := len(replacements)
+		ast.Inspect(fn.Body, func(n ast.Node) bool {
+			if _, castOk := n.(*ast.FuncLit); castOk {
+				return false
+			}
+			stmt, castOk := n.(*ast.ExprStmt)
+			if !castOk {
+				return true
+			}
+			if call, castOk := stmt.X.(*ast.CallExpr); castOk && len(call.Args) == 1 {
+				if ident, castOk := call.Fun.(*ast.Ident); castOk && ident.Name == "panic" {
+					if msg, castOk := call.Args[0].(*ast.BasicLit); castOk && msg.Kind == token.STRING {
+						replacements = append(replacements, replacement{
+							start: fset.Position(stmt.Pos()).Offset,
+							end:   fset.Position(stmt.End()).Offset,
+							text:  "return " + strings.Join(append(values, "assemblerStateError("+msg.Value+")"), ", "),
+						})
+					}
+				}
+			}
+			return true
+		})
+		if unsupported != nil && len(replacements) > found {
+			return nil, unsupported
+		}
+	}
+	sort.Slice(replacements, func(i, j int) bool { return replacements[i].start > replacements[j].start })
+	for _, r := range replacements {
+		src = append(src[:r.start:r.start], append([]byte(r.text), src[r.end:]...)...)
+	}
+	return src, nil
 }
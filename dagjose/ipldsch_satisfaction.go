@@ -47,12 +47,14 @@ func (m MaybeAny) Must() Any {
 }
 
 var (
+	memberName__Any_Bool   = _String{"Bool"}
 	memberName__Any_String = _String{"String"}
 	memberName__Any_Bytes  = _String{"Bytes"}
 	memberName__Any_Int    = _String{"Int"}
 	memberName__Any_Float  = _String{"Float"}
 	memberName__Any_Map    = _String{"Map"}
 	memberName__Any_List   = _String{"List"}
+	memberName__Any_Link   = _String{"Link"}
 )
 var _ datamodel.Node = (Any)(&_Any{})
 var _ schema.TypedNode = (Any)(&_Any{})
@@ -62,6 +64,12 @@ func (Any) Kind() datamodel.Kind {
 }
 func (n Any) LookupByString(key string) (datamodel.Node, error) {
 	switch key {
+	case "Bool":
+		if n2, ok := n.x.(Bool); ok {
+			return n2, nil
+		} else {
+			return nil, datamodel.ErrNotExists{Segment: datamodel.PathSegmentOfString(key)}
+		}
 	case "String":
 		if n2, ok := n.x.(String); ok {
 			return n2, nil
@@ -98,6 +106,12 @@ func (n Any) LookupByString(key string) (datamodel.Node, error) {
 		} else {
 			return nil, datamodel.ErrNotExists{Segment: datamodel.PathSegmentOfString(key)}
 		}
+	case "Link":
+		if n2, ok := n.x.(Link); ok {
+			return n2, nil
+		} else {
+			return nil, datamodel.ErrNotExists{Segment: datamodel.PathSegmentOfString(key)}
+		}
 	default:
 		return nil, schema.ErrNoSuchField{Type: nil /*TODO*/, Field: datamodel.PathSegmentOfString(key)}
 	}
@@ -129,6 +143,8 @@ func (itr *_Any__MapItr) Next() (k datamodel.Node, v datamodel.Node, _ error) {
 		return nil, nil, datamodel.ErrIteratorOverread{}
 	}
 	switch n2 := itr.n.x.(type) {
+	case Bool:
+		k, v = &memberName__Any_Bool, n2
 	case String:
 		k, v = &memberName__Any_String, n2
 	case Bytes:
@@ -141,6 +157,8 @@ func (itr *_Any__MapItr) Next() (k datamodel.Node, v datamodel.Node, _ error) {
 		k, v = &memberName__Any_Map, n2
 	case List:
 		k, v = &memberName__Any_List, n2
+	case Link:
+		k, v = &memberName__Any_Link, n2
 	default:
 		panic("unreachable")
 	}
@@ -215,17 +233,21 @@ type _Any__Assembler struct {
 	state maState
 
 	cm  schema.Maybe
-	ca1 *_String__Assembler
+	ca1 *_Bool__Assembler
+
+	ca2 *_String__Assembler
+
+	ca3 *_Bytes__Assembler
 
-	ca2 *_Bytes__Assembler
+	ca4 *_Int__Assembler
 
-	ca3 *_Int__Assembler
+	ca5 *_Float__Assembler
 
-	ca4 *_Float__Assembler
+	ca6 *_Map__Assembler
 
-	ca5 *_Map__Assembler
+	ca7 *_List__Assembler
 
-	ca6 *_List__Assembler
+	ca8 *_Link__Assembler
 	ca  uint
 }
 
@@ -251,6 +273,12 @@ func (na *_Any__Assembler) reset() {
 
 	case 6:
 		na.ca6.reset()
+
+	case 7:
+		na.ca7.reset()
+
+	case 8:
+		na.ca8.reset()
 	default:
 		panic("unreachable")
 	}
@@ -260,9 +288,9 @@ func (na *_Any__Assembler) reset() {
 func (na *_Any__Assembler) BeginMap(int64) (datamodel.MapAssembler, error) {
 	switch *na.m {
 	case schema.Maybe_Value, schema.Maybe_Null:
-		panic("invalid state: cannot assign into assembler that's already finished")
+		return nil, assemblerStateError("invalid state: cannot assign into assembler that's already finished")
 	case midvalue:
-		panic("invalid state: it makes no sense to 'begin' twice on the same assembler!")
+		return nil, assemblerStateError("invalid state: it makes no sense to 'begin' twice on the same assembler!")
 	}
 	*na.m = midvalue
 	if na.w == nil {
@@ -281,11 +309,11 @@ func (na *_Any__Assembler) AssignNull() error {
 	case schema.Maybe_Absent:
 		return mixins.MapAssembler{TypeName: "dagjose.Any"}.AssignNull()
 	case schema.Maybe_Value, schema.Maybe_Null:
-		panic("invalid state: cannot assign into assembler that's already finished")
+		return assemblerStateError("invalid state: cannot assign into assembler that's already finished")
 	case midvalue:
-		panic("invalid state: cannot assign null into an assembler that's already begun working on recursive structures!")
+		return assemblerStateError("invalid state: cannot assign null into an assembler that's already begun working on recursive structures!")
 	}
-	panic("unreachable")
+	return assemblerStateError("unreachable")
 }
 func (_Any__Assembler) AssignBool(bool) error {
 	return mixins.MapAssembler{TypeName: "dagjose.Any"}.AssignBool(false)
@@ -312,9 +340,9 @@ func (na *_Any__Assembler) AssignNode(v datamodel.Node) error {
 	if v2, ok := v.(*_Any); ok {
 		switch *na.m {
 		case schema.Maybe_Value, schema.Maybe_Null:
-			panic("invalid state: cannot assign into assembler that's already finished")
+			return assemblerStateError("invalid state: cannot assign into assembler that's already finished")
 		case midvalue:
-			panic("invalid state: cannot assign null into an assembler that's already begun working on recursive structures!")
+			return assemblerStateError("invalid state: cannot assign null into an assembler that's already begun working on recursive structures!")
 		}
 		if na.w == nil {
 			na.w = v2
@@ -360,86 +388,108 @@ func (ma *_Any__Assembler) AssembleEntry(k string) (datamodel.NodeAssembler, err
 	case maState_initial:
 		// carry on
 	case maState_midKey:
-		panic("invalid state: AssembleEntry cannot be called when in the middle of assembling another key")
+		return nil, assemblerStateError("invalid state: AssembleEntry cannot be called when in the middle of assembling another key")
 	case maState_expectValue:
-		panic("invalid state: AssembleEntry cannot be called when expecting start of value assembly")
+		return nil, assemblerStateError("invalid state: AssembleEntry cannot be called when expecting start of value assembly")
 	case maState_midValue:
 		if !ma.valueFinishTidy() {
-			panic("invalid state: AssembleEntry cannot be called when in the middle of assembling a value")
+			return nil, assemblerStateError("invalid state: AssembleEntry cannot be called when in the middle of assembling a value")
 		} // if tidy success: carry on for the moment, but we'll still be erroring shortly.
 	case maState_finished:
-		panic("invalid state: AssembleEntry cannot be called on an assembler that's already finished")
+		return nil, assemblerStateError("invalid state: AssembleEntry cannot be called on an assembler that's already finished")
 	}
 	if ma.ca != 0 {
 		return nil, schema.ErrNotUnionStructure{TypeName: "dagjose.Any", Detail: "cannot add another entry -- a union can only contain one thing!"}
 	}
 	switch k {
-	case "String":
+	case "Bool":
 		ma.state = maState_midValue
 		ma.ca = 1
-		x := &_String{}
+		x := &_Bool{}
 		ma.w.x = x
 		if ma.ca1 == nil {
-			ma.ca1 = &_String__Assembler{}
+			ma.ca1 = &_Bool__Assembler{}
 		}
 		ma.ca1.w = x
 		ma.ca1.m = &ma.cm
 		return ma.ca1, nil
-	case "Bytes":
+	case "String":
 		ma.state = maState_midValue
 		ma.ca = 2
-		x := &_Bytes{}
+		x := &_String{}
 		ma.w.x = x
 		if ma.ca2 == nil {
-			ma.ca2 = &_Bytes__Assembler{}
+			ma.ca2 = &_String__Assembler{}
 		}
 		ma.ca2.w = x
 		ma.ca2.m = &ma.cm
 		return ma.ca2, nil
-	case "Int":
+	case "Bytes":
 		ma.state = maState_midValue
 		ma.ca = 3
-		x := &_Int{}
+		x := &_Bytes{}
 		ma.w.x = x
 		if ma.ca3 == nil {
-			ma.ca3 = &_Int__Assembler{}
+			ma.ca3 = &_Bytes__Assembler{}
 		}
 		ma.ca3.w = x
 		ma.ca3.m = &ma.cm
 		return ma.ca3, nil
-	case "Float":
+	case "Int":
 		ma.state = maState_midValue
 		ma.ca = 4
-		x := &_Float{}
+		x := &_Int{}
 		ma.w.x = x
 		if ma.ca4 == nil {
-			ma.ca4 = &_Float__Assembler{}
+			ma.ca4 = &_Int__Assembler{}
 		}
 		ma.ca4.w = x
 		ma.ca4.m = &ma.cm
 		return ma.ca4, nil
-	case "Map":
+	case "Float":
 		ma.state = maState_midValue
 		ma.ca = 5
-		x := &_Map{}
+		x := &_Float{}
 		ma.w.x = x
 		if ma.ca5 == nil {
-			ma.ca5 = &_Map__Assembler{}
+			ma.ca5 = &_Float__Assembler{}
 		}
 		ma.ca5.w = x
 		ma.ca5.m = &ma.cm
 		return ma.ca5, nil
-	case "List":
+	case "Map":
 		ma.state = maState_midValue
 		ma.ca = 6
-		x := &_List{}
+		x := &_Map{}
 		ma.w.x = x
 		if ma.ca6 == nil {
-			ma.ca6 = &_List__Assembler{}
+			ma.ca6 = &_Map__Assembler{}
 		}
 		ma.ca6.w = x
 		ma.ca6.m = &ma.cm
 		return ma.ca6, nil
+	case "List":
+		ma.state = maState_midValue
+		ma.ca = 7
+		x := &_List{}
+		ma.w.x = x
+		if ma.ca7 == nil {
+			ma.ca7 = &_List__Assembler{}
+		}
+		ma.ca7.w = x
+		ma.ca7.m = &ma.cm
+		return ma.ca7, nil
+	case "Link":
+		ma.state = maState_midValue
+		ma.ca = 8
+		x := &_Link{}
+		ma.w.x = x
+		if ma.ca8 == nil {
+			ma.ca8 = &_Link__Assembler{}
+		}
+		ma.ca8.w = x
+		ma.ca8.m = &ma.cm
+		return ma.ca8, nil
 	}
 	return nil, schema.ErrInvalidKey{TypeName: "dagjose.Any", Key: &_String{k}}
 }
@@ -477,59 +527,77 @@ func (ma *_Any__Assembler) AssembleValue() datamodel.NodeAssembler {
 	ma.state = maState_midValue
 	switch ma.ca {
 	case 1:
-		x := &_String{}
+		x := &_Bool{}
 		ma.w.x = x
 		if ma.ca1 == nil {
-			ma.ca1 = &_String__Assembler{}
+			ma.ca1 = &_Bool__Assembler{}
 		}
 		ma.ca1.w = x
 		ma.ca1.m = &ma.cm
 		return ma.ca1
 	case 2:
-		x := &_Bytes{}
+		x := &_String{}
 		ma.w.x = x
 		if ma.ca2 == nil {
-			ma.ca2 = &_Bytes__Assembler{}
+			ma.ca2 = &_String__Assembler{}
 		}
 		ma.ca2.w = x
 		ma.ca2.m = &ma.cm
 		return ma.ca2
 	case 3:
-		x := &_Int{}
+		x := &_Bytes{}
 		ma.w.x = x
 		if ma.ca3 == nil {
-			ma.ca3 = &_Int__Assembler{}
+			ma.ca3 = &_Bytes__Assembler{}
 		}
 		ma.ca3.w = x
 		ma.ca3.m = &ma.cm
 		return ma.ca3
 	case 4:
-		x := &_Float{}
+		x := &_Int{}
 		ma.w.x = x
 		if ma.ca4 == nil {
-			ma.ca4 = &_Float__Assembler{}
+			ma.ca4 = &_Int__Assembler{}
 		}
 		ma.ca4.w = x
 		ma.ca4.m = &ma.cm
 		return ma.ca4
 	case 5:
-		x := &_Map{}
+		x := &_Float{}
 		ma.w.x = x
 		if ma.ca5 == nil {
-			ma.ca5 = &_Map__Assembler{}
+			ma.ca5 = &_Float__Assembler{}
 		}
 		ma.ca5.w = x
 		ma.ca5.m = &ma.cm
 		return ma.ca5
 	case 6:
-		x := &_List{}
+		x := &_Map{}
 		ma.w.x = x
 		if ma.ca6 == nil {
-			ma.ca6 = &_List__Assembler{}
+			ma.ca6 = &_Map__Assembler{}
 		}
 		ma.ca6.w = x
 		ma.ca6.m = &ma.cm
 		return ma.ca6
+	case 7:
+		x := &_List{}
+		ma.w.x = x
+		if ma.ca7 == nil {
+			ma.ca7 = &_List__Assembler{}
+		}
+		ma.ca7.w = x
+		ma.ca7.m = &ma.cm
+		return ma.ca7
+	case 8:
+		x := &_Link{}
+		ma.w.x = x
+		if ma.ca8 == nil {
+			ma.ca8 = &_Link__Assembler{}
+		}
+		ma.ca8.w = x
+		ma.ca8.m = &ma.cm
+		return ma.ca8
 	default:
 		panic("unreachable")
 	}
@@ -539,15 +607,15 @@ func (ma *_Any__Assembler) Finish() error {
 	case maState_initial:
 		// carry on
 	case maState_midKey:
-		panic("invalid state: Finish cannot be called when in the middle of assembling a key")
+		return assemblerStateError("invalid state: Finish cannot be called when in the middle of assembling a key")
 	case maState_expectValue:
-		panic("invalid state: Finish cannot be called when expecting start of value assembly")
+		return assemblerStateError("invalid state: Finish cannot be called when expecting start of value assembly")
 	case maState_midValue:
 		if !ma.valueFinishTidy() {
-			panic("invalid state: Finish cannot be called when in the middle of assembling a value")
+			return assemblerStateError("invalid state: Finish cannot be called when in the middle of assembling a value")
 		} // if tidy success: carry on
 	case maState_finished:
-		panic("invalid state: Finish cannot be called on an assembler that's already finished")
+		return assemblerStateError("invalid state: Finish cannot be called on an assembler that's already finished")
 	}
 	if ma.ca == 0 {
 		return schema.ErrNotUnionStructure{TypeName: "dagjose.Any", Detail: "a union must have exactly one entry (not none)!"}
@@ -561,6 +629,8 @@ func (ma *_Any__Assembler) KeyPrototype() datamodel.NodePrototype {
 }
 func (ma *_Any__Assembler) ValuePrototype(k string) datamodel.NodePrototype {
 	switch k {
+	case "Bool":
+		return _Bool__Prototype{}
 	case "String":
 		return _String__Prototype{}
 	case "Bytes":
@@ -573,6 +643,8 @@ func (ma *_Any__Assembler) ValuePrototype(k string) datamodel.NodePrototype {
 		return _Map__Prototype{}
 	case "List":
 		return _List__Prototype{}
+	case "Link":
+		return _Link__Prototype{}
 	default:
 		return nil
 	}
@@ -600,36 +672,44 @@ func (_Any__KeyAssembler) AssignFloat(float64) error {
 }
 func (ka *_Any__KeyAssembler) AssignString(k string) error {
 	if ka.state != maState_midKey {
-		panic("misuse: KeyAssembler held beyond its valid lifetime")
+		return assemblerStateError("misuse: KeyAssembler held beyond its valid lifetime")
 	}
 	if ka.ca != 0 {
 		return schema.ErrNotUnionStructure{TypeName: "dagjose.Any", Detail: "cannot add another entry -- a union can only contain one thing!"}
 	}
 	switch k {
-	case "String":
+	case "Bool":
 		ka.ca = 1
 		ka.state = maState_expectValue
 		return nil
-	case "Bytes":
+	case "String":
 		ka.ca = 2
 		ka.state = maState_expectValue
 		return nil
-	case "Int":
+	case "Bytes":
 		ka.ca = 3
 		ka.state = maState_expectValue
 		return nil
-	case "Float":
+	case "Int":
 		ka.ca = 4
 		ka.state = maState_expectValue
 		return nil
-	case "Map":
+	case "Float":
 		ka.ca = 5
 		ka.state = maState_expectValue
 		return nil
-	case "List":
+	case "Map":
 		ka.ca = 6
 		ka.state = maState_expectValue
 		return nil
+	case "List":
+		ka.ca = 7
+		ka.state = maState_expectValue
+		return nil
+	case "Link":
+		ka.ca = 8
+		ka.state = maState_expectValue
+		return nil
 	}
 	return schema.ErrInvalidKey{TypeName: "dagjose.Any", Key: &_String{k}} // TODO: error quality: ErrInvalidUnionDiscriminant ?
 }
@@ -662,6 +742,8 @@ var _ datamodel.Node = &_Any__Repr{}
 
 func (n *_Any__Repr) Kind() datamodel.Kind {
 	switch n.x.(type) {
+	case Bool:
+		return datamodel.Kind_Bool
 	case String:
 		return datamodel.Kind_String
 	case Bytes:
@@ -674,6 +756,8 @@ func (n *_Any__Repr) Kind() datamodel.Kind {
 		return datamodel.Kind_Map
 	case List:
 		return datamodel.Kind_List
+	case Link:
+		return datamodel.Kind_Link
 	default:
 		panic("unreachable")
 	}
@@ -747,7 +831,12 @@ func (n *_Any__Repr) IsNull() bool {
 	return false
 }
 func (n *_Any__Repr) AsBool() (bool, error) {
-	return false, datamodel.ErrWrongKind{TypeName: "dagjose.Any.Repr", MethodName: "AsBool", AppropriateKind: datamodel.KindSet_JustBool, ActualKind: n.Kind()}
+	switch n2 := n.x.(type) {
+	case Bool:
+		return n2.Representation().AsBool()
+	default:
+		return false, datamodel.ErrWrongKind{TypeName: "dagjose.Any.Repr", MethodName: "AsBool", AppropriateKind: datamodel.KindSet_JustBool, ActualKind: n.Kind()}
+	}
 }
 func (n *_Any__Repr) AsInt() (int64, error) {
 	switch n2 := n.x.(type) {
@@ -782,7 +871,12 @@ func (n *_Any__Repr) AsBytes() ([]byte, error) {
 	}
 }
 func (n *_Any__Repr) AsLink() (datamodel.Link, error) {
-	return nil, datamodel.ErrWrongKind{TypeName: "dagjose.Any.Repr", MethodName: "AsLink", AppropriateKind: datamodel.KindSet_JustLink, ActualKind: n.Kind()}
+	switch n2 := n.x.(type) {
+	case Link:
+		return n2.Representation().AsLink()
+	default:
+		return nil, datamodel.ErrWrongKind{TypeName: "dagjose.Any.Repr", MethodName: "AsLink", AppropriateKind: datamodel.KindSet_JustLink, ActualKind: n.Kind()}
+	}
 }
 func (_Any__Repr) Prototype() datamodel.NodePrototype {
 	return _Any__ReprPrototype{}
@@ -815,12 +909,14 @@ func (nb *_Any__ReprBuilder) Reset() {
 type _Any__ReprAssembler struct {
 	w   *_Any
 	m   *schema.Maybe
-	ca1 *_String__ReprAssembler
-	ca2 *_Bytes__ReprAssembler
-	ca3 *_Int__ReprAssembler
-	ca4 *_Float__ReprAssembler
-	ca5 *_Map__ReprAssembler
-	ca6 *_List__ReprAssembler
+	ca1 *_Bool__ReprAssembler
+	ca2 *_String__ReprAssembler
+	ca3 *_Bytes__ReprAssembler
+	ca4 *_Int__ReprAssembler
+	ca5 *_Float__ReprAssembler
+	ca6 *_Map__ReprAssembler
+	ca7 *_List__ReprAssembler
+	ca8 *_Link__ReprAssembler
 	ca  uint
 }
 
@@ -840,6 +936,10 @@ func (na *_Any__ReprAssembler) reset() {
 		na.ca5.reset()
 	case 6:
 		na.ca6.reset()
+	case 7:
+		na.ca7.reset()
+	case 8:
+		na.ca8.reset()
 	default:
 		panic("unreachable")
 	}
@@ -848,149 +948,174 @@ func (na *_Any__ReprAssembler) reset() {
 func (na *_Any__ReprAssembler) BeginMap(sizeHint int64) (datamodel.MapAssembler, error) {
 	switch *na.m {
 	case schema.Maybe_Value, schema.Maybe_Null:
-		panic("invalid state: cannot assign into assembler that's already finished")
+		return nil, assemblerStateError("invalid state: cannot assign into assembler that's already finished")
 	case midvalue:
-		panic("invalid state: cannot assign into assembler that's already working on a larger structure!")
+		return nil, assemblerStateError("invalid state: cannot assign into assembler that's already working on a larger structure!")
 	}
 	if na.w == nil {
 		na.w = &_Any{}
 	}
-	na.ca = 5
+	na.ca = 6
 	x := &_Map{}
 	na.w.x = x
-	if na.ca5 == nil {
-		na.ca5 = &_Map__ReprAssembler{}
+	if na.ca6 == nil {
+		na.ca6 = &_Map__ReprAssembler{}
 	}
-	na.ca5.w = x
-	na.ca5.m = na.m
-	return na.ca5.BeginMap(sizeHint)
+	na.ca6.w = x
+	na.ca6.m = na.m
+	return na.ca6.BeginMap(sizeHint)
 }
 func (na *_Any__ReprAssembler) BeginList(sizeHint int64) (datamodel.ListAssembler, error) {
 	switch *na.m {
 	case schema.Maybe_Value, schema.Maybe_Null:
-		panic("invalid state: cannot assign into assembler that's already finished")
+		return nil, assemblerStateError("invalid state: cannot assign into assembler that's already finished")
 	case midvalue:
-		panic("invalid state: cannot assign into assembler that's already working on a larger structure!")
+		return nil, assemblerStateError("invalid state: cannot assign into assembler that's already working on a larger structure!")
 	}
 	if na.w == nil {
 		na.w = &_Any{}
 	}
-	na.ca = 6
+	na.ca = 7
 	x := &_List{}
 	na.w.x = x
-	if na.ca6 == nil {
-		na.ca6 = &_List__ReprAssembler{}
+	if na.ca7 == nil {
+		na.ca7 = &_List__ReprAssembler{}
 	}
-	na.ca6.w = x
-	na.ca6.m = na.m
-	return na.ca6.BeginList(sizeHint)
+	na.ca7.w = x
+	na.ca7.m = na.m
+	return na.ca7.BeginList(sizeHint)
 }
 func (na *_Any__ReprAssembler) AssignNull() error {
 	switch *na.m {
+	case allowNull:
+		*na.m = schema.Maybe_Null
+		return nil
 	case schema.Maybe_Value, schema.Maybe_Null:
-		panic("invalid state: cannot assign into assembler that's already finished")
+		return assemblerStateError("invalid state: cannot assign into assembler that's already finished")
 	case midvalue:
-		panic("invalid state: cannot assign into assembler that's already working on a larger structure!")
+		return assemblerStateError("invalid state: cannot assign into assembler that's already working on a larger structure!")
 	}
 	return schema.ErrNotUnionStructure{TypeName: "dagjose.Any.Repr", Detail: "AssignNull called but is not valid for any of the kinds that are valid members of this union"}
 }
 func (na *_Any__ReprAssembler) AssignBool(v bool) error {
 	switch *na.m {
 	case schema.Maybe_Value, schema.Maybe_Null:
-		panic("invalid state: cannot assign into assembler that's already finished")
+		return assemblerStateError("invalid state: cannot assign into assembler that's already finished")
 	case midvalue:
-		panic("invalid state: cannot assign into assembler that's already working on a larger structure!")
+		return assemblerStateError("invalid state: cannot assign into assembler that's already working on a larger structure!")
+	}
+	if na.w == nil {
+		na.w = &_Any{}
+	}
+	na.ca = 1
+	x := &_Bool{}
+	na.w.x = x
+	if na.ca1 == nil {
+		na.ca1 = &_Bool__ReprAssembler{}
 	}
-	return schema.ErrNotUnionStructure{TypeName: "dagjose.Any.Repr", Detail: "AssignBool called but is not valid for any of the kinds that are valid members of this union"}
+	na.ca1.w = x
+	na.ca1.m = na.m
+	return na.ca1.AssignBool(v)
 }
 func (na *_Any__ReprAssembler) AssignInt(v int64) error {
 	switch *na.m {
 	case schema.Maybe_Value, schema.Maybe_Null:
-		panic("invalid state: cannot assign into assembler that's already finished")
+		return assemblerStateError("invalid state: cannot assign into assembler that's already finished")
 	case midvalue:
-		panic("invalid state: cannot assign into assembler that's already working on a larger structure!")
+		return assemblerStateError("invalid state: cannot assign into assembler that's already working on a larger structure!")
 	}
 	if na.w == nil {
 		na.w = &_Any{}
 	}
-	na.ca = 3
+	na.ca = 4
 	x := &_Int{}
 	na.w.x = x
-	if na.ca3 == nil {
-		na.ca3 = &_Int__ReprAssembler{}
+	if na.ca4 == nil {
+		na.ca4 = &_Int__ReprAssembler{}
 	}
-	na.ca3.w = x
-	na.ca3.m = na.m
-	return na.ca3.AssignInt(v)
+	na.ca4.w = x
+	na.ca4.m = na.m
+	return na.ca4.AssignInt(v)
 }
 func (na *_Any__ReprAssembler) AssignFloat(v float64) error {
 	switch *na.m {
 	case schema.Maybe_Value, schema.Maybe_Null:
-		panic("invalid state: cannot assign into assembler that's already finished")
+		return assemblerStateError("invalid state: cannot assign into assembler that's already finished")
 	case midvalue:
-		panic("invalid state: cannot assign into assembler that's already working on a larger structure!")
+		return assemblerStateError("invalid state: cannot assign into assembler that's already working on a larger structure!")
 	}
 	if na.w == nil {
 		na.w = &_Any{}
 	}
-	na.ca = 4
+	na.ca = 5
 	x := &_Float{}
 	na.w.x = x
-	if na.ca4 == nil {
-		na.ca4 = &_Float__ReprAssembler{}
+	if na.ca5 == nil {
+		na.ca5 = &_Float__ReprAssembler{}
 	}
-	na.ca4.w = x
-	na.ca4.m = na.m
-	return na.ca4.AssignFloat(v)
+	na.ca5.w = x
+	na.ca5.m = na.m
+	return na.ca5.AssignFloat(v)
 }
 func (na *_Any__ReprAssembler) AssignString(v string) error {
 	switch *na.m {
 	case schema.Maybe_Value, schema.Maybe_Null:
-		panic("invalid state: cannot assign into assembler that's already finished")
+		return assemblerStateError("invalid state: cannot assign into assembler that's already finished")
 	case midvalue:
-		panic("invalid state: cannot assign into assembler that's already working on a larger structure!")
+		return assemblerStateError("invalid state: cannot assign into assembler that's already working on a larger structure!")
 	}
 	if na.w == nil {
 		na.w = &_Any{}
 	}
-	na.ca = 1
+	na.ca = 2
 	x := &_String{}
 	na.w.x = x
-	if na.ca1 == nil {
-		na.ca1 = &_String__ReprAssembler{}
+	if na.ca2 == nil {
+		na.ca2 = &_String__ReprAssembler{}
 	}
-	na.ca1.w = x
-	na.ca1.m = na.m
-	return na.ca1.AssignString(v)
+	na.ca2.w = x
+	na.ca2.m = na.m
+	return na.ca2.AssignString(v)
 }
 func (na *_Any__ReprAssembler) AssignBytes(v []byte) error {
 	switch *na.m {
 	case schema.Maybe_Value, schema.Maybe_Null:
-		panic("invalid state: cannot assign into assembler that's already finished")
+		return assemblerStateError("invalid state: cannot assign into assembler that's already finished")
 	case midvalue:
-		panic("invalid state: cannot assign into assembler that's already working on a larger structure!")
+		return assemblerStateError("invalid state: cannot assign into assembler that's already working on a larger structure!")
 	}
 	if na.w == nil {
 		na.w = &_Any{}
 	}
-	na.ca = 2
+	na.ca = 3
 	x := &_Bytes{}
 	na.w.x = x
-	if na.ca2 == nil {
-		na.ca2 = &_Bytes__ReprAssembler{}
+	if na.ca3 == nil {
+		na.ca3 = &_Bytes__ReprAssembler{}
 	}
-	na.ca2.w = x
-	na.ca2.m = na.m
-	return na.ca2.AssignBytes(v)
+	na.ca3.w = x
+	na.ca3.m = na.m
+	return na.ca3.AssignBytes(v)
 }
 func (na *_Any__ReprAssembler) AssignLink(v datamodel.Link) error {
 	switch *na.m {
 	case schema.Maybe_Value, schema.Maybe_Null:
-		panic("invalid state: cannot assign into assembler that's already finished")
+		return assemblerStateError("invalid state: cannot assign into assembler that's already finished")
 	case midvalue:
-		panic("invalid state: cannot assign into assembler that's already working on a larger structure!")
+		return assemblerStateError("invalid state: cannot assign into assembler that's already working on a larger structure!")
 	}
-	return schema.ErrNotUnionStructure{TypeName: "dagjose.Any.Repr", Detail: "AssignLink called but is not valid for any of the kinds that are valid members of this union"}
+	if na.w == nil {
+		na.w = &_Any{}
+	}
+	na.ca = 8
+	x := &_Link{}
+	na.w.x = x
+	if na.ca8 == nil {
+		na.ca8 = &_Link__ReprAssembler{}
+	}
+	na.ca8.w = x
+	na.ca8.m = na.m
+	return na.ca8.AssignLink(v)
 }
 func (na *_Any__ReprAssembler) AssignNode(v datamodel.Node) error {
 	if v.IsNull() {
@@ -999,9 +1124,9 @@ func (na *_Any__ReprAssembler) AssignNode(v datamodel.Node) error {
 	if v2, ok := v.(*_Any); ok {
 		switch *na.m {
 		case schema.Maybe_Value, schema.Maybe_Null:
-			panic("invalid state: cannot assign into assembler that's already finished")
+			return assemblerStateError("invalid state: cannot assign into assembler that's already finished")
 		case midvalue:
-			panic("invalid state: cannot assign null into an assembler that's already begun working on recursive structures!")
+			return assemblerStateError("invalid state: cannot assign null into an assembler that's already begun working on recursive structures!")
 		}
 		if na.w == nil {
 			na.w = v2
@@ -1067,13 +1192,218 @@ func (na *_Any__ReprAssembler) AssignNode(v datamodel.Node) error {
 		v2, _ := v.AsLink()
 		return na.AssignLink(v2)
 	default:
-		panic("unreachable")
+		return assemblerStateError("unreachable")
 	}
 }
 func (na *_Any__ReprAssembler) Prototype() datamodel.NodePrototype {
 	return _Any__ReprPrototype{}
 }
 
+func (n Bool) Bool() bool {
+	return n.x
+}
+func (_Bool__Prototype) FromBool(v bool) (Bool, error) {
+	n := _Bool{v}
+	return &n, nil
+}
+
+type _Bool__Maybe struct {
+	m schema.Maybe
+	v _Bool
+}
+type MaybeBool = *_Bool__Maybe
+
+func (m MaybeBool) IsNull() bool {
+	return m.m == schema.Maybe_Null
+}
+func (m MaybeBool) IsAbsent() bool {
+	return m.m == schema.Maybe_Absent
+}
+func (m MaybeBool) Exists() bool {
+	return m.m == schema.Maybe_Value
+}
+func (m MaybeBool) AsNode() datamodel.Node {
+	switch m.m {
+	case schema.Maybe_Absent:
+		return datamodel.Absent
+	case schema.Maybe_Null:
+		return datamodel.Null
+	case schema.Maybe_Value:
+		return &m.v
+	default:
+		panic("unreachable")
+	}
+}
+func (m MaybeBool) Must() Bool {
+	if !m.Exists() {
+		panic("unbox of a maybe rejected")
+	}
+	return &m.v
+}
+
+var _ datamodel.Node = (Bool)(&_Bool{})
+var _ schema.TypedNode = (Bool)(&_Bool{})
+
+func (Bool) Kind() datamodel.Kind {
+	return datamodel.Kind_Bool
+}
+func (Bool) LookupByString(string) (datamodel.Node, error) {
+	return mixins.Bool{TypeName: "dagjose.Bool"}.LookupByString("")
+}
+func (Bool) LookupByNode(datamodel.Node) (datamodel.Node, error) {
+	return mixins.Bool{TypeName: "dagjose.Bool"}.LookupByNode(nil)
+}
+func (Bool) LookupByIndex(idx int64) (datamodel.Node, error) {
+	return mixins.Bool{TypeName: "dagjose.Bool"}.LookupByIndex(0)
+}
+func (Bool) LookupBySegment(seg datamodel.PathSegment) (datamodel.Node, error) {
+	return mixins.Bool{TypeName: "dagjose.Bool"}.LookupBySegment(seg)
+}
+func (Bool) MapIterator() datamodel.MapIterator {
+	return nil
+}
+func (Bool) ListIterator() datamodel.ListIterator {
+	return nil
+}
+func (Bool) Length() int64 {
+	return -1
+}
+func (Bool) IsAbsent() bool {
+	return false
+}
+func (Bool) IsNull() bool {
+	return false
+}
+func (n Bool) AsBool() (bool, error) {
+	return n.x, nil
+}
+func (Bool) AsInt() (int64, error) {
+	return mixins.Bool{TypeName: "dagjose.Bool"}.AsInt()
+}
+func (Bool) AsFloat() (float64, error) {
+	return mixins.Bool{TypeName: "dagjose.Bool"}.AsFloat()
+}
+func (Bool) AsString() (string, error) {
+	return mixins.Bool{TypeName: "dagjose.Bool"}.AsString()
+}
+func (Bool) AsBytes() ([]byte, error) {
+	return mixins.Bool{TypeName: "dagjose.Bool"}.AsBytes()
+}
+func (Bool) AsLink() (datamodel.Link, error) {
+	return mixins.Bool{TypeName: "dagjose.Bool"}.AsLink()
+}
+func (Bool) Prototype() datamodel.NodePrototype {
+	return _Bool__Prototype{}
+}
+
+type _Bool__Prototype struct{}
+
+func (_Bool__Prototype) NewBuilder() datamodel.NodeBuilder {
+	var nb _Bool__Builder
+	nb.Reset()
+	return &nb
+}
+
+type _Bool__Builder struct {
+	_Bool__Assembler
+}
+
+func (nb *_Bool__Builder) Build() datamodel.Node {
+	if *nb.m != schema.Maybe_Value {
+		panic("invalid state: cannot call Build on an assembler that's not finished")
+	}
+	return nb.w
+}
+func (nb *_Bool__Builder) Reset() {
+	var w _Bool
+	var m schema.Maybe
+	*nb = _Bool__Builder{_Bool__Assembler{w: &w, m: &m}}
+}
+
+type _Bool__Assembler struct {
+	w *_Bool
+	m *schema.Maybe
+}
+
+func (na *_Bool__Assembler) reset() {}
+func (_Bool__Assembler) BeginMap(sizeHint int64) (datamodel.MapAssembler, error) {
+	return mixins.BoolAssembler{TypeName: "dagjose.Bool"}.BeginMap(0)
+}
+func (_Bool__Assembler) BeginList(sizeHint int64) (datamodel.ListAssembler, error) {
+	return mixins.BoolAssembler{TypeName: "dagjose.Bool"}.BeginList(0)
+}
+func (na *_Bool__Assembler) AssignNull() error {
+	switch *na.m {
+	case allowNull:
+		*na.m = schema.Maybe_Null
+		return nil
+	case schema.Maybe_Absent:
+		return mixins.BoolAssembler{TypeName: "dagjose.Bool"}.AssignNull()
+	case schema.Maybe_Value, schema.Maybe_Null:
+		return assemblerStateError("invalid state: cannot assign into assembler that's already finished")
+	}
+	return assemblerStateError("unreachable")
+}
+func (na *_Bool__Assembler) AssignBool(v bool) error {
+	switch *na.m {
+	case schema.Maybe_Value, schema.Maybe_Null:
+		return assemblerStateError("invalid state: cannot assign into assembler that's already finished")
+	}
+	na.w.x = v
+	*na.m = schema.Maybe_Value
+	return nil
+}
+func (_Bool__Assembler) AssignInt(int64) error {
+	return mixins.BoolAssembler{TypeName: "dagjose.Bool"}.AssignInt(0)
+}
+func (_Bool__Assembler) AssignFloat(float64) error {
+	return mixins.BoolAssembler{TypeName: "dagjose.Bool"}.AssignFloat(0)
+}
+func (_Bool__Assembler) AssignString(string) error {
+	return mixins.BoolAssembler{TypeName: "dagjose.Bool"}.AssignString("")
+}
+func (_Bool__Assembler) AssignBytes([]byte) error {
+	return mixins.BoolAssembler{TypeName: "dagjose.Bool"}.AssignBytes(nil)
+}
+func (_Bool__Assembler) AssignLink(datamodel.Link) error {
+	return mixins.BoolAssembler{TypeName: "dagjose.Bool"}.AssignLink(nil)
+}
+func (na *_Bool__Assembler) AssignNode(v datamodel.Node) error {
+	if v.IsNull() {
+		return na.AssignNull()
+	}
+	if v2, ok := v.(*_Bool); ok {
+		switch *na.m {
+		case schema.Maybe_Value, schema.Maybe_Null:
+			return assemblerStateError("invalid state: cannot assign into assembler that's already finished")
+		}
+		*na.w = *v2
+		*na.m = schema.Maybe_Value
+		return nil
+	}
+	if v2, err := v.AsBool(); err != nil {
+		return err
+	} else {
+		return na.AssignBool(v2)
+	}
+}
+func (_Bool__Assembler) Prototype() datamodel.NodePrototype {
+	return _Bool__Prototype{}
+}
+func (Bool) Type() schema.Type {
+	return nil /*TODO:typelit*/
+}
+func (n Bool) Representation() datamodel.Node {
+	return (*_Bool__Repr)(n)
+}
+
+type _Bool__Repr = _Bool
+
+var _ datamodel.Node = &_Bool__Repr{}
+
+type _Bool__ReprPrototype = _Bool__Prototype
+type _Bool__ReprAssembler = _Bool__Assembler
+
 func (n Bytes) Bytes() []byte {
 	return n.x
 }
@@ -1215,9 +1545,9 @@ func (na *_Bytes__Assembler) AssignNull() error {
 	case schema.Maybe_Absent:
 		return mixins.BytesAssembler{TypeName: "dagjose.Bytes"}.AssignNull()
 	case schema.Maybe_Value, schema.Maybe_Null:
-		panic("invalid state: cannot assign into assembler that's already finished")
+		return assemblerStateError("invalid state: cannot assign into assembler that's already finished")
 	}
-	panic("unreachable")
+	return assemblerStateError("unreachable")
 }
 func (_Bytes__Assembler) AssignBool(bool) error {
 	return mixins.BytesAssembler{TypeName: "dagjose.Bytes"}.AssignBool(false)
@@ -1234,7 +1564,7 @@ func (_Bytes__Assembler) AssignString(string) error {
 func (na *_Bytes__Assembler) AssignBytes(v []byte) error {
 	switch *na.m {
 	case schema.Maybe_Value, schema.Maybe_Null:
-		panic("invalid state: cannot assign into assembler that's already finished")
+		return assemblerStateError("invalid state: cannot assign into assembler that's already finished")
 	}
 	na.w.x = v
 	*na.m = schema.Maybe_Value
@@ -1250,7 +1580,7 @@ func (na *_Bytes__Assembler) AssignNode(v datamodel.Node) error {
 	if v2, ok := v.(*_Bytes); ok {
 		switch *na.m {
 		case schema.Maybe_Value, schema.Maybe_Null:
-			panic("invalid state: cannot assign into assembler that's already finished")
+			return assemblerStateError("invalid state: cannot assign into assembler that's already finished")
 		}
 		*na.w = *v2
 		*na.m = schema.Maybe_Value
@@ -1571,9 +1901,9 @@ var (
 func (na *_DecodedJWE__Assembler) BeginMap(int64) (datamodel.MapAssembler, error) {
 	switch *na.m {
 	case schema.Maybe_Value, schema.Maybe_Null:
-		panic("invalid state: cannot assign into assembler that's already finished")
+		return nil, assemblerStateError("invalid state: cannot assign into assembler that's already finished")
 	case midvalue:
-		panic("invalid state: it makes no sense to 'begin' twice on the same assembler!")
+		return nil, assemblerStateError("invalid state: it makes no sense to 'begin' twice on the same assembler!")
 	}
 	*na.m = midvalue
 	if na.w == nil {
@@ -1592,11 +1922,11 @@ func (na *_DecodedJWE__Assembler) AssignNull() error {
 	case schema.Maybe_Absent:
 		return mixins.MapAssembler{TypeName: "dagjose.DecodedJWE"}.AssignNull()
 	case schema.Maybe_Value, schema.Maybe_Null:
-		panic("invalid state: cannot assign into assembler that's already finished")
+		return assemblerStateError("invalid state: cannot assign into assembler that's already finished")
 	case midvalue:
-		panic("invalid state: cannot assign null into an assembler that's already begun working on recursive structures!")
+		return assemblerStateError("invalid state: cannot assign null into an assembler that's already begun working on recursive structures!")
 	}
-	panic("unreachable")
+	return assemblerStateError("unreachable")
 }
 func (_DecodedJWE__Assembler) AssignBool(bool) error {
 	return mixins.MapAssembler{TypeName: "dagjose.DecodedJWE"}.AssignBool(false)
@@ -1623,9 +1953,9 @@ func (na *_DecodedJWE__Assembler) AssignNode(v datamodel.Node) error {
 	if v2, ok := v.(*_DecodedJWE); ok {
 		switch *na.m {
 		case schema.Maybe_Value, schema.Maybe_Null:
-			panic("invalid state: cannot assign into assembler that's already finished")
+			return assemblerStateError("invalid state: cannot assign into assembler that's already finished")
 		case midvalue:
-			panic("invalid state: cannot assign null into an assembler that's already begun working on recursive structures!")
+			return assemblerStateError("invalid state: cannot assign null into an assembler that's already begun working on recursive structures!")
 		}
 		if na.w == nil {
 			na.w = v2
@@ -1727,15 +2057,15 @@ func (ma *_DecodedJWE__Assembler) AssembleEntry(k string) (datamodel.NodeAssembl
 	case maState_initial:
 		// carry on
 	case maState_midKey:
-		panic("invalid state: AssembleEntry cannot be called when in the middle of assembling another key")
+		return nil, assemblerStateError("invalid state: AssembleEntry cannot be called when in the middle of assembling another key")
 	case maState_expectValue:
-		panic("invalid state: AssembleEntry cannot be called when expecting start of value assembly")
+		return nil, assemblerStateError("invalid state: AssembleEntry cannot be called when expecting start of value assembly")
 	case maState_midValue:
 		if !ma.valueFinishTidy() {
-			panic("invalid state: AssembleEntry cannot be called when in the middle of assembling a value")
+			return nil, assemblerStateError("invalid state: AssembleEntry cannot be called when in the middle of assembling a value")
 		} // if tidy success: carry on
 	case maState_finished:
-		panic("invalid state: AssembleEntry cannot be called on an assembler that's already finished")
+		return nil, assemblerStateError("invalid state: AssembleEntry cannot be called on an assembler that's already finished")
 	}
 	switch k {
 	case "aad":
@@ -1881,15 +2211,15 @@ func (ma *_DecodedJWE__Assembler) Finish() error {
 	case maState_initial:
 		// carry on
 	case maState_midKey:
-		panic("invalid state: Finish cannot be called when in the middle of assembling a key")
+		return assemblerStateError("invalid state: Finish cannot be called when in the middle of assembling a key")
 	case maState_expectValue:
-		panic("invalid state: Finish cannot be called when expecting start of value assembly")
+		return assemblerStateError("invalid state: Finish cannot be called when expecting start of value assembly")
 	case maState_midValue:
 		if !ma.valueFinishTidy() {
-			panic("invalid state: Finish cannot be called when in the middle of assembling a value")
+			return assemblerStateError("invalid state: Finish cannot be called when in the middle of assembling a value")
 		} // if tidy success: carry on
 	case maState_finished:
-		panic("invalid state: Finish cannot be called on an assembler that's already finished")
+		return assemblerStateError("invalid state: Finish cannot be called on an assembler that's already finished")
 	}
 	if ma.s&fieldBits__DecodedJWE_sufficient != fieldBits__DecodedJWE_sufficient {
 		err := schema.ErrMissingRequiredField{Missing: make([]string, 0)}
@@ -1931,7 +2261,7 @@ func (_DecodedJWE__KeyAssembler) AssignFloat(float64) error {
 }
 func (ka *_DecodedJWE__KeyAssembler) AssignString(k string) error {
 	if ka.state != maState_midKey {
-		panic("misuse: KeyAssembler held beyond its valid lifetime")
+		return assemblerStateError("misuse: KeyAssembler held beyond its valid lifetime")
 	}
 	switch k {
 	case "aad":
@@ -2289,9 +2619,9 @@ func (na *_DecodedJWE__ReprAssembler) reset() {
 func (na *_DecodedJWE__ReprAssembler) BeginMap(int64) (datamodel.MapAssembler, error) {
 	switch *na.m {
 	case schema.Maybe_Value, schema.Maybe_Null:
-		panic("invalid state: cannot assign into assembler that's already finished")
+		return nil, assemblerStateError("invalid state: cannot assign into assembler that's already finished")
 	case midvalue:
-		panic("invalid state: it makes no sense to 'begin' twice on the same assembler!")
+		return nil, assemblerStateError("invalid state: it makes no sense to 'begin' twice on the same assembler!")
 	}
 	*na.m = midvalue
 	if na.w == nil {
@@ -2310,11 +2640,11 @@ func (na *_DecodedJWE__ReprAssembler) AssignNull() error {
 	case schema.Maybe_Absent:
 		return mixins.MapAssembler{TypeName: "dagjose.DecodedJWE.Repr.Repr"}.AssignNull()
 	case schema.Maybe_Value, schema.Maybe_Null:
-		panic("invalid state: cannot assign into assembler that's already finished")
+		return assemblerStateError("invalid state: cannot assign into assembler that's already finished")
 	case midvalue:
-		panic("invalid state: cannot assign null into an assembler that's already begun working on recursive structures!")
+		return assemblerStateError("invalid state: cannot assign null into an assembler that's already begun working on recursive structures!")
 	}
-	panic("unreachable")
+	return assemblerStateError("unreachable")
 }
 func (_DecodedJWE__ReprAssembler) AssignBool(bool) error {
 	return mixins.MapAssembler{TypeName: "dagjose.DecodedJWE.Repr"}.AssignBool(false)
@@ -2341,9 +2671,9 @@ func (na *_DecodedJWE__ReprAssembler) AssignNode(v datamodel.Node) error {
 	if v2, ok := v.(*_DecodedJWE); ok {
 		switch *na.m {
 		case schema.Maybe_Value, schema.Maybe_Null:
-			panic("invalid state: cannot assign into assembler that's already finished")
+			return assemblerStateError("invalid state: cannot assign into assembler that's already finished")
 		case midvalue:
-			panic("invalid state: cannot assign null into an assembler that's already begun working on recursive structures!")
+			return assemblerStateError("invalid state: cannot assign null into an assembler that's already begun working on recursive structures!")
 		}
 		if na.w == nil {
 			na.w = v2
@@ -2444,15 +2774,15 @@ func (ma *_DecodedJWE__ReprAssembler) AssembleEntry(k string) (datamodel.NodeAss
 	case maState_initial:
 		// carry on
 	case maState_midKey:
-		panic("invalid state: AssembleEntry cannot be called when in the middle of assembling another key")
+		return nil, assemblerStateError("invalid state: AssembleEntry cannot be called when in the middle of assembling another key")
 	case maState_expectValue:
-		panic("invalid state: AssembleEntry cannot be called when expecting start of value assembly")
+		return nil, assemblerStateError("invalid state: AssembleEntry cannot be called when expecting start of value assembly")
 	case maState_midValue:
 		if !ma.valueFinishTidy() {
-			panic("invalid state: AssembleEntry cannot be called when in the middle of assembling a value")
+			return nil, assemblerStateError("invalid state: AssembleEntry cannot be called when in the middle of assembling a value")
 		} // if tidy success: carry on
 	case maState_finished:
-		panic("invalid state: AssembleEntry cannot be called on an assembler that's already finished")
+		return nil, assemblerStateError("invalid state: AssembleEntry cannot be called on an assembler that's already finished")
 	}
 	switch k {
 	case "aad":
@@ -2611,15 +2941,15 @@ func (ma *_DecodedJWE__ReprAssembler) Finish() error {
 	case maState_initial:
 		// carry on
 	case maState_midKey:
-		panic("invalid state: Finish cannot be called when in the middle of assembling a key")
+		return assemblerStateError("invalid state: Finish cannot be called when in the middle of assembling a key")
 	case maState_expectValue:
-		panic("invalid state: Finish cannot be called when expecting start of value assembly")
+		return assemblerStateError("invalid state: Finish cannot be called when expecting start of value assembly")
 	case maState_midValue:
 		if !ma.valueFinishTidy() {
-			panic("invalid state: Finish cannot be called when in the middle of assembling a value")
+			return assemblerStateError("invalid state: Finish cannot be called when in the middle of assembling a value")
 		} // if tidy success: carry on
 	case maState_finished:
-		panic("invalid state: Finish cannot be called on an assembler that's already finished")
+		return assemblerStateError("invalid state: Finish cannot be called on an assembler that's already finished")
 	}
 	if ma.s&fieldBits__DecodedJWE_sufficient != fieldBits__DecodedJWE_sufficient {
 		err := schema.ErrMissingRequiredField{Missing: make([]string, 0)}
@@ -2661,7 +2991,7 @@ func (_DecodedJWE__ReprKeyAssembler) AssignFloat(float64) error {
 }
 func (ka *_DecodedJWE__ReprKeyAssembler) AssignString(k string) error {
 	if ka.state != maState_midKey {
-		panic("misuse: KeyAssembler held beyond its valid lifetime")
+		return assemblerStateError("misuse: KeyAssembler held beyond its valid lifetime")
 	}
 	switch k {
 	case "aad":
@@ -2956,9 +3286,9 @@ var (
 func (na *_DecodedJWS__Assembler) BeginMap(int64) (datamodel.MapAssembler, error) {
 	switch *na.m {
 	case schema.Maybe_Value, schema.Maybe_Null:
-		panic("invalid state: cannot assign into assembler that's already finished")
+		return nil, assemblerStateError("invalid state: cannot assign into assembler that's already finished")
 	case midvalue:
-		panic("invalid state: it makes no sense to 'begin' twice on the same assembler!")
+		return nil, assemblerStateError("invalid state: it makes no sense to 'begin' twice on the same assembler!")
 	}
 	*na.m = midvalue
 	if na.w == nil {
@@ -2977,11 +3307,11 @@ func (na *_DecodedJWS__Assembler) AssignNull() error {
 	case schema.Maybe_Absent:
 		return mixins.MapAssembler{TypeName: "dagjose.DecodedJWS"}.AssignNull()
 	case schema.Maybe_Value, schema.Maybe_Null:
-		panic("invalid state: cannot assign into assembler that's already finished")
+		return assemblerStateError("invalid state: cannot assign into assembler that's already finished")
 	case midvalue:
-		panic("invalid state: cannot assign null into an assembler that's already begun working on recursive structures!")
+		return assemblerStateError("invalid state: cannot assign null into an assembler that's already begun working on recursive structures!")
 	}
-	panic("unreachable")
+	return assemblerStateError("unreachable")
 }
 func (_DecodedJWS__Assembler) AssignBool(bool) error {
 	return mixins.MapAssembler{TypeName: "dagjose.DecodedJWS"}.AssignBool(false)
@@ -3008,9 +3338,9 @@ func (na *_DecodedJWS__Assembler) AssignNode(v datamodel.Node) error {
 	if v2, ok := v.(*_DecodedJWS); ok {
 		switch *na.m {
 		case schema.Maybe_Value, schema.Maybe_Null:
-			panic("invalid state: cannot assign into assembler that's already finished")
+			return assemblerStateError("invalid state: cannot assign into assembler that's already finished")
 		case midvalue:
-			panic("invalid state: cannot assign null into an assembler that's already begun working on recursive structures!")
+			return assemblerStateError("invalid state: cannot assign null into an assembler that's already begun working on recursive structures!")
 		}
 		if na.w == nil {
 			na.w = v2
@@ -3079,15 +3409,15 @@ func (ma *_DecodedJWS__Assembler) AssembleEntry(k string) (datamodel.NodeAssembl
 	case maState_initial:
 		// carry on
 	case maState_midKey:
-		panic("invalid state: AssembleEntry cannot be called when in the middle of assembling another key")
+		return nil, assemblerStateError("invalid state: AssembleEntry cannot be called when in the middle of assembling another key")
 	case maState_expectValue:
-		panic("invalid state: AssembleEntry cannot be called when expecting start of value assembly")
+		return nil, assemblerStateError("invalid state: AssembleEntry cannot be called when expecting start of value assembly")
 	case maState_midValue:
 		if !ma.valueFinishTidy() {
-			panic("invalid state: AssembleEntry cannot be called when in the middle of assembling a value")
+			return nil, assemblerStateError("invalid state: AssembleEntry cannot be called when in the middle of assembling a value")
 		} // if tidy success: carry on
 	case maState_finished:
-		panic("invalid state: AssembleEntry cannot be called on an assembler that's already finished")
+		return nil, assemblerStateError("invalid state: AssembleEntry cannot be called on an assembler that's already finished")
 	}
 	switch k {
 	case "link":
@@ -3177,15 +3507,15 @@ func (ma *_DecodedJWS__Assembler) Finish() error {
 	case maState_initial:
 		// carry on
 	case maState_midKey:
-		panic("invalid state: Finish cannot be called when in the middle of assembling a key")
+		return assemblerStateError("invalid state: Finish cannot be called when in the middle of assembling a key")
 	case maState_expectValue:
-		panic("invalid state: Finish cannot be called when expecting start of value assembly")
+		return assemblerStateError("invalid state: Finish cannot be called when expecting start of value assembly")
 	case maState_midValue:
 		if !ma.valueFinishTidy() {
-			panic("invalid state: Finish cannot be called when in the middle of assembling a value")
+			return assemblerStateError("invalid state: Finish cannot be called when in the middle of assembling a value")
 		} // if tidy success: carry on
 	case maState_finished:
-		panic("invalid state: Finish cannot be called on an assembler that's already finished")
+		return assemblerStateError("invalid state: Finish cannot be called on an assembler that's already finished")
 	}
 	if ma.s&fieldBits__DecodedJWS_sufficient != fieldBits__DecodedJWS_sufficient {
 		err := schema.ErrMissingRequiredField{Missing: make([]string, 0)}
@@ -3227,7 +3557,7 @@ func (_DecodedJWS__KeyAssembler) AssignFloat(float64) error {
 }
 func (ka *_DecodedJWS__KeyAssembler) AssignString(k string) error {
 	if ka.state != maState_midKey {
-		panic("misuse: KeyAssembler held beyond its valid lifetime")
+		return assemblerStateError("misuse: KeyAssembler held beyond its valid lifetime")
 	}
 	switch k {
 	case "link":
@@ -3461,9 +3791,9 @@ func (na *_DecodedJWS__ReprAssembler) reset() {
 func (na *_DecodedJWS__ReprAssembler) BeginMap(int64) (datamodel.MapAssembler, error) {
 	switch *na.m {
 	case schema.Maybe_Value, schema.Maybe_Null:
-		panic("invalid state: cannot assign into assembler that's already finished")
+		return nil, assemblerStateError("invalid state: cannot assign into assembler that's already finished")
 	case midvalue:
-		panic("invalid state: it makes no sense to 'begin' twice on the same assembler!")
+		return nil, assemblerStateError("invalid state: it makes no sense to 'begin' twice on the same assembler!")
 	}
 	*na.m = midvalue
 	if na.w == nil {
@@ -3482,11 +3812,11 @@ func (na *_DecodedJWS__ReprAssembler) AssignNull() error {
 	case schema.Maybe_Absent:
 		return mixins.MapAssembler{TypeName: "dagjose.DecodedJWS.Repr.Repr"}.AssignNull()
 	case schema.Maybe_Value, schema.Maybe_Null:
-		panic("invalid state: cannot assign into assembler that's already finished")
+		return assemblerStateError("invalid state: cannot assign into assembler that's already finished")
 	case midvalue:
-		panic("invalid state: cannot assign null into an assembler that's already begun working on recursive structures!")
+		return assemblerStateError("invalid state: cannot assign null into an assembler that's already begun working on recursive structures!")
 	}
-	panic("unreachable")
+	return assemblerStateError("unreachable")
 }
 func (_DecodedJWS__ReprAssembler) AssignBool(bool) error {
 	return mixins.MapAssembler{TypeName: "dagjose.DecodedJWS.Repr"}.AssignBool(false)
@@ -3513,9 +3843,9 @@ func (na *_DecodedJWS__ReprAssembler) AssignNode(v datamodel.Node) error {
 	if v2, ok := v.(*_DecodedJWS); ok {
 		switch *na.m {
 		case schema.Maybe_Value, schema.Maybe_Null:
-			panic("invalid state: cannot assign into assembler that's already finished")
+			return assemblerStateError("invalid state: cannot assign into assembler that's already finished")
 		case midvalue:
-			panic("invalid state: cannot assign null into an assembler that's already begun working on recursive structures!")
+			return assemblerStateError("invalid state: cannot assign null into an assembler that's already begun working on recursive structures!")
 		}
 		if na.w == nil {
 			na.w = v2
@@ -3583,15 +3913,15 @@ func (ma *_DecodedJWS__ReprAssembler) AssembleEntry(k string) (datamodel.NodeAss
 	case maState_initial:
 		// carry on
 	case maState_midKey:
-		panic("invalid state: AssembleEntry cannot be called when in the middle of assembling another key")
+		return nil, assemblerStateError("invalid state: AssembleEntry cannot be called when in the middle of assembling another key")
 	case maState_expectValue:
-		panic("invalid state: AssembleEntry cannot be called when expecting start of value assembly")
+		return nil, assemblerStateError("invalid state: AssembleEntry cannot be called when expecting start of value assembly")
 	case maState_midValue:
 		if !ma.valueFinishTidy() {
-			panic("invalid state: AssembleEntry cannot be called when in the middle of assembling a value")
+			return nil, assemblerStateError("invalid state: AssembleEntry cannot be called when in the middle of assembling a value")
 		} // if tidy success: carry on
 	case maState_finished:
-		panic("invalid state: AssembleEntry cannot be called on an assembler that's already finished")
+		return nil, assemblerStateError("invalid state: AssembleEntry cannot be called on an assembler that's already finished")
 	}
 	switch k {
 	case "link":
@@ -3686,15 +4016,15 @@ func (ma *_DecodedJWS__ReprAssembler) Finish() error {
 	case maState_initial:
 		// carry on
 	case maState_midKey:
-		panic("invalid state: Finish cannot be called when in the middle of assembling a key")
+		return assemblerStateError("invalid state: Finish cannot be called when in the middle of assembling a key")
 	case maState_expectValue:
-		panic("invalid state: Finish cannot be called when expecting start of value assembly")
+		return assemblerStateError("invalid state: Finish cannot be called when expecting start of value assembly")
 	case maState_midValue:
 		if !ma.valueFinishTidy() {
-			panic("invalid state: Finish cannot be called when in the middle of assembling a value")
+			return assemblerStateError("invalid state: Finish cannot be called when in the middle of assembling a value")
 		} // if tidy success: carry on
 	case maState_finished:
-		panic("invalid state: Finish cannot be called on an assembler that's already finished")
+		return assemblerStateError("invalid state: Finish cannot be called on an assembler that's already finished")
 	}
 	if ma.s&fieldBits__DecodedJWS_sufficient != fieldBits__DecodedJWS_sufficient {
 		err := schema.ErrMissingRequiredField{Missing: make([]string, 0)}
@@ -3736,7 +4066,7 @@ func (_DecodedJWS__ReprKeyAssembler) AssignFloat(float64) error {
 }
 func (ka *_DecodedJWS__ReprKeyAssembler) AssignString(k string) error {
 	if ka.state != maState_midKey {
-		panic("misuse: KeyAssembler held beyond its valid lifetime")
+		return assemblerStateError("misuse: KeyAssembler held beyond its valid lifetime")
 	}
 	switch k {
 	case "link":
@@ -3987,9 +4317,9 @@ var (
 func (na *_DecodedRecipient__Assembler) BeginMap(int64) (datamodel.MapAssembler, error) {
 	switch *na.m {
 	case schema.Maybe_Value, schema.Maybe_Null:
-		panic("invalid state: cannot assign into assembler that's already finished")
+		return nil, assemblerStateError("invalid state: cannot assign into assembler that's already finished")
 	case midvalue:
-		panic("invalid state: it makes no sense to 'begin' twice on the same assembler!")
+		return nil, assemblerStateError("invalid state: it makes no sense to 'begin' twice on the same assembler!")
 	}
 	*na.m = midvalue
 	if na.w == nil {
@@ -4008,11 +4338,11 @@ func (na *_DecodedRecipient__Assembler) AssignNull() error {
 	case schema.Maybe_Absent:
 		return mixins.MapAssembler{TypeName: "dagjose.DecodedRecipient"}.AssignNull()
 	case schema.Maybe_Value, schema.Maybe_Null:
-		panic("invalid state: cannot assign into assembler that's already finished")
+		return assemblerStateError("invalid state: cannot assign into assembler that's already finished")
 	case midvalue:
-		panic("invalid state: cannot assign null into an assembler that's already begun working on recursive structures!")
+		return assemblerStateError("invalid state: cannot assign null into an assembler that's already begun working on recursive structures!")
 	}
-	panic("unreachable")
+	return assemblerStateError("unreachable")
 }
 func (_DecodedRecipient__Assembler) AssignBool(bool) error {
 	return mixins.MapAssembler{TypeName: "dagjose.DecodedRecipient"}.AssignBool(false)
@@ -4039,9 +4369,9 @@ func (na *_DecodedRecipient__Assembler) AssignNode(v datamodel.Node) error {
 	if v2, ok := v.(*_DecodedRecipient); ok {
 		switch *na.m {
 		case schema.Maybe_Value, schema.Maybe_Null:
-			panic("invalid state: cannot assign into assembler that's already finished")
+			return assemblerStateError("invalid state: cannot assign into assembler that's already finished")
 		case midvalue:
-			panic("invalid state: cannot assign null into an assembler that's already begun working on recursive structures!")
+			return assemblerStateError("invalid state: cannot assign null into an assembler that's already begun working on recursive structures!")
 		}
 		if na.w == nil {
 			na.w = v2
@@ -4101,15 +4431,15 @@ func (ma *_DecodedRecipient__Assembler) AssembleEntry(k string) (datamodel.NodeA
 	case maState_initial:
 		// carry on
 	case maState_midKey:
-		panic("invalid state: AssembleEntry cannot be called when in the middle of assembling another key")
+		return nil, assemblerStateError("invalid state: AssembleEntry cannot be called when in the middle of assembling another key")
 	case maState_expectValue:
-		panic("invalid state: AssembleEntry cannot be called when expecting start of value assembly")
+		return nil, assemblerStateError("invalid state: AssembleEntry cannot be called when expecting start of value assembly")
 	case maState_midValue:
 		if !ma.valueFinishTidy() {
-			panic("invalid state: AssembleEntry cannot be called when in the middle of assembling a value")
+			return nil, assemblerStateError("invalid state: AssembleEntry cannot be called when in the middle of assembling a value")
 		} // if tidy success: carry on
 	case maState_finished:
-		panic("invalid state: AssembleEntry cannot be called on an assembler that's already finished")
+		return nil, assemblerStateError("invalid state: AssembleEntry cannot be called on an assembler that's already finished")
 	}
 	switch k {
 	case "header":
@@ -4185,15 +4515,15 @@ func (ma *_DecodedRecipient__Assembler) Finish() error {
 	case maState_initial:
 		// carry on
 	case maState_midKey:
-		panic("invalid state: Finish cannot be called when in the middle of assembling a key")
+		return assemblerStateError("invalid state: Finish cannot be called when in the middle of assembling a key")
 	case maState_expectValue:
-		panic("invalid state: Finish cannot be called when expecting start of value assembly")
+		return assemblerStateError("invalid state: Finish cannot be called when expecting start of value assembly")
 	case maState_midValue:
 		if !ma.valueFinishTidy() {
-			panic("invalid state: Finish cannot be called when in the middle of assembling a value")
+			return assemblerStateError("invalid state: Finish cannot be called when in the middle of assembling a value")
 		} // if tidy success: carry on
 	case maState_finished:
-		panic("invalid state: Finish cannot be called on an assembler that's already finished")
+		return assemblerStateError("invalid state: Finish cannot be called on an assembler that's already finished")
 	}
 	if ma.s&fieldBits__DecodedRecipient_sufficient != fieldBits__DecodedRecipient_sufficient {
 		err := schema.ErrMissingRequiredField{Missing: make([]string, 0)}
@@ -4232,7 +4562,7 @@ func (_DecodedRecipient__KeyAssembler) AssignFloat(float64) error {
 }
 func (ka *_DecodedRecipient__KeyAssembler) AssignString(k string) error {
 	if ka.state != maState_midKey {
-		panic("misuse: KeyAssembler held beyond its valid lifetime")
+		return assemblerStateError("misuse: KeyAssembler held beyond its valid lifetime")
 	}
 	switch k {
 	case "header":
@@ -4455,9 +4785,9 @@ func (na *_DecodedRecipient__ReprAssembler) reset() {
 func (na *_DecodedRecipient__ReprAssembler) BeginMap(int64) (datamodel.MapAssembler, error) {
 	switch *na.m {
 	case schema.Maybe_Value, schema.Maybe_Null:
-		panic("invalid state: cannot assign into assembler that's already finished")
+		return nil, assemblerStateError("invalid state: cannot assign into assembler that's already finished")
 	case midvalue:
-		panic("invalid state: it makes no sense to 'begin' twice on the same assembler!")
+		return nil, assemblerStateError("invalid state: it makes no sense to 'begin' twice on the same assembler!")
 	}
 	*na.m = midvalue
 	if na.w == nil {
@@ -4476,11 +4806,11 @@ func (na *_DecodedRecipient__ReprAssembler) AssignNull() error {
 	case schema.Maybe_Absent:
 		return mixins.MapAssembler{TypeName: "dagjose.DecodedRecipient.Repr.Repr"}.AssignNull()
 	case schema.Maybe_Value, schema.Maybe_Null:
-		panic("invalid state: cannot assign into assembler that's already finished")
+		return assemblerStateError("invalid state: cannot assign into assembler that's already finished")
 	case midvalue:
-		panic("invalid state: cannot assign null into an assembler that's already begun working on recursive structures!")
+		return assemblerStateError("invalid state: cannot assign null into an assembler that's already begun working on recursive structures!")
 	}
-	panic("unreachable")
+	return assemblerStateError("unreachable")
 }
 func (_DecodedRecipient__ReprAssembler) AssignBool(bool) error {
 	return mixins.MapAssembler{TypeName: "dagjose.DecodedRecipient.Repr"}.AssignBool(false)
@@ -4507,9 +4837,9 @@ func (na *_DecodedRecipient__ReprAssembler) AssignNode(v datamodel.Node) error {
 	if v2, ok := v.(*_DecodedRecipient); ok {
 		switch *na.m {
 		case schema.Maybe_Value, schema.Maybe_Null:
-			panic("invalid state: cannot assign into assembler that's already finished")
+			return assemblerStateError("invalid state: cannot assign into assembler that's already finished")
 		case midvalue:
-			panic("invalid state: cannot assign null into an assembler that's already begun working on recursive structures!")
+			return assemblerStateError("invalid state: cannot assign null into an assembler that's already begun working on recursive structures!")
 		}
 		if na.w == nil {
 			na.w = v2
@@ -4569,15 +4899,15 @@ func (ma *_DecodedRecipient__ReprAssembler) AssembleEntry(k string) (datamodel.N
 	case maState_initial:
 		// carry on
 	case maState_midKey:
-		panic("invalid state: AssembleEntry cannot be called when in the middle of assembling another key")
+		return nil, assemblerStateError("invalid state: AssembleEntry cannot be called when in the middle of assembling another key")
 	case maState_expectValue:
-		panic("invalid state: AssembleEntry cannot be called when expecting start of value assembly")
+		return nil, assemblerStateError("invalid state: AssembleEntry cannot be called when expecting start of value assembly")
 	case maState_midValue:
 		if !ma.valueFinishTidy() {
-			panic("invalid state: AssembleEntry cannot be called when in the middle of assembling a value")
+			return nil, assemblerStateError("invalid state: AssembleEntry cannot be called when in the middle of assembling a value")
 		} // if tidy success: carry on
 	case maState_finished:
-		panic("invalid state: AssembleEntry cannot be called on an assembler that's already finished")
+		return nil, assemblerStateError("invalid state: AssembleEntry cannot be called on an assembler that's already finished")
 	}
 	switch k {
 	case "header":
@@ -4658,15 +4988,15 @@ func (ma *_DecodedRecipient__ReprAssembler) Finish() error {
 	case maState_initial:
 		// carry on
 	case maState_midKey:
-		panic("invalid state: Finish cannot be called when in the middle of assembling a key")
+		return assemblerStateError("invalid state: Finish cannot be called when in the middle of assembling a key")
 	case maState_expectValue:
-		panic("invalid state: Finish cannot be called when expecting start of value assembly")
+		return assemblerStateError("invalid state: Finish cannot be called when expecting start of value assembly")
 	case maState_midValue:
 		if !ma.valueFinishTidy() {
-			panic("invalid state: Finish cannot be called when in the middle of assembling a value")
+			return assemblerStateError("invalid state: Finish cannot be called when in the middle of assembling a value")
 		} // if tidy success: carry on
 	case maState_finished:
-		panic("invalid state: Finish cannot be called on an assembler that's already finished")
+		return assemblerStateError("invalid state: Finish cannot be called on an assembler that's already finished")
 	}
 	if ma.s&fieldBits__DecodedRecipient_sufficient != fieldBits__DecodedRecipient_sufficient {
 		err := schema.ErrMissingRequiredField{Missing: make([]string, 0)}
@@ -4705,7 +5035,7 @@ func (_DecodedRecipient__ReprKeyAssembler) AssignFloat(float64) error {
 }
 func (ka *_DecodedRecipient__ReprKeyAssembler) AssignString(k string) error {
 	if ka.state != maState_midKey {
-		panic("misuse: KeyAssembler held beyond its valid lifetime")
+		return assemblerStateError("misuse: KeyAssembler held beyond its valid lifetime")
 	}
 	switch k {
 	case "header":
@@ -4950,9 +5280,9 @@ func (_DecodedRecipients__Assembler) BeginMap(sizeHint int64) (datamodel.MapAsse
 func (na *_DecodedRecipients__Assembler) BeginList(sizeHint int64) (datamodel.ListAssembler, error) {
 	switch *na.m {
 	case schema.Maybe_Value, schema.Maybe_Null:
-		panic("invalid state: cannot assign into assembler that's already finished")
+		return nil, assemblerStateError("invalid state: cannot assign into assembler that's already finished")
 	case midvalue:
-		panic("invalid state: it makes no sense to 'begin' twice on the same assembler!")
+		return nil, assemblerStateError("invalid state: it makes no sense to 'begin' twice on the same assembler!")
 	}
 	*na.m = midvalue
 	if sizeHint < 0 {
@@ -4971,11 +5301,11 @@ func (na *_DecodedRecipients__Assembler) AssignNull() error {
 	case schema.Maybe_Absent:
 		return mixins.ListAssembler{TypeName: "dagjose.DecodedRecipients"}.AssignNull()
 	case schema.Maybe_Value, schema.Maybe_Null:
-		panic("invalid state: cannot assign into assembler that's already finished")
+		return assemblerStateError("invalid state: cannot assign into assembler that's already finished")
 	case midvalue:
-		panic("invalid state: cannot assign null into an assembler that's already begun working on recursive structures!")
+		return assemblerStateError("invalid state: cannot assign null into an assembler that's already begun working on recursive structures!")
 	}
-	panic("unreachable")
+	return assemblerStateError("unreachable")
 }
 func (_DecodedRecipients__Assembler) AssignBool(bool) error {
 	return mixins.ListAssembler{TypeName: "dagjose.DecodedRecipients"}.AssignBool(false)
@@ -5002,9 +5332,9 @@ func (na *_DecodedRecipients__Assembler) AssignNode(v datamodel.Node) error {
 	if v2, ok := v.(*_DecodedRecipients); ok {
 		switch *na.m {
 		case schema.Maybe_Value, schema.Maybe_Null:
-			panic("invalid state: cannot assign into assembler that's already finished")
+			return assemblerStateError("invalid state: cannot assign into assembler that's already finished")
 		case midvalue:
-			panic("invalid state: cannot assign null into an assembler that's already begun working on recursive structures!")
+			return assemblerStateError("invalid state: cannot assign null into an assembler that's already begun working on recursive structures!")
 		}
 		*na.w = *v2
 		*na.m = schema.Maybe_Value
@@ -5064,10 +5394,10 @@ func (la *_DecodedRecipients__Assembler) Finish() error {
 		// carry on
 	case laState_midValue:
 		if !la.valueFinishTidy() {
-			panic("invalid state: Finish cannot be called when in the middle of assembling a value")
+			return assemblerStateError("invalid state: Finish cannot be called when in the middle of assembling a value")
 		} // if tidy success: carry on
 	case laState_finished:
-		panic("invalid state: Finish cannot be called on an assembler that's already finished")
+		return assemblerStateError("invalid state: Finish cannot be called on an assembler that's already finished")
 	}
 	la.state = laState_finished
 	*la.m = schema.Maybe_Value
@@ -5208,9 +5538,9 @@ func (_DecodedRecipients__ReprAssembler) BeginMap(sizeHint int64) (datamodel.Map
 func (na *_DecodedRecipients__ReprAssembler) BeginList(sizeHint int64) (datamodel.ListAssembler, error) {
 	switch *na.m {
 	case schema.Maybe_Value, schema.Maybe_Null:
-		panic("invalid state: cannot assign into assembler that's already finished")
+		return nil, assemblerStateError("invalid state: cannot assign into assembler that's already finished")
 	case midvalue:
-		panic("invalid state: it makes no sense to 'begin' twice on the same assembler!")
+		return nil, assemblerStateError("invalid state: it makes no sense to 'begin' twice on the same assembler!")
 	}
 	*na.m = midvalue
 	if sizeHint < 0 {
@@ -5229,11 +5559,11 @@ func (na *_DecodedRecipients__ReprAssembler) AssignNull() error {
 	case schema.Maybe_Absent:
 		return mixins.ListAssembler{TypeName: "dagjose.DecodedRecipients.Repr.Repr"}.AssignNull()
 	case schema.Maybe_Value, schema.Maybe_Null:
-		panic("invalid state: cannot assign into assembler that's already finished")
+		return assemblerStateError("invalid state: cannot assign into assembler that's already finished")
 	case midvalue:
-		panic("invalid state: cannot assign null into an assembler that's already begun working on recursive structures!")
+		return assemblerStateError("invalid state: cannot assign null into an assembler that's already begun working on recursive structures!")
 	}
-	panic("unreachable")
+	return assemblerStateError("unreachable")
 }
 func (_DecodedRecipients__ReprAssembler) AssignBool(bool) error {
 	return mixins.ListAssembler{TypeName: "dagjose.DecodedRecipients.Repr"}.AssignBool(false)
@@ -5260,9 +5590,9 @@ func (na *_DecodedRecipients__ReprAssembler) AssignNode(v datamodel.Node) error
 	if v2, ok := v.(*_DecodedRecipients); ok {
 		switch *na.m {
 		case schema.Maybe_Value, schema.Maybe_Null:
-			panic("invalid state: cannot assign into assembler that's already finished")
+			return assemblerStateError("invalid state: cannot assign into assembler that's already finished")
 		case midvalue:
-			panic("invalid state: cannot assign null into an assembler that's already begun working on recursive structures!")
+			return assemblerStateError("invalid state: cannot assign null into an assembler that's already begun working on recursive structures!")
 		}
 		*na.w = *v2
 		*na.m = schema.Maybe_Value
@@ -5322,10 +5652,10 @@ func (la *_DecodedRecipients__ReprAssembler) Finish() error {
 		// carry on
 	case laState_midValue:
 		if !la.valueFinishTidy() {
-			panic("invalid state: Finish cannot be called when in the middle of assembling a value")
+			return assemblerStateError("invalid state: Finish cannot be called when in the middle of assembling a value")
 		} // if tidy success: carry on
 	case laState_finished:
-		panic("invalid state: Finish cannot be called on an assembler that's already finished")
+		return assemblerStateError("invalid state: Finish cannot be called on an assembler that's already finished")
 	}
 	la.state = laState_finished
 	*la.m = schema.Maybe_Value
@@ -5551,9 +5881,9 @@ var (
 func (na *_DecodedSignature__Assembler) BeginMap(int64) (datamodel.MapAssembler, error) {
 	switch *na.m {
 	case schema.Maybe_Value, schema.Maybe_Null:
-		panic("invalid state: cannot assign into assembler that's already finished")
+		return nil, assemblerStateError("invalid state: cannot assign into assembler that's already finished")
 	case midvalue:
-		panic("invalid state: it makes no sense to 'begin' twice on the same assembler!")
+		return nil, assemblerStateError("invalid state: it makes no sense to 'begin' twice on the same assembler!")
 	}
 	*na.m = midvalue
 	if na.w == nil {
@@ -5572,11 +5902,11 @@ func (na *_DecodedSignature__Assembler) AssignNull() error {
 	case schema.Maybe_Absent:
 		return mixins.MapAssembler{TypeName: "dagjose.DecodedSignature"}.AssignNull()
 	case schema.Maybe_Value, schema.Maybe_Null:
-		panic("invalid state: cannot assign into assembler that's already finished")
+		return assemblerStateError("invalid state: cannot assign into assembler that's already finished")
 	case midvalue:
-		panic("invalid state: cannot assign null into an assembler that's already begun working on recursive structures!")
+		return assemblerStateError("invalid state: cannot assign null into an assembler that's already begun working on recursive structures!")
 	}
-	panic("unreachable")
+	return assemblerStateError("unreachable")
 }
 func (_DecodedSignature__Assembler) AssignBool(bool) error {
 	return mixins.MapAssembler{TypeName: "dagjose.DecodedSignature"}.AssignBool(false)
@@ -5603,9 +5933,9 @@ func (na *_DecodedSignature__Assembler) AssignNode(v datamodel.Node) error {
 	if v2, ok := v.(*_DecodedSignature); ok {
 		switch *na.m {
 		case schema.Maybe_Value, schema.Maybe_Null:
-			panic("invalid state: cannot assign into assembler that's already finished")
+			return assemblerStateError("invalid state: cannot assign into assembler that's already finished")
 		case midvalue:
-			panic("invalid state: cannot assign null into an assembler that's already begun working on recursive structures!")
+			return assemblerStateError("invalid state: cannot assign null into an assembler that's already begun working on recursive structures!")
 		}
 		if na.w == nil {
 			na.w = v2
@@ -5675,15 +6005,15 @@ func (ma *_DecodedSignature__Assembler) AssembleEntry(k string) (datamodel.NodeA
 	case maState_initial:
 		// carry on
 	case maState_midKey:
-		panic("invalid state: AssembleEntry cannot be called when in the middle of assembling another key")
+		return nil, assemblerStateError("invalid state: AssembleEntry cannot be called when in the middle of assembling another key")
 	case maState_expectValue:
-		panic("invalid state: AssembleEntry cannot be called when expecting start of value assembly")
+		return nil, assemblerStateError("invalid state: AssembleEntry cannot be called when expecting start of value assembly")
 	case maState_midValue:
 		if !ma.valueFinishTidy() {
-			panic("invalid state: AssembleEntry cannot be called when in the middle of assembling a value")
+			return nil, assemblerStateError("invalid state: AssembleEntry cannot be called when in the middle of assembling a value")
 		} // if tidy success: carry on
 	case maState_finished:
-		panic("invalid state: AssembleEntry cannot be called on an assembler that's already finished")
+		return nil, assemblerStateError("invalid state: AssembleEntry cannot be called on an assembler that's already finished")
 	}
 	switch k {
 	case "header":
@@ -5773,15 +6103,15 @@ func (ma *_DecodedSignature__Assembler) Finish() error {
 	case maState_initial:
 		// carry on
 	case maState_midKey:
-		panic("invalid state: Finish cannot be called when in the middle of assembling a key")
+		return assemblerStateError("invalid state: Finish cannot be called when in the middle of assembling a key")
 	case maState_expectValue:
-		panic("invalid state: Finish cannot be called when expecting start of value assembly")
+		return assemblerStateError("invalid state: Finish cannot be called when expecting start of value assembly")
 	case maState_midValue:
 		if !ma.valueFinishTidy() {
-			panic("invalid state: Finish cannot be called when in the middle of assembling a value")
+			return assemblerStateError("invalid state: Finish cannot be called when in the middle of assembling a value")
 		} // if tidy success: carry on
 	case maState_finished:
-		panic("invalid state: Finish cannot be called on an assembler that's already finished")
+		return assemblerStateError("invalid state: Finish cannot be called on an assembler that's already finished")
 	}
 	if ma.s&fieldBits__DecodedSignature_sufficient != fieldBits__DecodedSignature_sufficient {
 		err := schema.ErrMissingRequiredField{Missing: make([]string, 0)}
@@ -5823,7 +6153,7 @@ func (_DecodedSignature__KeyAssembler) AssignFloat(float64) error {
 }
 func (ka *_DecodedSignature__KeyAssembler) AssignString(k string) error {
 	if ka.state != maState_midKey {
-		panic("misuse: KeyAssembler held beyond its valid lifetime")
+		return assemblerStateError("misuse: KeyAssembler held beyond its valid lifetime")
 	}
 	switch k {
 	case "header":
@@ -6049,9 +6379,9 @@ func (na *_DecodedSignature__ReprAssembler) reset() {
 func (na *_DecodedSignature__ReprAssembler) BeginMap(int64) (datamodel.MapAssembler, error) {
 	switch *na.m {
 	case schema.Maybe_Value, schema.Maybe_Null:
-		panic("invalid state: cannot assign into assembler that's already finished")
+		return nil, assemblerStateError("invalid state: cannot assign into assembler that's already finished")
 	case midvalue:
-		panic("invalid state: it makes no sense to 'begin' twice on the same assembler!")
+		return nil, assemblerStateError("invalid state: it makes no sense to 'begin' twice on the same assembler!")
 	}
 	*na.m = midvalue
 	if na.w == nil {
@@ -6070,11 +6400,11 @@ func (na *_DecodedSignature__ReprAssembler) AssignNull() error {
 	case schema.Maybe_Absent:
 		return mixins.MapAssembler{TypeName: "dagjose.DecodedSignature.Repr.Repr"}.AssignNull()
 	case schema.Maybe_Value, schema.Maybe_Null:
-		panic("invalid state: cannot assign into assembler that's already finished")
+		return assemblerStateError("invalid state: cannot assign into assembler that's already finished")
 	case midvalue:
-		panic("invalid state: cannot assign null into an assembler that's already begun working on recursive structures!")
+		return assemblerStateError("invalid state: cannot assign null into an assembler that's already begun working on recursive structures!")
 	}
-	panic("unreachable")
+	return assemblerStateError("unreachable")
 }
 func (_DecodedSignature__ReprAssembler) AssignBool(bool) error {
 	return mixins.MapAssembler{TypeName: "dagjose.DecodedSignature.Repr"}.AssignBool(false)
@@ -6101,9 +6431,9 @@ func (na *_DecodedSignature__ReprAssembler) AssignNode(v datamodel.Node) error {
 	if v2, ok := v.(*_DecodedSignature); ok {
 		switch *na.m {
 		case schema.Maybe_Value, schema.Maybe_Null:
-			panic("invalid state: cannot assign into assembler that's already finished")
+			return assemblerStateError("invalid state: cannot assign into assembler that's already finished")
 		case midvalue:
-			panic("invalid state: cannot assign null into an assembler that's already begun working on recursive structures!")
+			return assemblerStateError("invalid state: cannot assign null into an assembler that's already begun working on recursive structures!")
 		}
 		if na.w == nil {
 			na.w = v2
@@ -6172,15 +6502,15 @@ func (ma *_DecodedSignature__ReprAssembler) AssembleEntry(k string) (datamodel.N
 	case maState_initial:
 		// carry on
 	case maState_midKey:
-		panic("invalid state: AssembleEntry cannot be called when in the middle of assembling another key")
+		return nil, assemblerStateError("invalid state: AssembleEntry cannot be called when in the middle of assembling another key")
 	case maState_expectValue:
-		panic("invalid state: AssembleEntry cannot be called when expecting start of value assembly")
+		return nil, assemblerStateError("invalid state: AssembleEntry cannot be called when expecting start of value assembly")
 	case maState_midValue:
 		if !ma.valueFinishTidy() {
-			panic("invalid state: AssembleEntry cannot be called when in the middle of assembling a value")
+			return nil, assemblerStateError("invalid state: AssembleEntry cannot be called when in the middle of assembling a value")
 		} // if tidy success: carry on
 	case maState_finished:
-		panic("invalid state: AssembleEntry cannot be called on an assembler that's already finished")
+		return nil, assemblerStateError("invalid state: AssembleEntry cannot be called on an assembler that's already finished")
 	}
 	switch k {
 	case "header":
@@ -6275,15 +6605,15 @@ func (ma *_DecodedSignature__ReprAssembler) Finish() error {
 	case maState_initial:
 		// carry on
 	case maState_midKey:
-		panic("invalid state: Finish cannot be called when in the middle of assembling a key")
+		return assemblerStateError("invalid state: Finish cannot be called when in the middle of assembling a key")
 	case maState_expectValue:
-		panic("invalid state: Finish cannot be called when expecting start of value assembly")
+		return assemblerStateError("invalid state: Finish cannot be called when expecting start of value assembly")
 	case maState_midValue:
 		if !ma.valueFinishTidy() {
-			panic("invalid state: Finish cannot be called when in the middle of assembling a value")
+			return assemblerStateError("invalid state: Finish cannot be called when in the middle of assembling a value")
 		} // if tidy success: carry on
 	case maState_finished:
-		panic("invalid state: Finish cannot be called on an assembler that's already finished")
+		return assemblerStateError("invalid state: Finish cannot be called on an assembler that's already finished")
 	}
 	if ma.s&fieldBits__DecodedSignature_sufficient != fieldBits__DecodedSignature_sufficient {
 		err := schema.ErrMissingRequiredField{Missing: make([]string, 0)}
@@ -6325,7 +6655,7 @@ func (_DecodedSignature__ReprKeyAssembler) AssignFloat(float64) error {
 }
 func (ka *_DecodedSignature__ReprKeyAssembler) AssignString(k string) error {
 	if ka.state != maState_midKey {
-		panic("misuse: KeyAssembler held beyond its valid lifetime")
+		return assemblerStateError("misuse: KeyAssembler held beyond its valid lifetime")
 	}
 	switch k {
 	case "header":
@@ -6578,9 +6908,9 @@ func (_DecodedSignatures__Assembler) BeginMap(sizeHint int64) (datamodel.MapAsse
 func (na *_DecodedSignatures__Assembler) BeginList(sizeHint int64) (datamodel.ListAssembler, error) {
 	switch *na.m {
 	case schema.Maybe_Value, schema.Maybe_Null:
-		panic("invalid state: cannot assign into assembler that's already finished")
+		return nil, assemblerStateError("invalid state: cannot assign into assembler that's already finished")
 	case midvalue:
-		panic("invalid state: it makes no sense to 'begin' twice on the same assembler!")
+		return nil, assemblerStateError("invalid state: it makes no sense to 'begin' twice on the same assembler!")
 	}
 	*na.m = midvalue
 	if sizeHint < 0 {
@@ -6599,11 +6929,11 @@ func (na *_DecodedSignatures__Assembler) AssignNull() error {
 	case schema.Maybe_Absent:
 		return mixins.ListAssembler{TypeName: "dagjose.DecodedSignatures"}.AssignNull()
 	case schema.Maybe_Value, schema.Maybe_Null:
-		panic("invalid state: cannot assign into assembler that's already finished")
+		return assemblerStateError("invalid state: cannot assign into assembler that's already finished")
 	case midvalue:
-		panic("invalid state: cannot assign null into an assembler that's already begun working on recursive structures!")
+		return assemblerStateError("invalid state: cannot assign null into an assembler that's already begun working on recursive structures!")
 	}
-	panic("unreachable")
+	return assemblerStateError("unreachable")
 }
 func (_DecodedSignatures__Assembler) AssignBool(bool) error {
 	return mixins.ListAssembler{TypeName: "dagjose.DecodedSignatures"}.AssignBool(false)
@@ -6630,9 +6960,9 @@ func (na *_DecodedSignatures__Assembler) AssignNode(v datamodel.Node) error {
 	if v2, ok := v.(*_DecodedSignatures); ok {
 		switch *na.m {
 		case schema.Maybe_Value, schema.Maybe_Null:
-			panic("invalid state: cannot assign into assembler that's already finished")
+			return assemblerStateError("invalid state: cannot assign into assembler that's already finished")
 		case midvalue:
-			panic("invalid state: cannot assign null into an assembler that's already begun working on recursive structures!")
+			return assemblerStateError("invalid state: cannot assign null into an assembler that's already begun working on recursive structures!")
 		}
 		*na.w = *v2
 		*na.m = schema.Maybe_Value
@@ -6692,10 +7022,10 @@ func (la *_DecodedSignatures__Assembler) Finish() error {
 		// carry on
 	case laState_midValue:
 		if !la.valueFinishTidy() {
-			panic("invalid state: Finish cannot be called when in the middle of assembling a value")
+			return assemblerStateError("invalid state: Finish cannot be called when in the middle of assembling a value")
 		} // if tidy success: carry on
 	case laState_finished:
-		panic("invalid state: Finish cannot be called on an assembler that's already finished")
+		return assemblerStateError("invalid state: Finish cannot be called on an assembler that's already finished")
 	}
 	la.state = laState_finished
 	*la.m = schema.Maybe_Value
@@ -6836,9 +7166,9 @@ func (_DecodedSignatures__ReprAssembler) BeginMap(sizeHint int64) (datamodel.Map
 func (na *_DecodedSignatures__ReprAssembler) BeginList(sizeHint int64) (datamodel.ListAssembler, error) {
 	switch *na.m {
 	case schema.Maybe_Value, schema.Maybe_Null:
-		panic("invalid state: cannot assign into assembler that's already finished")
+		return nil, assemblerStateError("invalid state: cannot assign into assembler that's already finished")
 	case midvalue:
-		panic("invalid state: it makes no sense to 'begin' twice on the same assembler!")
+		return nil, assemblerStateError("invalid state: it makes no sense to 'begin' twice on the same assembler!")
 	}
 	*na.m = midvalue
 	if sizeHint < 0 {
@@ -6857,11 +7187,11 @@ func (na *_DecodedSignatures__ReprAssembler) AssignNull() error {
 	case schema.Maybe_Absent:
 		return mixins.ListAssembler{TypeName: "dagjose.DecodedSignatures.Repr.Repr"}.AssignNull()
 	case schema.Maybe_Value, schema.Maybe_Null:
-		panic("invalid state: cannot assign into assembler that's already finished")
+		return assemblerStateError("invalid state: cannot assign into assembler that's already finished")
 	case midvalue:
-		panic("invalid state: cannot assign null into an assembler that's already begun working on recursive structures!")
+		return assemblerStateError("invalid state: cannot assign null into an assembler that's already begun working on recursive structures!")
 	}
-	panic("unreachable")
+	return assemblerStateError("unreachable")
 }
 func (_DecodedSignatures__ReprAssembler) AssignBool(bool) error {
 	return mixins.ListAssembler{TypeName: "dagjose.DecodedSignatures.Repr"}.AssignBool(false)
@@ -6888,9 +7218,9 @@ func (na *_DecodedSignatures__ReprAssembler) AssignNode(v datamodel.Node) error
 	if v2, ok := v.(*_DecodedSignatures); ok {
 		switch *na.m {
 		case schema.Maybe_Value, schema.Maybe_Null:
-			panic("invalid state: cannot assign into assembler that's already finished")
+			return assemblerStateError("invalid state: cannot assign into assembler that's already finished")
 		case midvalue:
-			panic("invalid state: cannot assign null into an assembler that's already begun working on recursive structures!")
+			return assemblerStateError("invalid state: cannot assign null into an assembler that's already begun working on recursive structures!")
 		}
 		*na.w = *v2
 		*na.m = schema.Maybe_Value
@@ -6950,10 +7280,10 @@ func (la *_DecodedSignatures__ReprAssembler) Finish() error {
 		// carry on
 	case laState_midValue:
 		if !la.valueFinishTidy() {
-			panic("invalid state: Finish cannot be called when in the middle of assembling a value")
+			return assemblerStateError("invalid state: Finish cannot be called when in the middle of assembling a value")
 		} // if tidy success: carry on
 	case laState_finished:
-		panic("invalid state: Finish cannot be called on an assembler that's already finished")
+		return assemblerStateError("invalid state: Finish cannot be called on an assembler that's already finished")
 	}
 	la.state = laState_finished
 	*la.m = schema.Maybe_Value
@@ -7255,9 +7585,9 @@ var (
 func (na *_EncodedJWE__Assembler) BeginMap(int64) (datamodel.MapAssembler, error) {
 	switch *na.m {
 	case schema.Maybe_Value, schema.Maybe_Null:
-		panic("invalid state: cannot assign into assembler that's already finished")
+		return nil, assemblerStateError("invalid state: cannot assign into assembler that's already finished")
 	case midvalue:
-		panic("invalid state: it makes no sense to 'begin' twice on the same assembler!")
+		return nil, assemblerStateError("invalid state: it makes no sense to 'begin' twice on the same assembler!")
 	}
 	*na.m = midvalue
 	if na.w == nil {
@@ -7276,11 +7606,11 @@ func (na *_EncodedJWE__Assembler) AssignNull() error {
 	case schema.Maybe_Absent:
 		return mixins.MapAssembler{TypeName: "dagjose.EncodedJWE"}.AssignNull()
 	case schema.Maybe_Value, schema.Maybe_Null:
-		panic("invalid state: cannot assign into assembler that's already finished")
+		return assemblerStateError("invalid state: cannot assign into assembler that's already finished")
 	case midvalue:
-		panic("invalid state: cannot assign null into an assembler that's already begun working on recursive structures!")
+		return assemblerStateError("invalid state: cannot assign null into an assembler that's already begun working on recursive structures!")
 	}
-	panic("unreachable")
+	return assemblerStateError("unreachable")
 }
 func (_EncodedJWE__Assembler) AssignBool(bool) error {
 	return mixins.MapAssembler{TypeName: "dagjose.EncodedJWE"}.AssignBool(false)
@@ -7307,9 +7637,9 @@ func (na *_EncodedJWE__Assembler) AssignNode(v datamodel.Node) error {
 	if v2, ok := v.(*_EncodedJWE); ok {
 		switch *na.m {
 		case schema.Maybe_Value, schema.Maybe_Null:
-			panic("invalid state: cannot assign into assembler that's already finished")
+			return assemblerStateError("invalid state: cannot assign into assembler that's already finished")
 		case midvalue:
-			panic("invalid state: cannot assign null into an assembler that's already begun working on recursive structures!")
+			return assemblerStateError("invalid state: cannot assign null into an assembler that's already begun working on recursive structures!")
 		}
 		if na.w == nil {
 			na.w = v2
@@ -7411,15 +7741,15 @@ func (ma *_EncodedJWE__Assembler) AssembleEntry(k string) (datamodel.NodeAssembl
 	case maState_initial:
 		// carry on
 	case maState_midKey:
-		panic("invalid state: AssembleEntry cannot be called when in the middle of assembling another key")
+		return nil, assemblerStateError("invalid state: AssembleEntry cannot be called when in the middle of assembling another key")
 	case maState_expectValue:
-		panic("invalid state: AssembleEntry cannot be called when expecting start of value assembly")
+		return nil, assemblerStateError("invalid state: AssembleEntry cannot be called when expecting start of value assembly")
 	case maState_midValue:
 		if !ma.valueFinishTidy() {
-			panic("invalid state: AssembleEntry cannot be called when in the middle of assembling a value")
+			return nil, assemblerStateError("invalid state: AssembleEntry cannot be called when in the middle of assembling a value")
 		} // if tidy success: carry on
 	case maState_finished:
-		panic("invalid state: AssembleEntry cannot be called on an assembler that's already finished")
+		return nil, assemblerStateError("invalid state: AssembleEntry cannot be called on an assembler that's already finished")
 	}
 	switch k {
 	case "aad":
@@ -7565,15 +7895,15 @@ func (ma *_EncodedJWE__Assembler) Finish() error {
 	case maState_initial:
 		// carry on
 	case maState_midKey:
-		panic("invalid state: Finish cannot be called when in the middle of assembling a key")
+		return assemblerStateError("invalid state: Finish cannot be called when in the middle of assembling a key")
 	case maState_expectValue:
-		panic("invalid state: Finish cannot be called when expecting start of value assembly")
+		return assemblerStateError("invalid state: Finish cannot be called when expecting start of value assembly")
 	case maState_midValue:
 		if !ma.valueFinishTidy() {
-			panic("invalid state: Finish cannot be called when in the middle of assembling a value")
+			return assemblerStateError("invalid state: Finish cannot be called when in the middle of assembling a value")
 		} // if tidy success: carry on
 	case maState_finished:
-		panic("invalid state: Finish cannot be called on an assembler that's already finished")
+		return assemblerStateError("invalid state: Finish cannot be called on an assembler that's already finished")
 	}
 	if ma.s&fieldBits__EncodedJWE_sufficient != fieldBits__EncodedJWE_sufficient {
 		err := schema.ErrMissingRequiredField{Missing: make([]string, 0)}
@@ -7615,7 +7945,7 @@ func (_EncodedJWE__KeyAssembler) AssignFloat(float64) error {
 }
 func (ka *_EncodedJWE__KeyAssembler) AssignString(k string) error {
 	if ka.state != maState_midKey {
-		panic("misuse: KeyAssembler held beyond its valid lifetime")
+		return assemblerStateError("misuse: KeyAssembler held beyond its valid lifetime")
 	}
 	switch k {
 	case "aad":
@@ -7973,9 +8303,9 @@ func (na *_EncodedJWE__ReprAssembler) reset() {
 func (na *_EncodedJWE__ReprAssembler) BeginMap(int64) (datamodel.MapAssembler, error) {
 	switch *na.m {
 	case schema.Maybe_Value, schema.Maybe_Null:
-		panic("invalid state: cannot assign into assembler that's already finished")
+		return nil, assemblerStateError("invalid state: cannot assign into assembler that's already finished")
 	case midvalue:
-		panic("invalid state: it makes no sense to 'begin' twice on the same assembler!")
+		return nil, assemblerStateError("invalid state: it makes no sense to 'begin' twice on the same assembler!")
 	}
 	*na.m = midvalue
 	if na.w == nil {
@@ -7994,11 +8324,11 @@ func (na *_EncodedJWE__ReprAssembler) AssignNull() error {
 	case schema.Maybe_Absent:
 		return mixins.MapAssembler{TypeName: "dagjose.EncodedJWE.Repr.Repr"}.AssignNull()
 	case schema.Maybe_Value, schema.Maybe_Null:
-		panic("invalid state: cannot assign into assembler that's already finished")
+		return assemblerStateError("invalid state: cannot assign into assembler that's already finished")
 	case midvalue:
-		panic("invalid state: cannot assign null into an assembler that's already begun working on recursive structures!")
+		return assemblerStateError("invalid state: cannot assign null into an assembler that's already begun working on recursive structures!")
 	}
-	panic("unreachable")
+	return assemblerStateError("unreachable")
 }
 func (_EncodedJWE__ReprAssembler) AssignBool(bool) error {
 	return mixins.MapAssembler{TypeName: "dagjose.EncodedJWE.Repr"}.AssignBool(false)
@@ -8025,9 +8355,9 @@ func (na *_EncodedJWE__ReprAssembler) AssignNode(v datamodel.Node) error {
 	if v2, ok := v.(*_EncodedJWE); ok {
 		switch *na.m {
 		case schema.Maybe_Value, schema.Maybe_Null:
-			panic("invalid state: cannot assign into assembler that's already finished")
+			return assemblerStateError("invalid state: cannot assign into assembler that's already finished")
 		case midvalue:
-			panic("invalid state: cannot assign null into an assembler that's already begun working on recursive structures!")
+			return assemblerStateError("invalid state: cannot assign null into an assembler that's already begun working on recursive structures!")
 		}
 		if na.w == nil {
 			na.w = v2
@@ -8128,15 +8458,15 @@ func (ma *_EncodedJWE__ReprAssembler) AssembleEntry(k string) (datamodel.NodeAss
 	case maState_initial:
 		// carry on
 	case maState_midKey:
-		panic("invalid state: AssembleEntry cannot be called when in the middle of assembling another key")
+		return nil, assemblerStateError("invalid state: AssembleEntry cannot be called when in the middle of assembling another key")
 	case maState_expectValue:
-		panic("invalid state: AssembleEntry cannot be called when expecting start of value assembly")
+		return nil, assemblerStateError("invalid state: AssembleEntry cannot be called when expecting start of value assembly")
 	case maState_midValue:
 		if !ma.valueFinishTidy() {
-			panic("invalid state: AssembleEntry cannot be called when in the middle of assembling a value")
+			return nil, assemblerStateError("invalid state: AssembleEntry cannot be called when in the middle of assembling a value")
 		} // if tidy success: carry on
 	case maState_finished:
-		panic("invalid state: AssembleEntry cannot be called on an assembler that's already finished")
+		return nil, assemblerStateError("invalid state: AssembleEntry cannot be called on an assembler that's already finished")
 	}
 	switch k {
 	case "aad":
@@ -8295,15 +8625,15 @@ func (ma *_EncodedJWE__ReprAssembler) Finish() error {
 	case maState_initial:
 		// carry on
 	case maState_midKey:
-		panic("invalid state: Finish cannot be called when in the middle of assembling a key")
+		return assemblerStateError("invalid state: Finish cannot be called when in the middle of assembling a key")
 	case maState_expectValue:
-		panic("invalid state: Finish cannot be called when expecting start of value assembly")
+		return assemblerStateError("invalid state: Finish cannot be called when expecting start of value assembly")
 	case maState_midValue:
 		if !ma.valueFinishTidy() {
-			panic("invalid state: Finish cannot be called when in the middle of assembling a value")
+			return assemblerStateError("invalid state: Finish cannot be called when in the middle of assembling a value")
 		} // if tidy success: carry on
 	case maState_finished:
-		panic("invalid state: Finish cannot be called on an assembler that's already finished")
+		return assemblerStateError("invalid state: Finish cannot be called on an assembler that's already finished")
 	}
 	if ma.s&fieldBits__EncodedJWE_sufficient != fieldBits__EncodedJWE_sufficient {
 		err := schema.ErrMissingRequiredField{Missing: make([]string, 0)}
@@ -8345,7 +8675,7 @@ func (_EncodedJWE__ReprKeyAssembler) AssignFloat(float64) error {
 }
 func (ka *_EncodedJWE__ReprKeyAssembler) AssignString(k string) error {
 	if ka.state != maState_midKey {
-		panic("misuse: KeyAssembler held beyond its valid lifetime")
+		return assemblerStateError("misuse: KeyAssembler held beyond its valid lifetime")
 	}
 	switch k {
 	case "aad":
@@ -8621,9 +8951,9 @@ var (
 func (na *_EncodedJWS__Assembler) BeginMap(int64) (datamodel.MapAssembler, error) {
 	switch *na.m {
 	case schema.Maybe_Value, schema.Maybe_Null:
-		panic("invalid state: cannot assign into assembler that's already finished")
+		return nil, assemblerStateError("invalid state: cannot assign into assembler that's already finished")
 	case midvalue:
-		panic("invalid state: it makes no sense to 'begin' twice on the same assembler!")
+		return nil, assemblerStateError("invalid state: it makes no sense to 'begin' twice on the same assembler!")
 	}
 	*na.m = midvalue
 	if na.w == nil {
@@ -8642,11 +8972,11 @@ func (na *_EncodedJWS__Assembler) AssignNull() error {
 	case schema.Maybe_Absent:
 		return mixins.MapAssembler{TypeName: "dagjose.EncodedJWS"}.AssignNull()
 	case schema.Maybe_Value, schema.Maybe_Null:
-		panic("invalid state: cannot assign into assembler that's already finished")
+		return assemblerStateError("invalid state: cannot assign into assembler that's already finished")
 	case midvalue:
-		panic("invalid state: cannot assign null into an assembler that's already begun working on recursive structures!")
+		return assemblerStateError("invalid state: cannot assign null into an assembler that's already begun working on recursive structures!")
 	}
-	panic("unreachable")
+	return assemblerStateError("unreachable")
 }
 func (_EncodedJWS__Assembler) AssignBool(bool) error {
 	return mixins.MapAssembler{TypeName: "dagjose.EncodedJWS"}.AssignBool(false)
@@ -8673,9 +9003,9 @@ func (na *_EncodedJWS__Assembler) AssignNode(v datamodel.Node) error {
 	if v2, ok := v.(*_EncodedJWS); ok {
 		switch *na.m {
 		case schema.Maybe_Value, schema.Maybe_Null:
-			panic("invalid state: cannot assign into assembler that's already finished")
+			return assemblerStateError("invalid state: cannot assign into assembler that's already finished")
 		case midvalue:
-			panic("invalid state: cannot assign null into an assembler that's already begun working on recursive structures!")
+			return assemblerStateError("invalid state: cannot assign null into an assembler that's already begun working on recursive structures!")
 		}
 		if na.w == nil {
 			na.w = v2
@@ -8736,15 +9066,15 @@ func (ma *_EncodedJWS__Assembler) AssembleEntry(k string) (datamodel.NodeAssembl
 	case maState_initial:
 		// carry on
 	case maState_midKey:
-		panic("invalid state: AssembleEntry cannot be called when in the middle of assembling another key")
+		return nil, assemblerStateError("invalid state: AssembleEntry cannot be called when in the middle of assembling another key")
 	case maState_expectValue:
-		panic("invalid state: AssembleEntry cannot be called when expecting start of value assembly")
+		return nil, assemblerStateError("invalid state: AssembleEntry cannot be called when expecting start of value assembly")
 	case maState_midValue:
 		if !ma.valueFinishTidy() {
-			panic("invalid state: AssembleEntry cannot be called when in the middle of assembling a value")
+			return nil, assemblerStateError("invalid state: AssembleEntry cannot be called when in the middle of assembling a value")
 		} // if tidy success: carry on
 	case maState_finished:
-		panic("invalid state: AssembleEntry cannot be called on an assembler that's already finished")
+		return nil, assemblerStateError("invalid state: AssembleEntry cannot be called on an assembler that's already finished")
 	}
 	switch k {
 	case "payload":
@@ -8820,15 +9150,15 @@ func (ma *_EncodedJWS__Assembler) Finish() error {
 	case maState_initial:
 		// carry on
 	case maState_midKey:
-		panic("invalid state: Finish cannot be called when in the middle of assembling a key")
+		return assemblerStateError("invalid state: Finish cannot be called when in the middle of assembling a key")
 	case maState_expectValue:
-		panic("invalid state: Finish cannot be called when expecting start of value assembly")
+		return assemblerStateError("invalid state: Finish cannot be called when expecting start of value assembly")
 	case maState_midValue:
 		if !ma.valueFinishTidy() {
-			panic("invalid state: Finish cannot be called when in the middle of assembling a value")
+			return assemblerStateError("invalid state: Finish cannot be called when in the middle of assembling a value")
 		} // if tidy success: carry on
 	case maState_finished:
-		panic("invalid state: Finish cannot be called on an assembler that's already finished")
+		return assemblerStateError("invalid state: Finish cannot be called on an assembler that's already finished")
 	}
 	if ma.s&fieldBits__EncodedJWS_sufficient != fieldBits__EncodedJWS_sufficient {
 		err := schema.ErrMissingRequiredField{Missing: make([]string, 0)}
@@ -8870,7 +9200,7 @@ func (_EncodedJWS__KeyAssembler) AssignFloat(float64) error {
 }
 func (ka *_EncodedJWS__KeyAssembler) AssignString(k string) error {
 	if ka.state != maState_midKey {
-		panic("misuse: KeyAssembler held beyond its valid lifetime")
+		return assemblerStateError("misuse: KeyAssembler held beyond its valid lifetime")
 	}
 	switch k {
 	case "payload":
@@ -9078,9 +9408,9 @@ func (na *_EncodedJWS__ReprAssembler) reset() {
 func (na *_EncodedJWS__ReprAssembler) BeginMap(int64) (datamodel.MapAssembler, error) {
 	switch *na.m {
 	case schema.Maybe_Value, schema.Maybe_Null:
-		panic("invalid state: cannot assign into assembler that's already finished")
+		return nil, assemblerStateError("invalid state: cannot assign into assembler that's already finished")
 	case midvalue:
-		panic("invalid state: it makes no sense to 'begin' twice on the same assembler!")
+		return nil, assemblerStateError("invalid state: it makes no sense to 'begin' twice on the same assembler!")
 	}
 	*na.m = midvalue
 	if na.w == nil {
@@ -9099,11 +9429,11 @@ func (na *_EncodedJWS__ReprAssembler) AssignNull() error {
 	case schema.Maybe_Absent:
 		return mixins.MapAssembler{TypeName: "dagjose.EncodedJWS.Repr.Repr"}.AssignNull()
 	case schema.Maybe_Value, schema.Maybe_Null:
-		panic("invalid state: cannot assign into assembler that's already finished")
+		return assemblerStateError("invalid state: cannot assign into assembler that's already finished")
 	case midvalue:
-		panic("invalid state: cannot assign null into an assembler that's already begun working on recursive structures!")
+		return assemblerStateError("invalid state: cannot assign null into an assembler that's already begun working on recursive structures!")
 	}
-	panic("unreachable")
+	return assemblerStateError("unreachable")
 }
 func (_EncodedJWS__ReprAssembler) AssignBool(bool) error {
 	return mixins.MapAssembler{TypeName: "dagjose.EncodedJWS.Repr"}.AssignBool(false)
@@ -9130,9 +9460,9 @@ func (na *_EncodedJWS__ReprAssembler) AssignNode(v datamodel.Node) error {
 	if v2, ok := v.(*_EncodedJWS); ok {
 		switch *na.m {
 		case schema.Maybe_Value, schema.Maybe_Null:
-			panic("invalid state: cannot assign into assembler that's already finished")
+			return assemblerStateError("invalid state: cannot assign into assembler that's already finished")
 		case midvalue:
-			panic("invalid state: cannot assign null into an assembler that's already begun working on recursive structures!")
+			return assemblerStateError("invalid state: cannot assign null into an assembler that's already begun working on recursive structures!")
 		}
 		if na.w == nil {
 			na.w = v2
@@ -9192,15 +9522,15 @@ func (ma *_EncodedJWS__ReprAssembler) AssembleEntry(k string) (datamodel.NodeAss
 	case maState_initial:
 		// carry on
 	case maState_midKey:
-		panic("invalid state: AssembleEntry cannot be called when in the middle of assembling another key")
+		return nil, assemblerStateError("invalid state: AssembleEntry cannot be called when in the middle of assembling another key")
 	case maState_expectValue:
-		panic("invalid state: AssembleEntry cannot be called when expecting start of value assembly")
+		return nil, assemblerStateError("invalid state: AssembleEntry cannot be called when expecting start of value assembly")
 	case maState_midValue:
 		if !ma.valueFinishTidy() {
-			panic("invalid state: AssembleEntry cannot be called when in the middle of assembling a value")
+			return nil, assemblerStateError("invalid state: AssembleEntry cannot be called when in the middle of assembling a value")
 		} // if tidy success: carry on
 	case maState_finished:
-		panic("invalid state: AssembleEntry cannot be called on an assembler that's already finished")
+		return nil, assemblerStateError("invalid state: AssembleEntry cannot be called on an assembler that's already finished")
 	}
 	switch k {
 	case "payload":
@@ -9279,15 +9609,15 @@ func (ma *_EncodedJWS__ReprAssembler) Finish() error {
 	case maState_initial:
 		// carry on
 	case maState_midKey:
-		panic("invalid state: Finish cannot be called when in the middle of assembling a key")
+		return assemblerStateError("invalid state: Finish cannot be called when in the middle of assembling a key")
 	case maState_expectValue:
-		panic("invalid state: Finish cannot be called when expecting start of value assembly")
+		return assemblerStateError("invalid state: Finish cannot be called when expecting start of value assembly")
 	case maState_midValue:
 		if !ma.valueFinishTidy() {
-			panic("invalid state: Finish cannot be called when in the middle of assembling a value")
+			return assemblerStateError("invalid state: Finish cannot be called when in the middle of assembling a value")
 		} // if tidy success: carry on
 	case maState_finished:
-		panic("invalid state: Finish cannot be called on an assembler that's already finished")
+		return assemblerStateError("invalid state: Finish cannot be called on an assembler that's already finished")
 	}
 	if ma.s&fieldBits__EncodedJWS_sufficient != fieldBits__EncodedJWS_sufficient {
 		err := schema.ErrMissingRequiredField{Missing: make([]string, 0)}
@@ -9329,7 +9659,7 @@ func (_EncodedJWS__ReprKeyAssembler) AssignFloat(float64) error {
 }
 func (ka *_EncodedJWS__ReprKeyAssembler) AssignString(k string) error {
 	if ka.state != maState_midKey {
-		panic("misuse: KeyAssembler held beyond its valid lifetime")
+		return assemblerStateError("misuse: KeyAssembler held beyond its valid lifetime")
 	}
 	switch k {
 	case "payload":
@@ -9572,9 +9902,9 @@ var (
 func (na *_EncodedRecipient__Assembler) BeginMap(int64) (datamodel.MapAssembler, error) {
 	switch *na.m {
 	case schema.Maybe_Value, schema.Maybe_Null:
-		panic("invalid state: cannot assign into assembler that's already finished")
+		return nil, assemblerStateError("invalid state: cannot assign into assembler that's already finished")
 	case midvalue:
-		panic("invalid state: it makes no sense to 'begin' twice on the same assembler!")
+		return nil, assemblerStateError("invalid state: it makes no sense to 'begin' twice on the same assembler!")
 	}
 	*na.m = midvalue
 	if na.w == nil {
@@ -9593,11 +9923,11 @@ func (na *_EncodedRecipient__Assembler) AssignNull() error {
 	case schema.Maybe_Absent:
 		return mixins.MapAssembler{TypeName: "dagjose.EncodedRecipient"}.AssignNull()
 	case schema.Maybe_Value, schema.Maybe_Null:
-		panic("invalid state: cannot assign into assembler that's already finished")
+		return assemblerStateError("invalid state: cannot assign into assembler that's already finished")
 	case midvalue:
-		panic("invalid state: cannot assign null into an assembler that's already begun working on recursive structures!")
+		return assemblerStateError("invalid state: cannot assign null into an assembler that's already begun working on recursive structures!")
 	}
-	panic("unreachable")
+	return assemblerStateError("unreachable")
 }
 func (_EncodedRecipient__Assembler) AssignBool(bool) error {
 	return mixins.MapAssembler{TypeName: "dagjose.EncodedRecipient"}.AssignBool(false)
@@ -9624,9 +9954,9 @@ func (na *_EncodedRecipient__Assembler) AssignNode(v datamodel.Node) error {
 	if v2, ok := v.(*_EncodedRecipient); ok {
 		switch *na.m {
 		case schema.Maybe_Value, schema.Maybe_Null:
-			panic("invalid state: cannot assign into assembler that's already finished")
+			return assemblerStateError("invalid state: cannot assign into assembler that's already finished")
 		case midvalue:
-			panic("invalid state: cannot assign null into an assembler that's already begun working on recursive structures!")
+			return assemblerStateError("invalid state: cannot assign null into an assembler that's already begun working on recursive structures!")
 		}
 		if na.w == nil {
 			na.w = v2
@@ -9686,15 +10016,15 @@ func (ma *_EncodedRecipient__Assembler) AssembleEntry(k string) (datamodel.NodeA
 	case maState_initial:
 		// carry on
 	case maState_midKey:
-		panic("invalid state: AssembleEntry cannot be called when in the middle of assembling another key")
+		return nil, assemblerStateError("invalid state: AssembleEntry cannot be called when in the middle of assembling another key")
 	case maState_expectValue:
-		panic("invalid state: AssembleEntry cannot be called when expecting start of value assembly")
+		return nil, assemblerStateError("invalid state: AssembleEntry cannot be called when expecting start of value assembly")
 	case maState_midValue:
 		if !ma.valueFinishTidy() {
-			panic("invalid state: AssembleEntry cannot be called when in the middle of assembling a value")
+			return nil, assemblerStateError("invalid state: AssembleEntry cannot be called when in the middle of assembling a value")
 		} // if tidy success: carry on
 	case maState_finished:
-		panic("invalid state: AssembleEntry cannot be called on an assembler that's already finished")
+		return nil, assemblerStateError("invalid state: AssembleEntry cannot be called on an assembler that's already finished")
 	}
 	switch k {
 	case "header":
@@ -9770,15 +10100,15 @@ func (ma *_EncodedRecipient__Assembler) Finish() error {
 	case maState_initial:
 		// carry on
 	case maState_midKey:
-		panic("invalid state: Finish cannot be called when in the middle of assembling a key")
+		return assemblerStateError("invalid state: Finish cannot be called when in the middle of assembling a key")
 	case maState_expectValue:
-		panic("invalid state: Finish cannot be called when expecting start of value assembly")
+		return assemblerStateError("invalid state: Finish cannot be called when expecting start of value assembly")
 	case maState_midValue:
 		if !ma.valueFinishTidy() {
-			panic("invalid state: Finish cannot be called when in the middle of assembling a value")
+			return assemblerStateError("invalid state: Finish cannot be called when in the middle of assembling a value")
 		} // if tidy success: carry on
 	case maState_finished:
-		panic("invalid state: Finish cannot be called on an assembler that's already finished")
+		return assemblerStateError("invalid state: Finish cannot be called on an assembler that's already finished")
 	}
 	if ma.s&fieldBits__EncodedRecipient_sufficient != fieldBits__EncodedRecipient_sufficient {
 		err := schema.ErrMissingRequiredField{Missing: make([]string, 0)}
@@ -9817,7 +10147,7 @@ func (_EncodedRecipient__KeyAssembler) AssignFloat(float64) error {
 }
 func (ka *_EncodedRecipient__KeyAssembler) AssignString(k string) error {
 	if ka.state != maState_midKey {
-		panic("misuse: KeyAssembler held beyond its valid lifetime")
+		return assemblerStateError("misuse: KeyAssembler held beyond its valid lifetime")
 	}
 	switch k {
 	case "header":
@@ -10040,9 +10370,9 @@ func (na *_EncodedRecipient__ReprAssembler) reset() {
 func (na *_EncodedRecipient__ReprAssembler) BeginMap(int64) (datamodel.MapAssembler, error) {
 	switch *na.m {
 	case schema.Maybe_Value, schema.Maybe_Null:
-		panic("invalid state: cannot assign into assembler that's already finished")
+		return nil, assemblerStateError("invalid state: cannot assign into assembler that's already finished")
 	case midvalue:
-		panic("invalid state: it makes no sense to 'begin' twice on the same assembler!")
+		return nil, assemblerStateError("invalid state: it makes no sense to 'begin' twice on the same assembler!")
 	}
 	*na.m = midvalue
 	if na.w == nil {
@@ -10061,11 +10391,11 @@ func (na *_EncodedRecipient__ReprAssembler) AssignNull() error {
 	case schema.Maybe_Absent:
 		return mixins.MapAssembler{TypeName: "dagjose.EncodedRecipient.Repr.Repr"}.AssignNull()
 	case schema.Maybe_Value, schema.Maybe_Null:
-		panic("invalid state: cannot assign into assembler that's already finished")
+		return assemblerStateError("invalid state: cannot assign into assembler that's already finished")
 	case midvalue:
-		panic("invalid state: cannot assign null into an assembler that's already begun working on recursive structures!")
+		return assemblerStateError("invalid state: cannot assign null into an assembler that's already begun working on recursive structures!")
 	}
-	panic("unreachable")
+	return assemblerStateError("unreachable")
 }
 func (_EncodedRecipient__ReprAssembler) AssignBool(bool) error {
 	return mixins.MapAssembler{TypeName: "dagjose.EncodedRecipient.Repr"}.AssignBool(false)
@@ -10092,9 +10422,9 @@ func (na *_EncodedRecipient__ReprAssembler) AssignNode(v datamodel.Node) error {
 	if v2, ok := v.(*_EncodedRecipient); ok {
 		switch *na.m {
 		case schema.Maybe_Value, schema.Maybe_Null:
-			panic("invalid state: cannot assign into assembler that's already finished")
+			return assemblerStateError("invalid state: cannot assign into assembler that's already finished")
 		case midvalue:
-			panic("invalid state: cannot assign null into an assembler that's already begun working on recursive structures!")
+			return assemblerStateError("invalid state: cannot assign null into an assembler that's already begun working on recursive structures!")
 		}
 		if na.w == nil {
 			na.w = v2
@@ -10154,15 +10484,15 @@ func (ma *_EncodedRecipient__ReprAssembler) AssembleEntry(k string) (datamodel.N
 	case maState_initial:
 		// carry on
 	case maState_midKey:
-		panic("invalid state: AssembleEntry cannot be called when in the middle of assembling another key")
+		return nil, assemblerStateError("invalid state: AssembleEntry cannot be called when in the middle of assembling another key")
 	case maState_expectValue:
-		panic("invalid state: AssembleEntry cannot be called when expecting start of value assembly")
+		return nil, assemblerStateError("invalid state: AssembleEntry cannot be called when expecting start of value assembly")
 	case maState_midValue:
 		if !ma.valueFinishTidy() {
-			panic("invalid state: AssembleEntry cannot be called when in the middle of assembling a value")
+			return nil, assemblerStateError("invalid state: AssembleEntry cannot be called when in the middle of assembling a value")
 		} // if tidy success: carry on
 	case maState_finished:
-		panic("invalid state: AssembleEntry cannot be called on an assembler that's already finished")
+		return nil, assemblerStateError("invalid state: AssembleEntry cannot be called on an assembler that's already finished")
 	}
 	switch k {
 	case "header":
@@ -10243,15 +10573,15 @@ func (ma *_EncodedRecipient__ReprAssembler) Finish() error {
 	case maState_initial:
 		// carry on
 	case maState_midKey:
-		panic("invalid state: Finish cannot be called when in the middle of assembling a key")
+		return assemblerStateError("invalid state: Finish cannot be called when in the middle of assembling a key")
 	case maState_expectValue:
-		panic("invalid state: Finish cannot be called when expecting start of value assembly")
+		return assemblerStateError("invalid state: Finish cannot be called when expecting start of value assembly")
 	case maState_midValue:
 		if !ma.valueFinishTidy() {
-			panic("invalid state: Finish cannot be called when in the middle of assembling a value")
+			return assemblerStateError("invalid state: Finish cannot be called when in the middle of assembling a value")
 		} // if tidy success: carry on
 	case maState_finished:
-		panic("invalid state: Finish cannot be called on an assembler that's already finished")
+		return assemblerStateError("invalid state: Finish cannot be called on an assembler that's already finished")
 	}
 	if ma.s&fieldBits__EncodedRecipient_sufficient != fieldBits__EncodedRecipient_sufficient {
 		err := schema.ErrMissingRequiredField{Missing: make([]string, 0)}
@@ -10290,7 +10620,7 @@ func (_EncodedRecipient__ReprKeyAssembler) AssignFloat(float64) error {
 }
 func (ka *_EncodedRecipient__ReprKeyAssembler) AssignString(k string) error {
 	if ka.state != maState_midKey {
-		panic("misuse: KeyAssembler held beyond its valid lifetime")
+		return assemblerStateError("misuse: KeyAssembler held beyond its valid lifetime")
 	}
 	switch k {
 	case "header":
@@ -10535,9 +10865,9 @@ func (_EncodedRecipients__Assembler) BeginMap(sizeHint int64) (datamodel.MapAsse
 func (na *_EncodedRecipients__Assembler) BeginList(sizeHint int64) (datamodel.ListAssembler, error) {
 	switch *na.m {
 	case schema.Maybe_Value, schema.Maybe_Null:
-		panic("invalid state: cannot assign into assembler that's already finished")
+		return nil, assemblerStateError("invalid state: cannot assign into assembler that's already finished")
 	case midvalue:
-		panic("invalid state: it makes no sense to 'begin' twice on the same assembler!")
+		return nil, assemblerStateError("invalid state: it makes no sense to 'begin' twice on the same assembler!")
 	}
 	*na.m = midvalue
 	if sizeHint < 0 {
@@ -10556,11 +10886,11 @@ func (na *_EncodedRecipients__Assembler) AssignNull() error {
 	case schema.Maybe_Absent:
 		return mixins.ListAssembler{TypeName: "dagjose.EncodedRecipients"}.AssignNull()
 	case schema.Maybe_Value, schema.Maybe_Null:
-		panic("invalid state: cannot assign into assembler that's already finished")
+		return assemblerStateError("invalid state: cannot assign into assembler that's already finished")
 	case midvalue:
-		panic("invalid state: cannot assign null into an assembler that's already begun working on recursive structures!")
+		return assemblerStateError("invalid state: cannot assign null into an assembler that's already begun working on recursive structures!")
 	}
-	panic("unreachable")
+	return assemblerStateError("unreachable")
 }
 func (_EncodedRecipients__Assembler) AssignBool(bool) error {
 	return mixins.ListAssembler{TypeName: "dagjose.EncodedRecipients"}.AssignBool(false)
@@ -10587,9 +10917,9 @@ func (na *_EncodedRecipients__Assembler) AssignNode(v datamodel.Node) error {
 	if v2, ok := v.(*_EncodedRecipients); ok {
 		switch *na.m {
 		case schema.Maybe_Value, schema.Maybe_Null:
-			panic("invalid state: cannot assign into assembler that's already finished")
+			return assemblerStateError("invalid state: cannot assign into assembler that's already finished")
 		case midvalue:
-			panic("invalid state: cannot assign null into an assembler that's already begun working on recursive structures!")
+			return assemblerStateError("invalid state: cannot assign null into an assembler that's already begun working on recursive structures!")
 		}
 		*na.w = *v2
 		*na.m = schema.Maybe_Value
@@ -10649,10 +10979,10 @@ func (la *_EncodedRecipients__Assembler) Finish() error {
 		// carry on
 	case laState_midValue:
 		if !la.valueFinishTidy() {
-			panic("invalid state: Finish cannot be called when in the middle of assembling a value")
+			return assemblerStateError("invalid state: Finish cannot be called when in the middle of assembling a value")
 		} // if tidy success: carry on
 	case laState_finished:
-		panic("invalid state: Finish cannot be called on an assembler that's already finished")
+		return assemblerStateError("invalid state: Finish cannot be called on an assembler that's already finished")
 	}
 	la.state = laState_finished
 	*la.m = schema.Maybe_Value
@@ -10793,9 +11123,9 @@ func (_EncodedRecipients__ReprAssembler) BeginMap(sizeHint int64) (datamodel.Map
 func (na *_EncodedRecipients__ReprAssembler) BeginList(sizeHint int64) (datamodel.ListAssembler, error) {
 	switch *na.m {
 	case schema.Maybe_Value, schema.Maybe_Null:
-		panic("invalid state: cannot assign into assembler that's already finished")
+		return nil, assemblerStateError("invalid state: cannot assign into assembler that's already finished")
 	case midvalue:
-		panic("invalid state: it makes no sense to 'begin' twice on the same assembler!")
+		return nil, assemblerStateError("invalid state: it makes no sense to 'begin' twice on the same assembler!")
 	}
 	*na.m = midvalue
 	if sizeHint < 0 {
@@ -10814,11 +11144,11 @@ func (na *_EncodedRecipients__ReprAssembler) AssignNull() error {
 	case schema.Maybe_Absent:
 		return mixins.ListAssembler{TypeName: "dagjose.EncodedRecipients.Repr.Repr"}.AssignNull()
 	case schema.Maybe_Value, schema.Maybe_Null:
-		panic("invalid state: cannot assign into assembler that's already finished")
+		return assemblerStateError("invalid state: cannot assign into assembler that's already finished")
 	case midvalue:
-		panic("invalid state: cannot assign null into an assembler that's already begun working on recursive structures!")
+		return assemblerStateError("invalid state: cannot assign null into an assembler that's already begun working on recursive structures!")
 	}
-	panic("unreachable")
+	return assemblerStateError("unreachable")
 }
 func (_EncodedRecipients__ReprAssembler) AssignBool(bool) error {
 	return mixins.ListAssembler{TypeName: "dagjose.EncodedRecipients.Repr"}.AssignBool(false)
@@ -10845,9 +11175,9 @@ func (na *_EncodedRecipients__ReprAssembler) AssignNode(v datamodel.Node) error
 	if v2, ok := v.(*_EncodedRecipients); ok {
 		switch *na.m {
 		case schema.Maybe_Value, schema.Maybe_Null:
-			panic("invalid state: cannot assign into assembler that's already finished")
+			return assemblerStateError("invalid state: cannot assign into assembler that's already finished")
 		case midvalue:
-			panic("invalid state: cannot assign null into an assembler that's already begun working on recursive structures!")
+			return assemblerStateError("invalid state: cannot assign null into an assembler that's already begun working on recursive structures!")
 		}
 		*na.w = *v2
 		*na.m = schema.Maybe_Value
@@ -10907,10 +11237,10 @@ func (la *_EncodedRecipients__ReprAssembler) Finish() error {
 		// carry on
 	case laState_midValue:
 		if !la.valueFinishTidy() {
-			panic("invalid state: Finish cannot be called when in the middle of assembling a value")
+			return assemblerStateError("invalid state: Finish cannot be called when in the middle of assembling a value")
 		} // if tidy success: carry on
 	case laState_finished:
-		panic("invalid state: Finish cannot be called on an assembler that's already finished")
+		return assemblerStateError("invalid state: Finish cannot be called on an assembler that's already finished")
 	}
 	la.state = laState_finished
 	*la.m = schema.Maybe_Value
@@ -11136,9 +11466,9 @@ var (
 func (na *_EncodedSignature__Assembler) BeginMap(int64) (datamodel.MapAssembler, error) {
 	switch *na.m {
 	case schema.Maybe_Value, schema.Maybe_Null:
-		panic("invalid state: cannot assign into assembler that's already finished")
+		return nil, assemblerStateError("invalid state: cannot assign into assembler that's already finished")
 	case midvalue:
-		panic("invalid state: it makes no sense to 'begin' twice on the same assembler!")
+		return nil, assemblerStateError("invalid state: it makes no sense to 'begin' twice on the same assembler!")
 	}
 	*na.m = midvalue
 	if na.w == nil {
@@ -11157,11 +11487,11 @@ func (na *_EncodedSignature__Assembler) AssignNull() error {
 	case schema.Maybe_Absent:
 		return mixins.MapAssembler{TypeName: "dagjose.EncodedSignature"}.AssignNull()
 	case schema.Maybe_Value, schema.Maybe_Null:
-		panic("invalid state: cannot assign into assembler that's already finished")
+		return assemblerStateError("invalid state: cannot assign into assembler that's already finished")
 	case midvalue:
-		panic("invalid state: cannot assign null into an assembler that's already begun working on recursive structures!")
+		return assemblerStateError("invalid state: cannot assign null into an assembler that's already begun working on recursive structures!")
 	}
-	panic("unreachable")
+	return assemblerStateError("unreachable")
 }
 func (_EncodedSignature__Assembler) AssignBool(bool) error {
 	return mixins.MapAssembler{TypeName: "dagjose.EncodedSignature"}.AssignBool(false)
@@ -11188,9 +11518,9 @@ func (na *_EncodedSignature__Assembler) AssignNode(v datamodel.Node) error {
 	if v2, ok := v.(*_EncodedSignature); ok {
 		switch *na.m {
 		case schema.Maybe_Value, schema.Maybe_Null:
-			panic("invalid state: cannot assign into assembler that's already finished")
+			return assemblerStateError("invalid state: cannot assign into assembler that's already finished")
 		case midvalue:
-			panic("invalid state: cannot assign null into an assembler that's already begun working on recursive structures!")
+			return assemblerStateError("invalid state: cannot assign null into an assembler that's already begun working on recursive structures!")
 		}
 		if na.w == nil {
 			na.w = v2
@@ -11260,15 +11590,15 @@ func (ma *_EncodedSignature__Assembler) AssembleEntry(k string) (datamodel.NodeA
 	case maState_initial:
 		// carry on
 	case maState_midKey:
-		panic("invalid state: AssembleEntry cannot be called when in the middle of assembling another key")
+		return nil, assemblerStateError("invalid state: AssembleEntry cannot be called when in the middle of assembling another key")
 	case maState_expectValue:
-		panic("invalid state: AssembleEntry cannot be called when expecting start of value assembly")
+		return nil, assemblerStateError("invalid state: AssembleEntry cannot be called when expecting start of value assembly")
 	case maState_midValue:
 		if !ma.valueFinishTidy() {
-			panic("invalid state: AssembleEntry cannot be called when in the middle of assembling a value")
+			return nil, assemblerStateError("invalid state: AssembleEntry cannot be called when in the middle of assembling a value")
 		} // if tidy success: carry on
 	case maState_finished:
-		panic("invalid state: AssembleEntry cannot be called on an assembler that's already finished")
+		return nil, assemblerStateError("invalid state: AssembleEntry cannot be called on an assembler that's already finished")
 	}
 	switch k {
 	case "header":
@@ -11358,15 +11688,15 @@ func (ma *_EncodedSignature__Assembler) Finish() error {
 	case maState_initial:
 		// carry on
 	case maState_midKey:
-		panic("invalid state: Finish cannot be called when in the middle of assembling a key")
+		return assemblerStateError("invalid state: Finish cannot be called when in the middle of assembling a key")
 	case maState_expectValue:
-		panic("invalid state: Finish cannot be called when expecting start of value assembly")
+		return assemblerStateError("invalid state: Finish cannot be called when expecting start of value assembly")
 	case maState_midValue:
 		if !ma.valueFinishTidy() {
-			panic("invalid state: Finish cannot be called when in the middle of assembling a value")
+			return assemblerStateError("invalid state: Finish cannot be called when in the middle of assembling a value")
 		} // if tidy success: carry on
 	case maState_finished:
-		panic("invalid state: Finish cannot be called on an assembler that's already finished")
+		return assemblerStateError("invalid state: Finish cannot be called on an assembler that's already finished")
 	}
 	if ma.s&fieldBits__EncodedSignature_sufficient != fieldBits__EncodedSignature_sufficient {
 		err := schema.ErrMissingRequiredField{Missing: make([]string, 0)}
@@ -11408,7 +11738,7 @@ func (_EncodedSignature__KeyAssembler) AssignFloat(float64) error {
 }
 func (ka *_EncodedSignature__KeyAssembler) AssignString(k string) error {
 	if ka.state != maState_midKey {
-		panic("misuse: KeyAssembler held beyond its valid lifetime")
+		return assemblerStateError("misuse: KeyAssembler held beyond its valid lifetime")
 	}
 	switch k {
 	case "header":
@@ -11634,9 +11964,9 @@ func (na *_EncodedSignature__ReprAssembler) reset() {
 func (na *_EncodedSignature__ReprAssembler) BeginMap(int64) (datamodel.MapAssembler, error) {
 	switch *na.m {
 	case schema.Maybe_Value, schema.Maybe_Null:
-		panic("invalid state: cannot assign into assembler that's already finished")
+		return nil, assemblerStateError("invalid state: cannot assign into assembler that's already finished")
 	case midvalue:
-		panic("invalid state: it makes no sense to 'begin' twice on the same assembler!")
+		return nil, assemblerStateError("invalid state: it makes no sense to 'begin' twice on the same assembler!")
 	}
 	*na.m = midvalue
 	if na.w == nil {
@@ -11655,11 +11985,11 @@ func (na *_EncodedSignature__ReprAssembler) AssignNull() error {
 	case schema.Maybe_Absent:
 		return mixins.MapAssembler{TypeName: "dagjose.EncodedSignature.Repr.Repr"}.AssignNull()
 	case schema.Maybe_Value, schema.Maybe_Null:
-		panic("invalid state: cannot assign into assembler that's already finished")
+		return assemblerStateError("invalid state: cannot assign into assembler that's already finished")
 	case midvalue:
-		panic("invalid state: cannot assign null into an assembler that's already begun working on recursive structures!")
+		return assemblerStateError("invalid state: cannot assign null into an assembler that's already begun working on recursive structures!")
 	}
-	panic("unreachable")
+	return assemblerStateError("unreachable")
 }
 func (_EncodedSignature__ReprAssembler) AssignBool(bool) error {
 	return mixins.MapAssembler{TypeName: "dagjose.EncodedSignature.Repr"}.AssignBool(false)
@@ -11686,9 +12016,9 @@ func (na *_EncodedSignature__ReprAssembler) AssignNode(v datamodel.Node) error {
 	if v2, ok := v.(*_EncodedSignature); ok {
 		switch *na.m {
 		case schema.Maybe_Value, schema.Maybe_Null:
-			panic("invalid state: cannot assign into assembler that's already finished")
+			return assemblerStateError("invalid state: cannot assign into assembler that's already finished")
 		case midvalue:
-			panic("invalid state: cannot assign null into an assembler that's already begun working on recursive structures!")
+			return assemblerStateError("invalid state: cannot assign null into an assembler that's already begun working on recursive structures!")
 		}
 		if na.w == nil {
 			na.w = v2
@@ -11757,15 +12087,15 @@ func (ma *_EncodedSignature__ReprAssembler) AssembleEntry(k string) (datamodel.N
 	case maState_initial:
 		// carry on
 	case maState_midKey:
-		panic("invalid state: AssembleEntry cannot be called when in the middle of assembling another key")
+		return nil, assemblerStateError("invalid state: AssembleEntry cannot be called when in the middle of assembling another key")
 	case maState_expectValue:
-		panic("invalid state: AssembleEntry cannot be called when expecting start of value assembly")
+		return nil, assemblerStateError("invalid state: AssembleEntry cannot be called when expecting start of value assembly")
 	case maState_midValue:
 		if !ma.valueFinishTidy() {
-			panic("invalid state: AssembleEntry cannot be called when in the middle of assembling a value")
+			return nil, assemblerStateError("invalid state: AssembleEntry cannot be called when in the middle of assembling a value")
 		} // if tidy success: carry on
 	case maState_finished:
-		panic("invalid state: AssembleEntry cannot be called on an assembler that's already finished")
+		return nil, assemblerStateError("invalid state: AssembleEntry cannot be called on an assembler that's already finished")
 	}
 	switch k {
 	case "header":
@@ -11860,15 +12190,15 @@ func (ma *_EncodedSignature__ReprAssembler) Finish() error {
 	case maState_initial:
 		// carry on
 	case maState_midKey:
-		panic("invalid state: Finish cannot be called when in the middle of assembling a key")
+		return assemblerStateError("invalid state: Finish cannot be called when in the middle of assembling a key")
 	case maState_expectValue:
-		panic("invalid state: Finish cannot be called when expecting start of value assembly")
+		return assemblerStateError("invalid state: Finish cannot be called when expecting start of value assembly")
 	case maState_midValue:
 		if !ma.valueFinishTidy() {
-			panic("invalid state: Finish cannot be called when in the middle of assembling a value")
+			return assemblerStateError("invalid state: Finish cannot be called when in the middle of assembling a value")
 		} // if tidy success: carry on
 	case maState_finished:
-		panic("invalid state: Finish cannot be called on an assembler that's already finished")
+		return assemblerStateError("invalid state: Finish cannot be called on an assembler that's already finished")
 	}
 	if ma.s&fieldBits__EncodedSignature_sufficient != fieldBits__EncodedSignature_sufficient {
 		err := schema.ErrMissingRequiredField{Missing: make([]string, 0)}
@@ -11910,7 +12240,7 @@ func (_EncodedSignature__ReprKeyAssembler) AssignFloat(float64) error {
 }
 func (ka *_EncodedSignature__ReprKeyAssembler) AssignString(k string) error {
 	if ka.state != maState_midKey {
-		panic("misuse: KeyAssembler held beyond its valid lifetime")
+		return assemblerStateError("misuse: KeyAssembler held beyond its valid lifetime")
 	}
 	switch k {
 	case "header":
@@ -12163,9 +12493,9 @@ func (_EncodedSignatures__Assembler) BeginMap(sizeHint int64) (datamodel.MapAsse
 func (na *_EncodedSignatures__Assembler) BeginList(sizeHint int64) (datamodel.ListAssembler, error) {
 	switch *na.m {
 	case schema.Maybe_Value, schema.Maybe_Null:
-		panic("invalid state: cannot assign into assembler that's already finished")
+		return nil, assemblerStateError("invalid state: cannot assign into assembler that's already finished")
 	case midvalue:
-		panic("invalid state: it makes no sense to 'begin' twice on the same assembler!")
+		return nil, assemblerStateError("invalid state: it makes no sense to 'begin' twice on the same assembler!")
 	}
 	*na.m = midvalue
 	if sizeHint < 0 {
@@ -12184,11 +12514,11 @@ func (na *_EncodedSignatures__Assembler) AssignNull() error {
 	case schema.Maybe_Absent:
 		return mixins.ListAssembler{TypeName: "dagjose.EncodedSignatures"}.AssignNull()
 	case schema.Maybe_Value, schema.Maybe_Null:
-		panic("invalid state: cannot assign into assembler that's already finished")
+		return assemblerStateError("invalid state: cannot assign into assembler that's already finished")
 	case midvalue:
-		panic("invalid state: cannot assign null into an assembler that's already begun working on recursive structures!")
+		return assemblerStateError("invalid state: cannot assign null into an assembler that's already begun working on recursive structures!")
 	}
-	panic("unreachable")
+	return assemblerStateError("unreachable")
 }
 func (_EncodedSignatures__Assembler) AssignBool(bool) error {
 	return mixins.ListAssembler{TypeName: "dagjose.EncodedSignatures"}.AssignBool(false)
@@ -12215,9 +12545,9 @@ func (na *_EncodedSignatures__Assembler) AssignNode(v datamodel.Node) error {
 	if v2, ok := v.(*_EncodedSignatures); ok {
 		switch *na.m {
 		case schema.Maybe_Value, schema.Maybe_Null:
-			panic("invalid state: cannot assign into assembler that's already finished")
+			return assemblerStateError("invalid state: cannot assign into assembler that's already finished")
 		case midvalue:
-			panic("invalid state: cannot assign null into an assembler that's already begun working on recursive structures!")
+			return assemblerStateError("invalid state: cannot assign null into an assembler that's already begun working on recursive structures!")
 		}
 		*na.w = *v2
 		*na.m = schema.Maybe_Value
@@ -12277,10 +12607,10 @@ func (la *_EncodedSignatures__Assembler) Finish() error {
 		// carry on
 	case laState_midValue:
 		if !la.valueFinishTidy() {
-			panic("invalid state: Finish cannot be called when in the middle of assembling a value")
+			return assemblerStateError("invalid state: Finish cannot be called when in the middle of assembling a value")
 		} // if tidy success: carry on
 	case laState_finished:
-		panic("invalid state: Finish cannot be called on an assembler that's already finished")
+		return assemblerStateError("invalid state: Finish cannot be called on an assembler that's already finished")
 	}
 	la.state = laState_finished
 	*la.m = schema.Maybe_Value
@@ -12421,9 +12751,9 @@ func (_EncodedSignatures__ReprAssembler) BeginMap(sizeHint int64) (datamodel.Map
 func (na *_EncodedSignatures__ReprAssembler) BeginList(sizeHint int64) (datamodel.ListAssembler, error) {
 	switch *na.m {
 	case schema.Maybe_Value, schema.Maybe_Null:
-		panic("invalid state: cannot assign into assembler that's already finished")
+		return nil, assemblerStateError("invalid state: cannot assign into assembler that's already finished")
 	case midvalue:
-		panic("invalid state: it makes no sense to 'begin' twice on the same assembler!")
+		return nil, assemblerStateError("invalid state: it makes no sense to 'begin' twice on the same assembler!")
 	}
 	*na.m = midvalue
 	if sizeHint < 0 {
@@ -12442,11 +12772,11 @@ func (na *_EncodedSignatures__ReprAssembler) AssignNull() error {
 	case schema.Maybe_Absent:
 		return mixins.ListAssembler{TypeName: "dagjose.EncodedSignatures.Repr.Repr"}.AssignNull()
 	case schema.Maybe_Value, schema.Maybe_Null:
-		panic("invalid state: cannot assign into assembler that's already finished")
+		return assemblerStateError("invalid state: cannot assign into assembler that's already finished")
 	case midvalue:
-		panic("invalid state: cannot assign null into an assembler that's already begun working on recursive structures!")
+		return assemblerStateError("invalid state: cannot assign null into an assembler that's already begun working on recursive structures!")
 	}
-	panic("unreachable")
+	return assemblerStateError("unreachable")
 }
 func (_EncodedSignatures__ReprAssembler) AssignBool(bool) error {
 	return mixins.ListAssembler{TypeName: "dagjose.EncodedSignatures.Repr"}.AssignBool(false)
@@ -12473,9 +12803,9 @@ func (na *_EncodedSignatures__ReprAssembler) AssignNode(v datamodel.Node) error
 	if v2, ok := v.(*_EncodedSignatures); ok {
 		switch *na.m {
 		case schema.Maybe_Value, schema.Maybe_Null:
-			panic("invalid state: cannot assign into assembler that's already finished")
+			return assemblerStateError("invalid state: cannot assign into assembler that's already finished")
 		case midvalue:
-			panic("invalid state: cannot assign null into an assembler that's already begun working on recursive structures!")
+			return assemblerStateError("invalid state: cannot assign null into an assembler that's already begun working on recursive structures!")
 		}
 		*na.w = *v2
 		*na.m = schema.Maybe_Value
@@ -12535,10 +12865,10 @@ func (la *_EncodedSignatures__ReprAssembler) Finish() error {
 		// carry on
 	case laState_midValue:
 		if !la.valueFinishTidy() {
-			panic("invalid state: Finish cannot be called when in the middle of assembling a value")
+			return assemblerStateError("invalid state: Finish cannot be called when in the middle of assembling a value")
 		} // if tidy success: carry on
 	case laState_finished:
-		panic("invalid state: Finish cannot be called on an assembler that's already finished")
+		return assemblerStateError("invalid state: Finish cannot be called on an assembler that's already finished")
 	}
 	la.state = laState_finished
 	*la.m = schema.Maybe_Value
@@ -12689,9 +13019,9 @@ func (na *_Float__Assembler) AssignNull() error {
 	case schema.Maybe_Absent:
 		return mixins.FloatAssembler{TypeName: "dagjose.Float"}.AssignNull()
 	case schema.Maybe_Value, schema.Maybe_Null:
-		panic("invalid state: cannot assign into assembler that's already finished")
+		return assemblerStateError("invalid state: cannot assign into assembler that's already finished")
 	}
-	panic("unreachable")
+	return assemblerStateError("unreachable")
 }
 func (_Float__Assembler) AssignBool(bool) error {
 	return mixins.FloatAssembler{TypeName: "dagjose.Float"}.AssignBool(false)
@@ -12702,7 +13032,7 @@ func (_Float__Assembler) AssignInt(int64) error {
 func (na *_Float__Assembler) AssignFloat(v float64) error {
 	switch *na.m {
 	case schema.Maybe_Value, schema.Maybe_Null:
-		panic("invalid state: cannot assign into assembler that's already finished")
+		return assemblerStateError("invalid state: cannot assign into assembler that's already finished")
 	}
 	na.w.x = v
 	*na.m = schema.Maybe_Value
@@ -12724,7 +13054,7 @@ func (na *_Float__Assembler) AssignNode(v datamodel.Node) error {
 	if v2, ok := v.(*_Float); ok {
 		switch *na.m {
 		case schema.Maybe_Value, schema.Maybe_Null:
-			panic("invalid state: cannot assign into assembler that's already finished")
+			return assemblerStateError("invalid state: cannot assign into assembler that's already finished")
 		}
 		*na.w = *v2
 		*na.m = schema.Maybe_Value
@@ -12894,9 +13224,9 @@ func (na *_Int__Assembler) AssignNull() error {
 	case schema.Maybe_Absent:
 		return mixins.IntAssembler{TypeName: "dagjose.Int"}.AssignNull()
 	case schema.Maybe_Value, schema.Maybe_Null:
-		panic("invalid state: cannot assign into assembler that's already finished")
+		return assemblerStateError("invalid state: cannot assign into assembler that's already finished")
 	}
-	panic("unreachable")
+	return assemblerStateError("unreachable")
 }
 func (_Int__Assembler) AssignBool(bool) error {
 	return mixins.IntAssembler{TypeName: "dagjose.Int"}.AssignBool(false)
@@ -12904,7 +13234,7 @@ func (_Int__Assembler) AssignBool(bool) error {
 func (na *_Int__Assembler) AssignInt(v int64) error {
 	switch *na.m {
 	case schema.Maybe_Value, schema.Maybe_Null:
-		panic("invalid state: cannot assign into assembler that's already finished")
+		return assemblerStateError("invalid state: cannot assign into assembler that's already finished")
 	}
 	na.w.x = v
 	*na.m = schema.Maybe_Value
@@ -12929,7 +13259,7 @@ func (na *_Int__Assembler) AssignNode(v datamodel.Node) error {
 	if v2, ok := v.(*_Int); ok {
 		switch *na.m {
 		case schema.Maybe_Value, schema.Maybe_Null:
-			panic("invalid state: cannot assign into assembler that's already finished")
+			return assemblerStateError("invalid state: cannot assign into assembler that's already finished")
 		}
 		*na.w = *v2
 		*na.m = schema.Maybe_Value
@@ -13099,9 +13429,9 @@ func (na *_Link__Assembler) AssignNull() error {
 	case schema.Maybe_Absent:
 		return mixins.LinkAssembler{TypeName: "dagjose.Link"}.AssignNull()
 	case schema.Maybe_Value, schema.Maybe_Null:
-		panic("invalid state: cannot assign into assembler that's already finished")
+		return assemblerStateError("invalid state: cannot assign into assembler that's already finished")
 	}
-	panic("unreachable")
+	return assemblerStateError("unreachable")
 }
 func (_Link__Assembler) AssignBool(bool) error {
 	return mixins.LinkAssembler{TypeName: "dagjose.Link"}.AssignBool(false)
@@ -13121,7 +13451,7 @@ func (_Link__Assembler) AssignBytes([]byte) error {
 func (na *_Link__Assembler) AssignLink(v datamodel.Link) error {
 	switch *na.m {
 	case schema.Maybe_Value, schema.Maybe_Null:
-		panic("invalid state: cannot assign into assembler that's already finished")
+		return assemblerStateError("invalid state: cannot assign into assembler that's already finished")
 	}
 	na.w.x = v
 	*na.m = schema.Maybe_Value
@@ -13134,7 +13464,7 @@ func (na *_Link__Assembler) AssignNode(v datamodel.Node) error {
 	if v2, ok := v.(*_Link); ok {
 		switch *na.m {
 		case schema.Maybe_Value, schema.Maybe_Null:
-			panic("invalid state: cannot assign into assembler that's already finished")
+			return assemblerStateError("invalid state: cannot assign into assembler that's already finished")
 		}
 		*na.w = *v2
 		*na.m = schema.Maybe_Value
@@ -13168,17 +13498,17 @@ func (n *_List) Lookup(idx int64) Any {
 		return nil
 	}
 	v := &n.x[idx]
-	return v
+	if v.m == schema.Maybe_Null {
+		return nil
+	}
+	return v.v
 }
 func (n *_List) LookupMaybe(idx int64) MaybeAny {
 	if n.Length() <= idx {
 		return nil
 	}
 	v := &n.x[idx]
-	return &_Any__Maybe{
-		m: schema.Maybe_Value,
-		v: v,
-	}
+	return v
 }
 
 var _List__valueAbsent = _Any__Maybe{m: schema.Maybe_Absent}
@@ -13192,7 +13522,7 @@ type List__Itr struct {
 	idx int
 }
 
-func (itr *List__Itr) Next() (idx int64, v Any) {
+func (itr *List__Itr) Next() (idx int64, v MaybeAny) {
 	if itr.idx >= len(itr.n.x) {
 		return -1, nil
 	}
@@ -13260,7 +13590,10 @@ func (n List) LookupByIndex(idx int64) (datamodel.Node, error) {
 		return nil, datamodel.ErrNotExists{Segment: datamodel.PathSegmentOfInt(idx)}
 	}
 	v := &n.x[idx]
-	return v, nil
+	if v.m == schema.Maybe_Null {
+		return datamodel.Null, nil
+	}
+	return v.v, nil
 }
 func (n List) LookupBySegment(seg datamodel.PathSegment) (datamodel.Node, error) {
 	i, err := seg.Index()
@@ -13287,7 +13620,12 @@ func (itr *_List__ListItr) Next() (idx int64, v datamodel.Node, _ error) {
 	}
 	idx = int64(itr.idx)
 	x := &itr.n.x[itr.idx]
-	v = x
+	switch x.m {
+	case schema.Maybe_Null:
+		v = datamodel.Null
+	case schema.Maybe_Value:
+		v = x.v
+	}
 	itr.idx++
 	return
 }
@@ -13355,7 +13693,6 @@ type _List__Assembler struct {
 	m     *schema.Maybe
 	state laState
 
-	cm schema.Maybe
 	va _Any__Assembler
 }
 
@@ -13369,16 +13706,16 @@ func (_List__Assembler) BeginMap(sizeHint int64) (datamodel.MapAssembler, error)
 func (na *_List__Assembler) BeginList(sizeHint int64) (datamodel.ListAssembler, error) {
 	switch *na.m {
 	case schema.Maybe_Value, schema.Maybe_Null:
-		panic("invalid state: cannot assign into assembler that's already finished")
+		return nil, assemblerStateError("invalid state: cannot assign into assembler that's already finished")
 	case midvalue:
-		panic("invalid state: it makes no sense to 'begin' twice on the same assembler!")
+		return nil, assemblerStateError("invalid state: it makes no sense to 'begin' twice on the same assembler!")
 	}
 	*na.m = midvalue
 	if sizeHint < 0 {
 		sizeHint = 0
 	}
 	if sizeHint > 0 {
-		na.w.x = make([]_Any, 0, sizeHint)
+		na.w.x = make([]_Any__Maybe, 0, sizeHint)
 	}
 	return na, nil
 }
@@ -13390,11 +13727,11 @@ func (na *_List__Assembler) AssignNull() error {
 	case schema.Maybe_Absent:
 		return mixins.ListAssembler{TypeName: "dagjose.List"}.AssignNull()
 	case schema.Maybe_Value, schema.Maybe_Null:
-		panic("invalid state: cannot assign into assembler that's already finished")
+		return assemblerStateError("invalid state: cannot assign into assembler that's already finished")
 	case midvalue:
-		panic("invalid state: cannot assign null into an assembler that's already begun working on recursive structures!")
+		return assemblerStateError("invalid state: cannot assign null into an assembler that's already begun working on recursive structures!")
 	}
-	panic("unreachable")
+	return assemblerStateError("unreachable")
 }
 func (_List__Assembler) AssignBool(bool) error {
 	return mixins.ListAssembler{TypeName: "dagjose.List"}.AssignBool(false)
@@ -13421,9 +13758,9 @@ func (na *_List__Assembler) AssignNode(v datamodel.Node) error {
 	if v2, ok := v.(*_List); ok {
 		switch *na.m {
 		case schema.Maybe_Value, schema.Maybe_Null:
-			panic("invalid state: cannot assign into assembler that's already finished")
+			return assemblerStateError("invalid state: cannot assign into assembler that's already finished")
 		case midvalue:
-			panic("invalid state: cannot assign null into an assembler that's already begun working on recursive structures!")
+			return assemblerStateError("invalid state: cannot assign null into an assembler that's already begun working on recursive structures!")
 		}
 		*na.w = *v2
 		*na.m = schema.Maybe_Value
@@ -13448,10 +13785,13 @@ func (_List__Assembler) Prototype() datamodel.NodePrototype {
 	return _List__Prototype{}
 }
 func (la *_List__Assembler) valueFinishTidy() bool {
-	switch la.cm {
+	row := &la.w.x[len(la.w.x)-1]
+	switch row.m {
 	case schema.Maybe_Value:
+		row.v = la.va.w
 		la.va.w = nil
-		la.cm = schema.Maybe_Absent
+		fallthrough
+	case schema.Maybe_Null:
 		la.state = laState_initial
 		la.va.reset()
 		return true
@@ -13470,11 +13810,11 @@ func (la *_List__Assembler) AssembleValue() datamodel.NodeAssembler {
 	case laState_finished:
 		panic("invalid state: AssembleValue cannot be called on an assembler that's already finished")
 	}
-	la.w.x = append(la.w.x, _Any{})
+	la.w.x = append(la.w.x, _Any__Maybe{})
 	la.state = laState_midValue
 	row := &la.w.x[len(la.w.x)-1]
-	la.va.w = row
-	la.va.m = &la.cm
+	la.va.m = &row.m
+	row.m = allowNull
 	return &la.va
 }
 func (la *_List__Assembler) Finish() error {
@@ -13483,10 +13823,10 @@ func (la *_List__Assembler) Finish() error {
 		// carry on
 	case laState_midValue:
 		if !la.valueFinishTidy() {
-			panic("invalid state: Finish cannot be called when in the middle of assembling a value")
+			return assemblerStateError("invalid state: Finish cannot be called when in the middle of assembling a value")
 		} // if tidy success: carry on
 	case laState_finished:
-		panic("invalid state: Finish cannot be called on an assembler that's already finished")
+		return assemblerStateError("invalid state: Finish cannot be called on an assembler that's already finished")
 	}
 	la.state = laState_finished
 	*la.m = schema.Maybe_Value
@@ -13613,7 +13953,6 @@ type _List__ReprAssembler struct {
 	m     *schema.Maybe
 	state laState
 
-	cm schema.Maybe
 	va _Any__ReprAssembler
 }
 
@@ -13627,16 +13966,16 @@ func (_List__ReprAssembler) BeginMap(sizeHint int64) (datamodel.MapAssembler, er
 func (na *_List__ReprAssembler) BeginList(sizeHint int64) (datamodel.ListAssembler, error) {
 	switch *na.m {
 	case schema.Maybe_Value, schema.Maybe_Null:
-		panic("invalid state: cannot assign into assembler that's already finished")
+		return nil, assemblerStateError("invalid state: cannot assign into assembler that's already finished")
 	case midvalue:
-		panic("invalid state: it makes no sense to 'begin' twice on the same assembler!")
+		return nil, assemblerStateError("invalid state: it makes no sense to 'begin' twice on the same assembler!")
 	}
 	*na.m = midvalue
 	if sizeHint < 0 {
 		sizeHint = 0
 	}
 	if sizeHint > 0 {
-		na.w.x = make([]_Any, 0, sizeHint)
+		na.w.x = make([]_Any__Maybe, 0, sizeHint)
 	}
 	return na, nil
 }
@@ -13648,11 +13987,11 @@ func (na *_List__ReprAssembler) AssignNull() error {
 	case schema.Maybe_Absent:
 		return mixins.ListAssembler{TypeName: "dagjose.List.Repr.Repr"}.AssignNull()
 	case schema.Maybe_Value, schema.Maybe_Null:
-		panic("invalid state: cannot assign into assembler that's already finished")
+		return assemblerStateError("invalid state: cannot assign into assembler that's already finished")
 	case midvalue:
-		panic("invalid state: cannot assign null into an assembler that's already begun working on recursive structures!")
+		return assemblerStateError("invalid state: cannot assign null into an assembler that's already begun working on recursive structures!")
 	}
-	panic("unreachable")
+	return assemblerStateError("unreachable")
 }
 func (_List__ReprAssembler) AssignBool(bool) error {
 	return mixins.ListAssembler{TypeName: "dagjose.List.Repr"}.AssignBool(false)
@@ -13679,9 +14018,9 @@ func (na *_List__ReprAssembler) AssignNode(v datamodel.Node) error {
 	if v2, ok := v.(*_List); ok {
 		switch *na.m {
 		case schema.Maybe_Value, schema.Maybe_Null:
-			panic("invalid state: cannot assign into assembler that's already finished")
+			return assemblerStateError("invalid state: cannot assign into assembler that's already finished")
 		case midvalue:
-			panic("invalid state: cannot assign null into an assembler that's already begun working on recursive structures!")
+			return assemblerStateError("invalid state: cannot assign null into an assembler that's already begun working on recursive structures!")
 		}
 		*na.w = *v2
 		*na.m = schema.Maybe_Value
@@ -13706,10 +14045,13 @@ func (_List__ReprAssembler) Prototype() datamodel.NodePrototype {
 	return _List__ReprPrototype{}
 }
 func (la *_List__ReprAssembler) valueFinishTidy() bool {
-	switch la.cm {
+	row := &la.w.x[len(la.w.x)-1]
+	switch row.m {
 	case schema.Maybe_Value:
+		row.v = la.va.w
 		la.va.w = nil
-		la.cm = schema.Maybe_Absent
+		fallthrough
+	case schema.Maybe_Null:
 		la.state = laState_initial
 		la.va.reset()
 		return true
@@ -13728,11 +14070,11 @@ func (la *_List__ReprAssembler) AssembleValue() datamodel.NodeAssembler {
 	case laState_finished:
 		panic("invalid state: AssembleValue cannot be called on an assembler that's already finished")
 	}
-	la.w.x = append(la.w.x, _Any{})
+	la.w.x = append(la.w.x, _Any__Maybe{})
 	la.state = laState_midValue
 	row := &la.w.x[len(la.w.x)-1]
-	la.va.w = row
-	la.va.m = &la.cm
+	la.va.m = &row.m
+	row.m = allowNull
 	return &la.va
 }
 func (la *_List__ReprAssembler) Finish() error {
@@ -13741,10 +14083,10 @@ func (la *_List__ReprAssembler) Finish() error {
 		// carry on
 	case laState_midValue:
 		if !la.valueFinishTidy() {
-			panic("invalid state: Finish cannot be called when in the middle of assembling a value")
+			return assemblerStateError("invalid state: Finish cannot be called when in the middle of assembling a value")
 		} // if tidy success: carry on
 	case laState_finished:
-		panic("invalid state: Finish cannot be called on an assembler that's already finished")
+		return assemblerStateError("invalid state: Finish cannot be called on an assembler that's already finished")
 	}
 	la.state = laState_finished
 	*la.m = schema.Maybe_Value
@@ -13759,17 +14101,17 @@ func (n *_Map) Lookup(k String) Any {
 	if !exists {
 		return nil
 	}
-	return v
+	if v.m == schema.Maybe_Null {
+		return nil
+	}
+	return v.v
 }
 func (n *_Map) LookupMaybe(k String) MaybeAny {
 	v, exists := n.m[*k]
 	if !exists {
 		return &_Map__valueAbsent
 	}
-	return &_Any__Maybe{
-		m: schema.Maybe_Value,
-		v: v,
-	}
+	return v
 }
 
 var _Map__valueAbsent = _Any__Maybe{m: schema.Maybe_Absent}
@@ -13783,7 +14125,7 @@ type Map__Itr struct {
 	idx int
 }
 
-func (itr *Map__Itr) Next() (k String, v Any) {
+func (itr *Map__Itr) Next() (k String, v MaybeAny) {
 	if itr.idx >= len(itr.n.t) {
 		return nil, nil
 	}
@@ -13846,7 +14188,10 @@ func (n Map) LookupByString(k string) (datamodel.Node, error) {
 	if !exists {
 		return nil, datamodel.ErrNotExists{Segment: datamodel.PathSegmentOfString(k)}
 	}
-	return v, nil
+	if v.m == schema.Maybe_Null {
+		return datamodel.Null, nil
+	}
+	return v.v, nil
 }
 func (n Map) LookupByNode(k datamodel.Node) (datamodel.Node, error) {
 	k2, ok := k.(String)
@@ -13858,7 +14203,10 @@ func (n Map) LookupByNode(k datamodel.Node) (datamodel.Node, error) {
 	if !exists {
 		return nil, datamodel.ErrNotExists{Segment: datamodel.PathSegmentOfString(k2.String())}
 	}
-	return v, nil
+	if v.m == schema.Maybe_Null {
+		return datamodel.Null, nil
+	}
+	return v.v, nil
 }
 func (Map) LookupByIndex(idx int64) (datamodel.Node, error) {
 	return mixins.Map{TypeName: "dagjose.Map"}.LookupByIndex(0)
@@ -13881,7 +14229,12 @@ func (itr *_Map__MapItr) Next() (k datamodel.Node, v datamodel.Node, _ error) {
 	}
 	x := &itr.n.t[itr.idx]
 	k = &x.k
-	v = &x.v
+	switch x.v.m {
+	case schema.Maybe_Null:
+		v = datamodel.Null
+	case schema.Maybe_Value:
+		v = x.v.v
+	}
 	itr.idx++
 	return
 }
@@ -13965,15 +14318,15 @@ func (na *_Map__Assembler) reset() {
 func (na *_Map__Assembler) BeginMap(sizeHint int64) (datamodel.MapAssembler, error) {
 	switch *na.m {
 	case schema.Maybe_Value, schema.Maybe_Null:
-		panic("invalid state: cannot assign into assembler that's already finished")
+		return nil, assemblerStateError("invalid state: cannot assign into assembler that's already finished")
 	case midvalue:
-		panic("invalid state: it makes no sense to 'begin' twice on the same assembler!")
+		return nil, assemblerStateError("invalid state: it makes no sense to 'begin' twice on the same assembler!")
 	}
 	*na.m = midvalue
 	if sizeHint < 0 {
 		sizeHint = 0
 	}
-	na.w.m = make(map[_String]*_Any, sizeHint)
+	na.w.m = make(map[_String]MaybeAny, sizeHint)
 	na.w.t = make([]_Map__entry, 0, sizeHint)
 	return na, nil
 }
@@ -13988,11 +14341,11 @@ func (na *_Map__Assembler) AssignNull() error {
 	case schema.Maybe_Absent:
 		return mixins.MapAssembler{TypeName: "dagjose.Map"}.AssignNull()
 	case schema.Maybe_Value, schema.Maybe_Null:
-		panic("invalid state: cannot assign into assembler that's already finished")
+		return assemblerStateError("invalid state: cannot assign into assembler that's already finished")
 	case midvalue:
-		panic("invalid state: cannot assign null into an assembler that's already begun working on recursive structures!")
+		return assemblerStateError("invalid state: cannot assign null into an assembler that's already begun working on recursive structures!")
 	}
-	panic("unreachable")
+	return assemblerStateError("unreachable")
 }
 func (_Map__Assembler) AssignBool(bool) error {
 	return mixins.MapAssembler{TypeName: "dagjose.Map"}.AssignBool(false)
@@ -14019,9 +14372,9 @@ func (na *_Map__Assembler) AssignNode(v datamodel.Node) error {
 	if v2, ok := v.(*_Map); ok {
 		switch *na.m {
 		case schema.Maybe_Value, schema.Maybe_Null:
-			panic("invalid state: cannot assign into assembler that's already finished")
+			return assemblerStateError("invalid state: cannot assign into assembler that's already finished")
 		case midvalue:
-			panic("invalid state: cannot assign null into an assembler that's already begun working on recursive structures!")
+			return assemblerStateError("invalid state: cannot assign null into an assembler that's already begun working on recursive structures!")
 		}
 		*na.w = *v2
 		*na.m = schema.Maybe_Value
@@ -14056,8 +14409,8 @@ func (ma *_Map__Assembler) keyFinishTidy() bool {
 		ma.cm = schema.Maybe_Absent
 		ma.state = maState_expectValue
 		ma.w.m[tz.k] = &tz.v
-		ma.va.w = &tz.v
-		ma.va.m = &ma.cm
+		ma.va.m = &tz.v.m
+		tz.v.m = allowNull
 		ma.ka.reset()
 		return true
 	default:
@@ -14065,10 +14418,15 @@ func (ma *_Map__Assembler) keyFinishTidy() bool {
 	}
 }
 func (ma *_Map__Assembler) valueFinishTidy() bool {
-	switch ma.cm {
+	tz := &ma.w.t[len(ma.w.t)-1]
+	switch tz.v.m {
+	case schema.Maybe_Null:
+		ma.state = maState_initial
+		ma.va.reset()
+		return true
 	case schema.Maybe_Value:
+		tz.v.v = ma.va.w
 		ma.va.w = nil
-		ma.cm = schema.Maybe_Absent
 		ma.state = maState_initial
 		ma.va.reset()
 		return true
@@ -14081,15 +14439,15 @@ func (ma *_Map__Assembler) AssembleEntry(k string) (datamodel.NodeAssembler, err
 	case maState_initial:
 		// carry on
 	case maState_midKey:
-		panic("invalid state: AssembleEntry cannot be called when in the middle of assembling another key")
+		return nil, assemblerStateError("invalid state: AssembleEntry cannot be called when in the middle of assembling another key")
 	case maState_expectValue:
-		panic("invalid state: AssembleEntry cannot be called when expecting start of value assembly")
+		return nil, assemblerStateError("invalid state: AssembleEntry cannot be called when expecting start of value assembly")
 	case maState_midValue:
 		if !ma.valueFinishTidy() {
-			panic("invalid state: AssembleEntry cannot be called when in the middle of assembling a value")
+			return nil, assemblerStateError("invalid state: AssembleEntry cannot be called when in the middle of assembling a value")
 		} // if tidy success: carry on
 	case maState_finished:
-		panic("invalid state: AssembleEntry cannot be called on an assembler that's already finished")
+		return nil, assemblerStateError("invalid state: AssembleEntry cannot be called on an assembler that's already finished")
 	}
 
 	var k2 _String
@@ -14104,8 +14462,8 @@ func (ma *_Map__Assembler) AssembleEntry(k string) (datamodel.NodeAssembler, err
 	ma.state = maState_midValue
 
 	ma.w.m[k2] = &tz.v
-	ma.va.w = &tz.v
-	ma.va.m = &ma.cm
+	ma.va.m = &tz.v.m
+	tz.v.m = allowNull
 	return &ma.va, nil
 }
 func (ma *_Map__Assembler) AssembleKey() datamodel.NodeAssembler {
@@ -14152,15 +14510,15 @@ func (ma *_Map__Assembler) Finish() error {
 	case maState_initial:
 		// carry on
 	case maState_midKey:
-		panic("invalid state: Finish cannot be called when in the middle of assembling a key")
+		return assemblerStateError("invalid state: Finish cannot be called when in the middle of assembling a key")
 	case maState_expectValue:
-		panic("invalid state: Finish cannot be called when expecting start of value assembly")
+		return assemblerStateError("invalid state: Finish cannot be called when expecting start of value assembly")
 	case maState_midValue:
 		if !ma.valueFinishTidy() {
-			panic("invalid state: Finish cannot be called when in the middle of assembling a value")
+			return assemblerStateError("invalid state: Finish cannot be called when in the middle of assembling a value")
 		} // if tidy success: carry on
 	case maState_finished:
-		panic("invalid state: Finish cannot be called on an assembler that's already finished")
+		return assemblerStateError("invalid state: Finish cannot be called on an assembler that's already finished")
 	}
 	ma.state = maState_finished
 	*ma.m = schema.Maybe_Value
@@ -14299,15 +14657,15 @@ func (na *_Map__ReprAssembler) reset() {
 func (na *_Map__ReprAssembler) BeginMap(sizeHint int64) (datamodel.MapAssembler, error) {
 	switch *na.m {
 	case schema.Maybe_Value, schema.Maybe_Null:
-		panic("invalid state: cannot assign into assembler that's already finished")
+		return nil, assemblerStateError("invalid state: cannot assign into assembler that's already finished")
 	case midvalue:
-		panic("invalid state: it makes no sense to 'begin' twice on the same assembler!")
+		return nil, assemblerStateError("invalid state: it makes no sense to 'begin' twice on the same assembler!")
 	}
 	*na.m = midvalue
 	if sizeHint < 0 {
 		sizeHint = 0
 	}
-	na.w.m = make(map[_String]*_Any, sizeHint)
+	na.w.m = make(map[_String]MaybeAny, sizeHint)
 	na.w.t = make([]_Map__entry, 0, sizeHint)
 	return na, nil
 }
@@ -14322,11 +14680,11 @@ func (na *_Map__ReprAssembler) AssignNull() error {
 	case schema.Maybe_Absent:
 		return mixins.MapAssembler{TypeName: "dagjose.Map.Repr.Repr"}.AssignNull()
 	case schema.Maybe_Value, schema.Maybe_Null:
-		panic("invalid state: cannot assign into assembler that's already finished")
+		return assemblerStateError("invalid state: cannot assign into assembler that's already finished")
 	case midvalue:
-		panic("invalid state: cannot assign null into an assembler that's already begun working on recursive structures!")
+		return assemblerStateError("invalid state: cannot assign null into an assembler that's already begun working on recursive structures!")
 	}
-	panic("unreachable")
+	return assemblerStateError("unreachable")
 }
 func (_Map__ReprAssembler) AssignBool(bool) error {
 	return mixins.MapAssembler{TypeName: "dagjose.Map.Repr"}.AssignBool(false)
@@ -14353,9 +14711,9 @@ func (na *_Map__ReprAssembler) AssignNode(v datamodel.Node) error {
 	if v2, ok := v.(*_Map); ok {
 		switch *na.m {
 		case schema.Maybe_Value, schema.Maybe_Null:
-			panic("invalid state: cannot assign into assembler that's already finished")
+			return assemblerStateError("invalid state: cannot assign into assembler that's already finished")
 		case midvalue:
-			panic("invalid state: cannot assign null into an assembler that's already begun working on recursive structures!")
+			return assemblerStateError("invalid state: cannot assign null into an assembler that's already begun working on recursive structures!")
 		}
 		*na.w = *v2
 		*na.m = schema.Maybe_Value
@@ -14390,8 +14748,8 @@ func (ma *_Map__ReprAssembler) keyFinishTidy() bool {
 		ma.cm = schema.Maybe_Absent
 		ma.state = maState_expectValue
 		ma.w.m[tz.k] = &tz.v
-		ma.va.w = &tz.v
-		ma.va.m = &ma.cm
+		ma.va.m = &tz.v.m
+		tz.v.m = allowNull
 		ma.ka.reset()
 		return true
 	default:
@@ -14399,10 +14757,15 @@ func (ma *_Map__ReprAssembler) keyFinishTidy() bool {
 	}
 }
 func (ma *_Map__ReprAssembler) valueFinishTidy() bool {
-	switch ma.cm {
+	tz := &ma.w.t[len(ma.w.t)-1]
+	switch tz.v.m {
+	case schema.Maybe_Null:
+		ma.state = maState_initial
+		ma.va.reset()
+		return true
 	case schema.Maybe_Value:
+		tz.v.v = ma.va.w
 		ma.va.w = nil
-		ma.cm = schema.Maybe_Absent
 		ma.state = maState_initial
 		ma.va.reset()
 		return true
@@ -14415,15 +14778,15 @@ func (ma *_Map__ReprAssembler) AssembleEntry(k string) (datamodel.NodeAssembler,
 	case maState_initial:
 		// carry on
 	case maState_midKey:
-		panic("invalid state: AssembleEntry cannot be called when in the middle of assembling another key")
+		return nil, assemblerStateError("invalid state: AssembleEntry cannot be called when in the middle of assembling another key")
 	case maState_expectValue:
-		panic("invalid state: AssembleEntry cannot be called when expecting start of value assembly")
+		return nil, assemblerStateError("invalid state: AssembleEntry cannot be called when expecting start of value assembly")
 	case maState_midValue:
 		if !ma.valueFinishTidy() {
-			panic("invalid state: AssembleEntry cannot be called when in the middle of assembling a value")
+			return nil, assemblerStateError("invalid state: AssembleEntry cannot be called when in the middle of assembling a value")
 		} // if tidy success: carry on
 	case maState_finished:
-		panic("invalid state: AssembleEntry cannot be called on an assembler that's already finished")
+		return nil, assemblerStateError("invalid state: AssembleEntry cannot be called on an assembler that's already finished")
 	}
 
 	var k2 _String
@@ -14438,8 +14801,8 @@ func (ma *_Map__ReprAssembler) AssembleEntry(k string) (datamodel.NodeAssembler,
 	ma.state = maState_midValue
 
 	ma.w.m[k2] = &tz.v
-	ma.va.w = &tz.v
-	ma.va.m = &ma.cm
+	ma.va.m = &tz.v.m
+	tz.v.m = allowNull
 	return &ma.va, nil
 }
 func (ma *_Map__ReprAssembler) AssembleKey() datamodel.NodeAssembler {
@@ -14486,15 +14849,15 @@ func (ma *_Map__ReprAssembler) Finish() error {
 	case maState_initial:
 		// carry on
 	case maState_midKey:
-		panic("invalid state: Finish cannot be called when in the middle of assembling a key")
+		return assemblerStateError("invalid state: Finish cannot be called when in the middle of assembling a key")
 	case maState_expectValue:
-		panic("invalid state: Finish cannot be called when expecting start of value assembly")
+		return assemblerStateError("invalid state: Finish cannot be called when expecting start of value assembly")
 	case maState_midValue:
 		if !ma.valueFinishTidy() {
-			panic("invalid state: Finish cannot be called when in the middle of assembling a value")
+			return assemblerStateError("invalid state: Finish cannot be called when in the middle of assembling a value")
 		} // if tidy success: carry on
 	case maState_finished:
-		panic("invalid state: Finish cannot be called on an assembler that's already finished")
+		return assemblerStateError("invalid state: Finish cannot be called on an assembler that's already finished")
 	}
 	ma.state = maState_finished
 	*ma.m = schema.Maybe_Value
@@ -14652,9 +15015,9 @@ func (na *_String__Assembler) AssignNull() error {
 	case schema.Maybe_Absent:
 		return mixins.StringAssembler{TypeName: "dagjose.String"}.AssignNull()
 	case schema.Maybe_Value, schema.Maybe_Null:
-		panic("invalid state: cannot assign into assembler that's already finished")
+		return assemblerStateError("invalid state: cannot assign into assembler that's already finished")
 	}
-	panic("unreachable")
+	return assemblerStateError("unreachable")
 }
 func (_String__Assembler) AssignBool(bool) error {
 	return mixins.StringAssembler{TypeName: "dagjose.String"}.AssignBool(false)
@@ -14668,7 +15031,7 @@ func (_String__Assembler) AssignFloat(float64) error {
 func (na *_String__Assembler) AssignString(v string) error {
 	switch *na.m {
 	case schema.Maybe_Value, schema.Maybe_Null:
-		panic("invalid state: cannot assign into assembler that's already finished")
+		return assemblerStateError("invalid state: cannot assign into assembler that's already finished")
 	}
 	na.w.x = v
 	*na.m = schema.Maybe_Value
@@ -14687,7 +15050,7 @@ func (na *_String__Assembler) AssignNode(v datamodel.Node) error {
 	if v2, ok := v.(*_String); ok {
 		switch *na.m {
 		case schema.Maybe_Value, schema.Maybe_Null:
-			panic("invalid state: cannot assign into assembler that's already finished")
+			return assemblerStateError("invalid state: cannot assign into assembler that's already finished")
 		}
 		*na.w = *v2
 		*na.m = schema.Maybe_Value
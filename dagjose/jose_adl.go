@@ -0,0 +1,254 @@
+package dagjose
+
+import (
+	"context"
+	"sync"
+
+	"github.com/ipld/go-ipld-prime"
+	"github.com/ipld/go-ipld-prime/datamodel"
+	cidlink "github.com/ipld/go-ipld-prime/linking/cid"
+	"github.com/ipld/go-ipld-prime/node/basicnode"
+)
+
+// DecryptedView is an Advanced Data Layout that presents the cleartext of a JWE as a regular node. The JWE is decrypted
+// the first time the node is accessed, so that traversals and path lookups can cross encrypted block boundaries
+// without decrypting blocks they never visit.
+//
+// If decryption fails, methods that can return an error return the decryption error, Kind returns
+// datamodel.Kind_Invalid, and the error is also available from Cleartext.
+type DecryptedView struct {
+	ctx      context.Context
+	jwe      DecodedJWE
+	resolver KeyResolver
+
+	once      sync.Once
+	cleartext datamodel.Node
+	err       error
+}
+
+var _ datamodel.Node = (*DecryptedView)(nil)
+
+// NewDecryptedView returns a DecryptedView of the given JWE, which will be decrypted with keys from the given
+// resolver. The context is passed to the resolver when the JWE is decrypted.
+func NewDecryptedView(ctx context.Context, jwe DecodedJWE, resolver KeyResolver) *DecryptedView {
+	return &DecryptedView{ctx: ctx, jwe: jwe, resolver: resolver}
+}
+
+// DecryptedViewReifier returns an ipld.NodeReifier that presents every JWE loaded through the ipld.LinkSystem as a
+// DecryptedView using the given resolver, and leaves all other nodes untouched. It can be set as the NodeReifier of a
+// LinkSystem, or registered in its KnownReifiers for use with selectors.
+//
+// Only nodes that are known to be JWEs are reified: typed JWE nodes, e.g. loaded with Type.DecodedJWE__Repr, and nodes
+// loaded through a dag-jose link named by LinkContext.LinkNode, as set by the traversal package. Nodes loaded from other
+// codecs are returned as they are even if they look like JWEs, and so are dag-jose nodes that are not valid JWEs.
+func DecryptedViewReifier(resolver KeyResolver) ipld.NodeReifier {
+	return func(linkContext ipld.LinkContext, n datamodel.Node, _ *ipld.LinkSystem) (datamodel.Node, error) {
+		ctx := linkContext.Ctx
+		if ctx == nil {
+			ctx = context.Background()
+		}
+		switch jwe := n.(type) {
+		case *_DecodedJWE:
+			return NewDecryptedView(ctx, jwe, resolver), nil
+		case *_DecodedJWE__Repr:
+			return NewDecryptedView(ctx, (*_DecodedJWE)(jwe), resolver), nil
+		}
+		if !isDAGJOSELink(linkContext.LinkNode) || n.Kind() != datamodel.Kind_Map {
+			return n, nil
+		} else if jwe, err := isJWE(n); err != nil || !jwe {
+			return n, nil
+		} else if decoded, err := asDecodedJWE(n); err != nil {
+			return n, nil
+		} else {
+			return NewDecryptedView(ctx, decoded, resolver), nil
+		}
+	}
+}
+
+// isDAGJOSELink returns true if the given node is a link to a block encoded with the dag-jose codec.
+func isDAGJOSELink(n datamodel.Node) bool {
+	if n == nil || n.Kind() != datamodel.Kind_Link {
+		return false
+	} else if lnk, err := n.AsLink(); err != nil {
+		return false
+	} else if cl, castOk := lnk.(cidlink.Link); !castOk {
+		return false
+	} else {
+		return cl.Cid.Prefix().Codec == LinkPrototype.Codec
+	}
+}
+
+// Substrate returns the JWE underlying the view.
+func (v *DecryptedView) Substrate() DecodedJWE {
+	return v.jwe
+}
+
+// Cleartext decrypts the JWE, if that has not happened yet, and returns the decrypted node.
+func (v *DecryptedView) Cleartext() (datamodel.Node, error) {
+	v.once.Do(func() {
+		nb := basicnode.Prototype.Any.NewBuilder()
+		if v.err = DecryptJWE(v.ctx, v.jwe, v.resolver, nb); v.err == nil {
+			v.cleartext = nb.Build()
+		}
+	})
+	return v.cleartext, v.err
+}
+
+func (v *DecryptedView) Kind() datamodel.Kind {
+	if n, err := v.Cleartext(); err != nil {
+		return datamodel.Kind_Invalid
+	} else {
+		return n.Kind()
+	}
+}
+
+func (v *DecryptedView) LookupByString(key string) (datamodel.Node, error) {
+	if n, err := v.Cleartext(); err != nil {
+		return nil, err
+	} else {
+		return n.LookupByString(key)
+	}
+}
+
+func (v *DecryptedView) LookupByNode(key datamodel.Node) (datamodel.Node, error) {
+	if n, err := v.Cleartext(); err != nil {
+		return nil, err
+	} else {
+		return n.LookupByNode(key)
+	}
+}
+
+func (v *DecryptedView) LookupByIndex(idx int64) (datamodel.Node, error) {
+	if n, err := v.Cleartext(); err != nil {
+		return nil, err
+	} else {
+		return n.LookupByIndex(idx)
+	}
+}
+
+func (v *DecryptedView) LookupBySegment(seg datamodel.PathSegment) (datamodel.Node, error) {
+	if n, err := v.Cleartext(); err != nil {
+		return nil, err
+	} else {
+		return n.LookupBySegment(seg)
+	}
+}
+
+// MapIterator returns an iterator over the cleartext. If decryption fails, the iterator reports the decryption error
+// from Next.
+func (v *DecryptedView) MapIterator() datamodel.MapIterator {
+	if n, err := v.Cleartext(); err != nil {
+		return &failedMapIterator{err}
+	} else {
+		return n.MapIterator()
+	}
+}
+
+// ListIterator returns an iterator over the cleartext. If decryption fails, the iterator reports the decryption error
+// from Next.
+func (v *DecryptedView) ListIterator() datamodel.ListIterator {
+	if n, err := v.Cleartext(); err != nil {
+		return &failedListIterator{err}
+	} else {
+		return n.ListIterator()
+	}
+}
+
+func (v *DecryptedView) Length() int64 {
+	if n, err := v.Cleartext(); err != nil {
+		return -1
+	} else {
+		return n.Length()
+	}
+}
+
+func (v *DecryptedView) IsAbsent() bool {
+	return false
+}
+
+func (v *DecryptedView) IsNull() bool {
+	if n, err := v.Cleartext(); err != nil {
+		return false
+	} else {
+		return n.IsNull()
+	}
+}
+
+func (v *DecryptedView) AsBool() (bool, error) {
+	if n, err := v.Cleartext(); err != nil {
+		return false, err
+	} else {
+		return n.AsBool()
+	}
+}
+
+func (v *DecryptedView) AsInt() (int64, error) {
+	if n, err := v.Cleartext(); err != nil {
+		return 0, err
+	} else {
+		return n.AsInt()
+	}
+}
+
+func (v *DecryptedView) AsFloat() (float64, error) {
+	if n, err := v.Cleartext(); err != nil {
+		return 0, err
+	} else {
+		return n.AsFloat()
+	}
+}
+
+func (v *DecryptedView) AsString() (string, error) {
+	if n, err := v.Cleartext(); err != nil {
+		return "", err
+	} else {
+		return n.AsString()
+	}
+}
+
+func (v *DecryptedView) AsBytes() ([]byte, error) {
+	if n, err := v.Cleartext(); err != nil {
+		return nil, err
+	} else {
+		return n.AsBytes()
+	}
+}
+
+func (v *DecryptedView) AsLink() (datamodel.Link, error) {
+	if n, err := v.Cleartext(); err != nil {
+		return nil, err
+	} else {
+		return n.AsLink()
+	}
+}
+
+// Prototype returns the prototype of the cleartext node, since a DecryptedView cannot be built directly.
+func (v *DecryptedView) Prototype() datamodel.NodePrototype {
+	return basicnode.Prototype.Any
+}
+
+// failedMapIterator is a map iterator whose Next always returns the given error.
+type failedMapIterator struct {
+	err error
+}
+
+func (itr *failedMapIterator) Next() (datamodel.Node, datamodel.Node, error) {
+	return nil, nil, itr.err
+}
+
+func (itr *failedMapIterator) Done() bool {
+	return false
+}
+
+// failedListIterator is a list iterator whose Next always returns the given error.
+type failedListIterator struct {
+	err error
+}
+
+func (itr *failedListIterator) Next() (int64, datamodel.Node, error) {
+	return -1, nil, itr.err
+}
+
+func (itr *failedListIterator) Done() bool {
+	return false
+}
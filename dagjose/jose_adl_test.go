@@ -0,0 +1,121 @@
+package dagjose
+
+import (
+	"context"
+	"crypto/rand"
+	"testing"
+
+	gojose "github.com/go-jose/go-jose/v4"
+	"github.com/ipfs/go-cid"
+	"github.com/ipld/go-ipld-prime"
+	"github.com/ipld/go-ipld-prime/datamodel"
+	"github.com/ipld/go-ipld-prime/fluent"
+	"github.com/ipld/go-ipld-prime/linking/cid"
+	"github.com/ipld/go-ipld-prime/node/basicnode"
+	"github.com/ipld/go-ipld-prime/traversal"
+	"github.com/stretchr/testify/require"
+)
+
+// Path lookups through a link to an encrypted block should reach into its cleartext
+func TestDecryptedViewTraversal(t *testing.T) {
+	key := make([]byte, 32)
+	_, err := rand.Read(key)
+	require.NoError(t, err)
+	cleartext := fluent.MustBuildMap(basicnode.Prototype.Map, 1, func(ma fluent.MapAssembler) {
+		ma.AssembleEntry("secret").CreateMap(1, func(ma fluent.MapAssembler) {
+			ma.AssembleEntry("field").AssignString("value")
+		})
+	})
+	jwe, err := EncryptNode(cleartext, EncryptOptions{}, Recipient{Algorithm: gojose.DIRECT, Key: key})
+	require.NoError(t, err)
+
+	ls := memoryLinkSystem()
+	ls.NodeReifier = DecryptedViewReifier(staticKey(key))
+	jweLink, err := StoreJOSE(ipld.LinkContext{}, jwe, ls)
+	require.NoError(t, err)
+	parent := fluent.MustBuildMap(basicnode.Prototype.Map, 1, func(ma fluent.MapAssembler) {
+		ma.AssembleEntry("encrypted").AssignLink(jweLink)
+	})
+	parentLink, err := ls.Store(ipld.LinkContext{}, cidlink.LinkPrototype{Prefix: cid.Prefix{
+		Version:  1,
+		Codec:    0x71, // dag-cbor
+		MhType:   0x12,
+		MhLength: 32,
+	}}, parent)
+	require.NoError(t, err)
+
+	root, err := ls.Load(ipld.LinkContext{}, parentLink, basicnode.Prototype.Any)
+	require.NoError(t, err)
+	progress := traversal.Progress{Cfg: &traversal.Config{
+		LinkSystem:                     ls,
+		LinkTargetNodePrototypeChooser: basicnode.Chooser,
+	}}
+	field, err := progress.Get(root, datamodel.ParsePath("encrypted/secret/field"))
+	require.NoError(t, err)
+	value, err := field.AsString()
+	require.NoError(t, err)
+	require.Equal(t, "value", value)
+
+	view, err := ls.Load(ipld.LinkContext{LinkNode: basicnode.NewLink(jweLink)}, jweLink, basicnode.Prototype.Any)
+	require.NoError(t, err)
+	require.IsType(t, &DecryptedView{}, view)
+	typed, err := ls.Load(ipld.LinkContext{}, jweLink, Type.DecodedJWE__Repr)
+	require.NoError(t, err)
+	require.IsType(t, &DecryptedView{}, typed)
+	visited := 0
+	require.NoError(t, traversal.WalkLocal(view, func(traversal.Progress, datamodel.Node) error {
+		visited++
+		return nil
+	}))
+	require.Equal(t, 3, visited)
+}
+
+// A view that cannot be decrypted should report the decryption error
+func TestDecryptedViewWithWrongKey(t *testing.T) {
+	key, otherKey := make([]byte, 32), make([]byte, 32)
+	_, err := rand.Read(key)
+	require.NoError(t, err)
+	jwe, err := EncryptNode(cleartextNode(), EncryptOptions{}, Recipient{Algorithm: gojose.DIRECT, Key: key})
+	require.NoError(t, err)
+	decoded, err := asDecodedJWE(jwe)
+	require.NoError(t, err)
+
+	view := NewDecryptedView(context.Background(), decoded, staticKey(otherKey))
+	require.Equal(t, datamodel.Kind_Invalid, view.Kind())
+	_, err = view.LookupByString("secret")
+	require.Error(t, err)
+	_, err = view.Cleartext()
+	require.Error(t, err)
+	_, _, err = view.MapIterator().Next()
+	require.Error(t, err)
+	_, _, err = view.ListIterator().Next()
+	require.Error(t, err)
+
+	view = NewDecryptedView(context.Background(), decoded, staticKey(key))
+	require.Equal(t, datamodel.Kind_Map, view.Kind())
+	secret, err := view.LookupByString("secret")
+	require.NoError(t, err)
+	require.True(t, datamodel.DeepEqual(basicnode.NewString("hello"), secret))
+}
+
+// Nodes that only look like JWEs should be loaded as they are
+func TestDecryptedViewReifierIgnoresNonJOSE(t *testing.T) {
+	ls := memoryLinkSystem()
+	ls.NodeReifier = DecryptedViewReifier(staticKey(make([]byte, 32)))
+	dagCBOR := cidlink.LinkPrototype{Prefix: cid.Prefix{
+		Version:  1,
+		Codec:    0x71, // dag-cbor
+		MhType:   0x12,
+		MhLength: 32,
+	}}
+	for _, ciphertext := range []datamodel.Node{basicnode.NewString("hello"), basicnode.NewBytes([]byte("hello"))} {
+		n := fluent.MustBuildMap(basicnode.Prototype.Map, 1, func(ma fluent.MapAssembler) {
+			ma.AssembleEntry("ciphertext").AssignNode(ciphertext)
+		})
+		lnk, err := ls.Store(ipld.LinkContext{}, dagCBOR, n)
+		require.NoError(t, err)
+		loaded, err := ls.Load(ipld.LinkContext{LinkNode: basicnode.NewLink(lnk)}, lnk, basicnode.Prototype.Any)
+		require.NoError(t, err)
+		require.True(t, datamodel.DeepEqual(n, loaded))
+	}
+}
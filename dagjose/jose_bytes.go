@@ -3,7 +3,6 @@ package dagjose
 import (
 	"encoding/base64"
 
-	"github.com/ipfs/go-cid"
 	"github.com/ipld/go-ipld-prime/datamodel"
 	"github.com/ipld/go-ipld-prime/linking/cid"
 	"github.com/ipld/go-ipld-prime/node/mixins"
@@ -29,6 +28,11 @@ func (m MaybeBase64Url) IsAbsent() bool {
 func (m MaybeBase64Url) Exists() bool {
 	return m.m == schema.Maybe_Value
 }
+// The panics below are in methods whose signatures, fixed by the datamodel and schema interfaces, cannot return an
+// error, so they cannot use assemblerStateError like the assembler methods do. None of them can be reached by Decode:
+// once assembly has finished, m holds one of the three schema.Maybe values handled by AsNode, Decode never calls Must,
+// and Decode only calls Build once assembly has succeeded, at which point a value has always been assigned. FuzzDecode
+// exercises these paths, including building and encoding every successfully decoded node.
 func (m MaybeBase64Url) AsNode() datamodel.Node {
 	switch m.m {
 	case schema.Maybe_Absent:
@@ -147,9 +151,9 @@ func (na *_Base64Url__Assembler) AssignNull() error {
 	case schema.Maybe_Absent:
 		return mixins.StringAssembler{TypeName: "dagjose.Base64Url"}.AssignNull()
 	case schema.Maybe_Value, schema.Maybe_Null:
-		panic("invalid state: cannot assign into assembler that's already finished")
+		return assemblerStateError("invalid state: cannot assign into assembler that's already finished")
 	}
-	panic("unreachable")
+	return assemblerStateError("unreachable")
 }
 func (_Base64Url__Assembler) AssignBool(bool) error {
 	return mixins.StringAssembler{TypeName: "dagjose.Base64Url"}.AssignBool(false)
@@ -163,7 +167,7 @@ func (_Base64Url__Assembler) AssignFloat(float64) error {
 func (na *_Base64Url__Assembler) AssignString(v string) error {
 	switch *na.m {
 	case schema.Maybe_Value, schema.Maybe_Null:
-		panic("invalid state: cannot assign into assembler that's already finished")
+		return assemblerStateError("invalid state: cannot assign into assembler that's already finished")
 	}
 	if decodedBytes, err := decodeBase64Url(v); err != nil {
 		return err
@@ -176,7 +180,7 @@ func (na *_Base64Url__Assembler) AssignString(v string) error {
 func (na *_Base64Url__Assembler) AssignBytes(v []byte) error {
 	switch *na.m {
 	case schema.Maybe_Value, schema.Maybe_Null:
-		panic("invalid state: cannot assign into assembler that's already finished")
+		return assemblerStateError("invalid state: cannot assign into assembler that's already finished")
 	}
 	na.w.x = string(v)
 	*na.m = schema.Maybe_Value
@@ -192,7 +196,7 @@ func (na *_Base64Url__Assembler) AssignNode(v datamodel.Node) error {
 	if v2, ok := v.(*_Base64Url); ok {
 		switch *na.m {
 		case schema.Maybe_Value, schema.Maybe_Null:
-			panic("invalid state: cannot assign into assembler that's already finished")
+			return assemblerStateError("invalid state: cannot assign into assembler that's already finished")
 		}
 		*na.w = *v2
 		*na.m = schema.Maybe_Value
@@ -229,7 +233,7 @@ type _Base64Url__ReprPrototype = _Base64Url__Prototype
 type _Base64Url__ReprAssembler = _Base64Url__Assembler
 
 func (_Base64Url__Prototype) Link(n Base64Url) (Link, error) {
-	c, err := cid.Cast([]byte(n.x))
+	c, err := payloadCID([]byte(n.x))
 	if err != nil {
 		return nil, err
 	}
@@ -255,6 +259,7 @@ func (m MaybeRaw) IsAbsent() bool {
 func (m MaybeRaw) Exists() bool {
 	return m.m == schema.Maybe_Value
 }
+// See MaybeBase64Url.AsNode for why the panics below cannot be reached by Decode.
 func (m MaybeRaw) AsNode() datamodel.Node {
 	switch m.m {
 	case schema.Maybe_Absent:
@@ -373,9 +378,9 @@ func (na *_Raw__Assembler) AssignNull() error {
 	case schema.Maybe_Absent:
 		return mixins.BytesAssembler{TypeName: "dagjose.Raw"}.AssignNull()
 	case schema.Maybe_Value, schema.Maybe_Null:
-		panic("invalid state: cannot assign into assembler that's already finished")
+		return assemblerStateError("invalid state: cannot assign into assembler that's already finished")
 	}
-	panic("unreachable")
+	return assemblerStateError("unreachable")
 }
 func (_Raw__Assembler) AssignBool(bool) error {
 	return mixins.BytesAssembler{TypeName: "dagjose.Raw"}.AssignBool(false)
@@ -389,7 +394,7 @@ func (_Raw__Assembler) AssignFloat(float64) error {
 func (na *_Raw__Assembler) AssignString(v string) error {
 	switch *na.m {
 	case schema.Maybe_Value, schema.Maybe_Null:
-		panic("invalid state: cannot assign into assembler that's already finished")
+		return assemblerStateError("invalid state: cannot assign into assembler that's already finished")
 	}
 	if decodedBytes, err := decodeBase64Url(v); err != nil {
 		return err
@@ -402,7 +407,7 @@ func (na *_Raw__Assembler) AssignString(v string) error {
 func (na *_Raw__Assembler) AssignBytes(v []byte) error {
 	switch *na.m {
 	case schema.Maybe_Value, schema.Maybe_Null:
-		panic("invalid state: cannot assign into assembler that's already finished")
+		return assemblerStateError("invalid state: cannot assign into assembler that's already finished")
 	}
 	na.w.x = v
 	*na.m = schema.Maybe_Value
@@ -418,7 +423,7 @@ func (na *_Raw__Assembler) AssignNode(v datamodel.Node) error {
 	if v2, ok := v.(*_Raw); ok {
 		switch *na.m {
 		case schema.Maybe_Value, schema.Maybe_Null:
-			panic("invalid state: cannot assign into assembler that's already finished")
+			return assemblerStateError("invalid state: cannot assign into assembler that's already finished")
 		}
 		*na.w = *v2
 		*na.m = schema.Maybe_Value
@@ -0,0 +1,294 @@
+package dagjose
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/ipfs/go-cid"
+	"github.com/ipld/go-ipld-prime"
+	"github.com/ipld/go-ipld-prime/codec/dagcbor"
+	"github.com/ipld/go-ipld-prime/datamodel"
+	"github.com/ipld/go-ipld-prime/fluent"
+	"github.com/ipld/go-ipld-prime/linking/cid"
+	"github.com/ipld/go-ipld-prime/node/basicnode"
+	"github.com/multiformats/go-varint"
+)
+
+const (
+	// carV2HeaderSize is the size of the fixed CARv2 header that follows the pragma.
+	carV2HeaderSize = 40
+	// maxCARSectionSize bounds the size of the header and of each block read from a CAR, to avoid allocating arbitrary
+	// amounts of memory for corrupted archives.
+	maxCARSectionSize = 32 << 20
+)
+
+// carV2Pragma is the CARv1-compatible header that starts every CARv2 archive, i.e. the length-prefixed DAG-CBOR
+// encoding of {"version": 2}.
+var carV2Pragma = []byte{0x0a, 0xa1, 0x67, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x02}
+
+// CARWriteOptions configures WriteCAR.
+type CARWriteOptions struct {
+	// Version is the CAR format version to write, 1 or 2. If zero, CARv1 is written.
+	Version int
+}
+
+// CARReadOptions configures ReadCAR.
+type CARReadOptions struct {
+	// Resolver, if not nil, is used to verify every JWS in the archive, which must have at least one valid signature.
+	Resolver KeyResolver
+}
+
+// WriteCAR writes a CAR archive with the given DAG-JOSE links as roots, containing the blocks of the roots and, for
+// every JWS, the block its payload links to. Payload blocks that are DAG-JOSE objects themselves are followed in the
+// same way. All blocks are loaded through the given LinkSystem, and each block is written once.
+// See: https://ipld.io/specs/transport/car/
+func WriteCAR(ctx context.Context, w io.Writer, ls ipld.LinkSystem, roots []ipld.Link, opts CARWriteOptions) error {
+	if opts.Version != 0 && opts.Version != 1 && opts.Version != 2 {
+		return fmt.Errorf("unsupported CAR version: %d", opts.Version)
+	}
+	data := bytes.Buffer{}
+	if err := writeCARv1Header(&data, roots); err != nil {
+		return err
+	}
+	queue := make([]cid.Cid, 0, len(roots))
+	for _, root := range roots {
+		if cl, castOk := root.(cidlink.Link); !castOk {
+			return fmt.Errorf("unsupported link type: %T", root)
+		} else if cl.Cid.Prefix().Codec != LinkPrototype.Codec {
+			return fmt.Errorf("link does not refer to a DAG-JOSE object: unexpected codec 0x%x", cl.Cid.Prefix().Codec)
+		} else {
+			queue = append(queue, cl.Cid)
+		}
+	}
+	written := make(map[cid.Cid]bool)
+	for len(queue) > 0 {
+		c := queue[0]
+		queue = queue[1:]
+		if written[c] {
+			continue
+		}
+		block, err := ls.LoadRaw(ipld.LinkContext{Ctx: ctx}, cidlink.Link{Cid: c})
+		if err != nil {
+			return fmt.Errorf("loading %s: %w", c, err)
+		}
+		writeCARSection(&data, c, block)
+		written[c] = true
+		if c.Prefix().Codec != LinkPrototype.Codec {
+			continue
+		}
+		if payload, err := jwsPayloadCID(block); err != nil {
+			return fmt.Errorf("decoding %s: %w", c, err)
+		} else if payload.Defined() {
+			queue = append(queue, payload)
+		}
+	}
+	if opts.Version == 2 {
+		// The archive is written without an index, which is indicated by an index offset of zero
+		header := make([]byte, carV2HeaderSize)
+		binary.LittleEndian.PutUint64(header[16:], uint64(len(carV2Pragma)+carV2HeaderSize))
+		binary.LittleEndian.PutUint64(header[24:], uint64(data.Len()))
+		if _, err := w.Write(carV2Pragma); err != nil {
+			return err
+		} else if _, err := w.Write(header); err != nil {
+			return err
+		}
+	}
+	_, err := data.WriteTo(w)
+	return err
+}
+
+// ReadCAR reads a CARv1 or CARv2 archive and returns its roots. The hash of every block is checked against its CID,
+// and every block with the dag-jose codec must decode with Decode. Blocks are stored through the given LinkSystem,
+// unless it has no StorageWriteOpener, in which case the archive is only validated.
+func ReadCAR(ctx context.Context, r io.Reader, ls ipld.LinkSystem, opts CARReadOptions) ([]ipld.Link, error) {
+	br := bufio.NewReader(r)
+	if pragma, err := br.Peek(len(carV2Pragma)); err == nil && bytes.Equal(pragma, carV2Pragma) {
+		header := make([]byte, len(carV2Pragma)+carV2HeaderSize)
+		if _, err := io.ReadFull(br, header); err != nil {
+			return nil, fmt.Errorf("invalid CARv2 header: %w", err)
+		}
+		dataOffset := binary.LittleEndian.Uint64(header[len(carV2Pragma)+16:])
+		dataSize := binary.LittleEndian.Uint64(header[len(carV2Pragma)+24:])
+		if dataOffset < uint64(len(header)) {
+			return nil, fmt.Errorf("invalid CARv2 data offset: %d", dataOffset)
+		} else if _, err := br.Discard(int(dataOffset) - len(header)); err != nil {
+			return nil, fmt.Errorf("invalid CARv2 data offset: %w", err)
+		}
+		br = bufio.NewReader(io.LimitReader(br, int64(dataSize)))
+	}
+	roots, err := readCARv1Header(br)
+	if err != nil {
+		return nil, err
+	}
+	for {
+		c, block, err := readCARSection(br)
+		if err == io.EOF {
+			return roots, nil
+		} else if err != nil {
+			return nil, err
+		}
+		if err := validateCARBlock(ctx, c, block, opts); err != nil {
+			return nil, fmt.Errorf("invalid block %s: %w", c, err)
+		}
+		if ls.StorageWriteOpener == nil {
+			continue
+		}
+		if w, commit, err := ls.StorageWriteOpener(ipld.LinkContext{Ctx: ctx}); err != nil {
+			return nil, err
+		} else if _, err := w.Write(block); err != nil {
+			return nil, err
+		} else if err := commit(cidlink.Link{Cid: c}); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func validateCARBlock(ctx context.Context, c cid.Cid, block []byte, opts CARReadOptions) error {
+	if sum, err := c.Prefix().Sum(block); err != nil {
+		return err
+	} else if !sum.Equals(c) {
+		return errors.New("block data does not match its CID")
+	}
+	if c.Prefix().Codec != LinkPrototype.Codec {
+		return nil
+	}
+	nb := basicnode.Prototype.Any.NewBuilder()
+	if err := Decode(nb, bytes.NewReader(block)); err != nil {
+		return err
+	}
+	if opts.Resolver == nil {
+		return nil
+	}
+	if kind, err := peekJOSEKind(block); err != nil || kind != joseKindJWS {
+		return err
+	}
+	results, err := VerifyJWSWithResolver(ctx, nb.Build(), opts.Resolver)
+	if err != nil {
+		return err
+	}
+	for _, result := range results {
+		if result.Valid() {
+			return nil
+		}
+	}
+	return errors.New("no valid signature")
+}
+
+// jwsPayloadCID returns the payload CID of the DAG-JOSE block if it is a JWS, or cid.Undef if it is a JWE.
+func jwsPayloadCID(block []byte) (cid.Cid, error) {
+	if kind, err := peekJOSEKind(block); err != nil {
+		return cid.Undef, err
+	} else if kind == joseKindJWE {
+		return cid.Undef, nil
+	}
+	jwsBuilder := Type.DecodedJWS__Repr.NewBuilder()
+	if err := (DecodeOptions{}.DecodeJWS(jwsBuilder, bytes.NewReader(block))); err != nil {
+		return cid.Undef, err
+	}
+	return payloadCID([]byte(jwsBuilder.Build().(DecodedJWS).payload.x))
+}
+
+func writeCARv1Header(w io.Writer, roots []ipld.Link) error {
+	header, err := fluent.BuildMap(basicnode.Prototype.Map, 2, func(ma fluent.MapAssembler) {
+		ma.AssembleEntry("roots").CreateList(int64(len(roots)), func(la fluent.ListAssembler) {
+			for _, root := range roots {
+				la.AssembleValue().AssignLink(root)
+			}
+		})
+		ma.AssembleEntry("version").AssignInt(1)
+	})
+	if err != nil {
+		return err
+	}
+	buf := bytes.Buffer{}
+	if err := dagcbor.Encode(header, &buf); err != nil {
+		return err
+	}
+	if _, err := w.Write(varint.ToUvarint(uint64(buf.Len()))); err != nil {
+		return err
+	}
+	_, err = buf.WriteTo(w)
+	return err
+}
+
+func readCARv1Header(r *bufio.Reader) ([]ipld.Link, error) {
+	section, err := readCARSectionBytes(r)
+	if err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, fmt.Errorf("invalid CAR header: %w", err)
+	}
+	nb := basicnode.Prototype.Map.NewBuilder()
+	if err := dagcbor.Decode(nb, bytes.NewReader(section)); err != nil {
+		return nil, fmt.Errorf("invalid CAR header: %w", err)
+	}
+	header := nb.Build()
+	if version, err := header.LookupByString("version"); err != nil {
+		return nil, fmt.Errorf("invalid CAR header: %w", err)
+	} else if v, err := version.AsInt(); err != nil || v != 1 {
+		return nil, fmt.Errorf("unsupported CAR version: %v", version)
+	}
+	rootsNode, err := header.LookupByString("roots")
+	if err != nil {
+		return nil, fmt.Errorf("invalid CAR header: %w", err)
+	} else if rootsNode.Kind() != datamodel.Kind_List {
+		return nil, errors.New("invalid CAR header: `roots` is not a list")
+	}
+	roots := make([]ipld.Link, 0, rootsNode.Length())
+	itr := rootsNode.ListIterator()
+	for !itr.Done() {
+		_, root, err := itr.Next()
+		if err != nil {
+			return nil, err
+		}
+		if lnk, err := root.AsLink(); err != nil {
+			return nil, fmt.Errorf("invalid CAR header: %w", err)
+		} else {
+			roots = append(roots, lnk)
+		}
+	}
+	return roots, nil
+}
+
+func writeCARSection(w *bytes.Buffer, c cid.Cid, block []byte) {
+	cidBytes := c.Bytes()
+	w.Write(varint.ToUvarint(uint64(len(cidBytes) + len(block))))
+	w.Write(cidBytes)
+	w.Write(block)
+}
+
+// readCARSection reads the next block from a CARv1 payload. It returns io.EOF at the end of the payload.
+func readCARSection(r *bufio.Reader) (cid.Cid, []byte, error) {
+	section, err := readCARSectionBytes(r)
+	if err != nil {
+		return cid.Undef, nil, err
+	}
+	if n, c, err := cid.CidFromBytes(section); err != nil {
+		return cid.Undef, nil, fmt.Errorf("invalid CAR section: %w", err)
+	} else {
+		return c, section[n:], nil
+	}
+}
+
+func readCARSectionBytes(r *bufio.Reader) ([]byte, error) {
+	if _, err := r.Peek(1); err != nil {
+		return nil, err
+	}
+	length, err := varint.ReadUvarint(r)
+	if err != nil {
+		return nil, fmt.Errorf("invalid CAR section length: %w", err)
+	} else if length == 0 || length > maxCARSectionSize {
+		return nil, fmt.Errorf("invalid CAR section length: %d", length)
+	}
+	section := make([]byte, length)
+	if _, err := io.ReadFull(r, section); err != nil {
+		return nil, fmt.Errorf("truncated CAR section: %w", err)
+	}
+	return section, nil
+}
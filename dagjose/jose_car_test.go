@@ -0,0 +1,103 @@
+package dagjose
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	gojose "github.com/go-jose/go-jose/v4"
+	"github.com/ipfs/go-cid"
+	"github.com/ipld/go-ipld-prime"
+	"github.com/ipld/go-ipld-prime/linking/cid"
+	"github.com/multiformats/go-multihash"
+	"github.com/stretchr/testify/require"
+)
+
+// carFixture stores a JWS, its payload and a JWE, and returns the links to the JWS and JWE
+func carFixture(t *testing.T, ls ipld.LinkSystem) (ipld.Link, ipld.Link, cid.Cid, *MemoryKeyResolver) {
+	payload, err := ls.Store(ipld.LinkContext{}, cidlink.LinkPrototype{Prefix: cid.Prefix{
+		Version:  1,
+		Codec:    cid.DagCBOR,
+		MhType:   multihash.SHA2_256,
+		MhLength: -1,
+	}}, cleartextNode())
+	require.NoError(t, err)
+	privateKey := ed25519PrivateKeyGen().Example()
+	jws, err := SignCID(payload.(cidlink.Link).Cid, Signer{Algorithm: gojose.EdDSA, Key: privateKey, KeyID: "key"})
+	require.NoError(t, err)
+	jwsLink, err := StoreJOSE(ipld.LinkContext{}, jws, ls)
+	require.NoError(t, err)
+	jwe, err := EncryptNode(cleartextNode(), EncryptOptions{}, Recipient{Algorithm: gojose.A256KW, Key: bytes.Repeat([]byte{1}, 32)})
+	require.NoError(t, err)
+	jweLink, err := StoreJOSE(ipld.LinkContext{}, jwe, ls)
+	require.NoError(t, err)
+	resolver := NewMemoryKeyResolver()
+	resolver.Add("key", privateKey)
+	return jwsLink, jweLink, payload.(cidlink.Link).Cid, resolver
+}
+
+func TestCARRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	ls := memoryLinkSystem()
+	jwsLink, jweLink, payload, resolver := carFixture(t, ls)
+
+	for _, version := range []int{1, 2} {
+		car := bytes.Buffer{}
+		require.NoError(t, WriteCAR(ctx, &car, ls, []ipld.Link{jwsLink, jweLink, jwsLink}, CARWriteOptions{Version: version}))
+		if version == 2 {
+			require.True(t, bytes.HasPrefix(car.Bytes(), carV2Pragma))
+		}
+
+		imported := memoryLinkSystem()
+		roots, err := ReadCAR(ctx, bytes.NewReader(car.Bytes()), imported, CARReadOptions{Resolver: resolver})
+		require.NoError(t, err)
+		require.Equal(t, []ipld.Link{jwsLink, jweLink, jwsLink}, roots)
+		for _, c := range []cid.Cid{jwsLink.(cidlink.Link).Cid, jweLink.(cidlink.Link).Cid, payload} {
+			original, err := ls.LoadRaw(ipld.LinkContext{}, cidlink.Link{Cid: c})
+			require.NoError(t, err)
+			block, err := imported.LoadRaw(ipld.LinkContext{}, cidlink.Link{Cid: c})
+			require.NoError(t, err)
+			require.Equal(t, original, block)
+		}
+		signed, err := LoadSigned(imported, jwsLink, nil)
+		require.NoError(t, err)
+		require.NotNil(t, signed.Payload)
+
+		// Verification fails without the right keys
+		_, err = ReadCAR(ctx, bytes.NewReader(car.Bytes()), memoryLinkSystem(), CARReadOptions{Resolver: NewMemoryKeyResolver()})
+		require.ErrorContains(t, err, "no valid signature")
+	}
+}
+
+func TestReadCARRejectsInvalidBlocks(t *testing.T) {
+	ctx := context.Background()
+	ls := memoryLinkSystem()
+	jwsLink, _, payload, _ := carFixture(t, ls)
+	car := bytes.Buffer{}
+	require.NoError(t, WriteCAR(ctx, &car, ls, []ipld.Link{jwsLink}, CARWriteOptions{}))
+
+	// Flip the last byte, which belongs to the last block
+	corrupted := append([]byte{}, car.Bytes()...)
+	corrupted[len(corrupted)-1] ^= 0xff
+	_, err := ReadCAR(ctx, bytes.NewReader(corrupted), ipld.LinkSystem{}, CARReadOptions{})
+	require.ErrorContains(t, err, "does not match its CID")
+
+	// A block with the dag-jose codec that is not a JOSE object
+	notJOSE := bytes.Buffer{}
+	require.NoError(t, writeCARv1Header(&notJOSE, nil))
+	block := encodeDagCBOR(t, cleartextNode())
+	c, err := cid.Prefix{Version: 1, Codec: LinkPrototype.Codec, MhType: multihash.SHA2_256, MhLength: -1}.Sum(block)
+	require.NoError(t, err)
+	writeCARSection(&notJOSE, c, block)
+	_, err = ReadCAR(ctx, &notJOSE, ipld.LinkSystem{}, CARReadOptions{})
+	require.ErrorContains(t, err, "invalid JOSE object")
+
+	for _, truncated := range [][]byte{nil, car.Bytes()[:10], car.Bytes()[:car.Len()-1]} {
+		_, err = ReadCAR(ctx, bytes.NewReader(truncated), ipld.LinkSystem{}, CARReadOptions{})
+		require.Error(t, err)
+	}
+
+	// Only DAG-JOSE roots and known versions can be written
+	require.Error(t, WriteCAR(ctx, &bytes.Buffer{}, ls, []ipld.Link{cidlink.Link{Cid: payload}}, CARWriteOptions{}))
+	require.Error(t, WriteCAR(ctx, &bytes.Buffer{}, ls, []ipld.Link{jwsLink}, CARWriteOptions{Version: 3}))
+}
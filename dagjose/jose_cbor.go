@@ -0,0 +1,225 @@
+package dagjose
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/ipld/go-ipld-prime/datamodel"
+)
+
+const (
+	cborMajorUnsigned = iota
+	cborMajorNegative
+	cborMajorBytes
+	cborMajorText
+	cborMajorArray
+	cborMajorMap
+	cborMajorTag
+	cborMajorSimple
+)
+
+const (
+	cborSimpleFalse = 20
+	cborSimpleTrue  = 21
+	cborSimpleNull  = 22
+	cborFloat64Info = 27
+	cborTagCID      = 42
+)
+
+// maxCBORNestingDepth is the maximum nesting depth of arrays and maps accepted when checking that a block is canonical.
+const maxCBORNestingDepth = 256
+
+// cborScanner walks the data items of a CBOR block without decoding them.
+// See: https://datatracker.ietf.org/doc/html/rfc8949#section-3
+type cborScanner struct {
+	buf []byte
+	pos int
+	// If true, reject argument encodings and simple values that DAG-CBOR does not allow.
+	canonical bool
+}
+
+// readHead reads the initial byte and argument of a data item. For strings, arrays and maps, the argument is the
+// length, while for other major types it is the value itself (or its size, for floats).
+func (s *cborScanner) readHead() (byte, uint64, error) {
+	if s.pos >= len(s.buf) {
+		return 0, 0, io.ErrUnexpectedEOF
+	}
+	major, info := s.buf[s.pos]>>5, s.buf[s.pos]&0x1f
+	s.pos++
+	var size int
+	switch {
+	case info < 24:
+		if s.canonical && major == cborMajorSimple && info != cborSimpleFalse && info != cborSimpleTrue && info != cborSimpleNull {
+			return 0, 0, fmt.Errorf("unsupported CBOR simple value: %d", info)
+		}
+		return major, uint64(info), nil
+	case info == 24:
+		size = 1
+	case info == 25:
+		size = 2
+	case info == 26:
+		size = 4
+	case info == 27:
+		size = 8
+	default:
+		// DAG-CBOR does not allow indefinite-length items
+		return 0, 0, fmt.Errorf("unsupported CBOR additional information: %d", info)
+	}
+	if s.canonical && major == cborMajorSimple && info != cborFloat64Info {
+		// DAG-CBOR always encodes floats with 64 bits
+		return 0, 0, fmt.Errorf("unsupported CBOR float or simple value encoding: %d", info)
+	}
+	if len(s.buf)-s.pos < size {
+		return 0, 0, io.ErrUnexpectedEOF
+	}
+	var arg uint64
+	for _, b := range s.buf[s.pos : s.pos+size] {
+		arg = arg<<8 | uint64(b)
+	}
+	s.pos += size
+	if s.canonical && major != cborMajorSimple && (arg < 24 || (size > 1 && arg>>(8*size/2) == 0)) {
+		return 0, 0, fmt.Errorf("non-minimal CBOR argument encoding: %d in %d bytes", arg, size)
+	}
+	return major, arg, nil
+}
+
+func (s *cborScanner) readTextString() (string, error) {
+	if major, length, err := s.readHead(); err != nil {
+		return "", err
+	} else if major != cborMajorText {
+		return "", fmt.Errorf("expected a CBOR text string map key, found major type %d", major)
+	} else if uint64(len(s.buf)-s.pos) < length {
+		return "", io.ErrUnexpectedEOF
+	} else {
+		str := string(s.buf[s.pos : s.pos+int(length)])
+		s.pos += int(length)
+		return str, nil
+	}
+}
+
+// skipItem skips over the next data item, including any nested items.
+func (s *cborScanner) skipItem() error {
+	for remaining := uint64(1); remaining > 0; remaining-- {
+		major, arg, err := s.readHead()
+		if err != nil {
+			return err
+		}
+		switch major {
+		case cborMajorBytes, cborMajorText:
+			if uint64(len(s.buf)-s.pos) < arg {
+				return io.ErrUnexpectedEOF
+			}
+			s.pos += int(arg)
+		case cborMajorArray, cborMajorMap:
+			if major == cborMajorMap {
+				arg *= 2
+			}
+			// Every item takes at least one byte, which bounds the number of items still to be skipped
+			if arg > uint64(len(s.buf)-s.pos) {
+				return io.ErrUnexpectedEOF
+			}
+			remaining += arg
+		case cborMajorTag:
+			remaining++
+		}
+	}
+	return nil
+}
+
+// checkCanonical verifies that the given block is exactly what Encode would produce for the JWS or JWE it contains,
+// i.e. that it is valid DAG-CBOR with map keys in RFC 7049 order, that the fields of type `Raw` are encoded as bytes,
+// and that a JWS has no `link` field.
+func checkCanonical(block []byte, kind joseKind) error {
+	s := cborScanner{buf: block, canonical: true}
+	if err := s.checkItem(datamodel.Path{}, kind); err != nil {
+		return err
+	} else if s.pos != len(block) {
+		return fmt.Errorf("non-canonical DAG-JOSE block: %d unexpected bytes after the end of the object", len(block)-s.pos)
+	}
+	return nil
+}
+
+func (s *cborScanner) checkItem(path datamodel.Path, kind joseKind) error {
+	if path.Len() > maxCBORNestingDepth {
+		return fmt.Errorf("invalid DAG-JOSE block at %q: nesting depth exceeds %d", path.String(), maxCBORNestingDepth)
+	}
+	major, arg, err := s.readHead()
+	if err != nil {
+		return fmt.Errorf("non-canonical DAG-JOSE block at %q: %w", path.String(), err)
+	}
+	if isRawField(path, kind) && major != cborMajorBytes {
+		return fmt.Errorf("non-canonical DAG-JOSE block at %q: expected bytes, found major type %d", path.String(), major)
+	}
+	switch major {
+	case cborMajorBytes, cborMajorText:
+		if uint64(len(s.buf)-s.pos) < arg {
+			return fmt.Errorf("non-canonical DAG-JOSE block at %q: %w", path.String(), io.ErrUnexpectedEOF)
+		}
+		s.pos += int(arg)
+	case cborMajorArray:
+		for i := uint64(0); i < arg; i++ {
+			if err := s.checkItem(path.AppendSegmentInt(int64(i)), kind); err != nil {
+				return err
+			}
+		}
+	case cborMajorMap:
+		prevKey := ""
+		for i := uint64(0); i < arg; i++ {
+			key, err := s.readTextString()
+			if err != nil {
+				return fmt.Errorf("non-canonical DAG-JOSE block at %q: %w", path.String(), err)
+			} else if i > 0 && !canonicalKeyLess(prevKey, key) {
+				return fmt.Errorf("non-canonical DAG-JOSE block at %q: map key %q is not sorted after %q", path.String(), key, prevKey)
+			} else if path.Len() == 0 && kind == joseKindJWS && key == "link" {
+				return fmt.Errorf("non-canonical DAG-JOSE block: `link` field must not be encoded")
+			}
+			if err := s.checkItem(path.AppendSegmentString(key), kind); err != nil {
+				return err
+			}
+			prevKey = key
+		}
+	case cborMajorTag:
+		if arg != cborTagCID {
+			return fmt.Errorf("non-canonical DAG-JOSE block at %q: unsupported CBOR tag %d", path.String(), arg)
+		} else if major, length, err := s.readHead(); err != nil {
+			return fmt.Errorf("non-canonical DAG-JOSE block at %q: %w", path.String(), err)
+		} else if major != cborMajorBytes {
+			return fmt.Errorf("non-canonical DAG-JOSE block at %q: expected CID bytes, found major type %d", path.String(), major)
+		} else if uint64(len(s.buf)-s.pos) < length {
+			return fmt.Errorf("non-canonical DAG-JOSE block at %q: %w", path.String(), io.ErrUnexpectedEOF)
+		} else {
+			s.pos += int(length)
+		}
+	}
+	return nil
+}
+
+// isRawField returns true if the value at the given path is one of the fields of type `Raw` in the schema, which
+// must be encoded as CBOR bytes.
+func isRawField(path datamodel.Path, kind joseKind) bool {
+	segments := path.Segments()
+	if len(segments) == 1 {
+		field := segments[0].String()
+		if kind == joseKindJWS {
+			return field == "payload"
+		}
+		return field == "aad" || field == "ciphertext" || field == "iv" || field == "protected" || field == "tag"
+	} else if len(segments) == 3 {
+		list, field := segments[0].String(), segments[2].String()
+		if kind == joseKindJWS {
+			return list == "signatures" && (field == "protected" || field == "signature")
+		}
+		return list == "recipients" && field == "encrypted_key"
+	}
+	return false
+}
+
+// canonicalKeyLess reports whether map key a sorts strictly before map key b in RFC 7049 canonical order, i.e. shorter
+// keys first and then bytewise comparison.
+// See: https://datatracker.ietf.org/doc/html/rfc7049#section-3.9
+func canonicalKeyLess(a, b string) bool {
+	if len(a) != len(b) {
+		return len(a) < len(b)
+	}
+	return a < b
+}
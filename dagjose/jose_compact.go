@@ -0,0 +1,187 @@
+package dagjose
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/ipfs/go-cid"
+	"github.com/ipld/go-ipld-prime/datamodel"
+	"github.com/ipld/go-ipld-prime/schema"
+)
+
+// ParseCompactJWS parses a JWS in compact serialization, i.e. `protected.payload.signature`, where the payload is a
+// base64url-encoded CID.
+// See: https://datatracker.ietf.org/doc/html/rfc7515#section-7.1
+func ParseCompactJWS(s string) (EncodedJWS, error) {
+	parts := strings.Split(s, ".")
+	if len(parts) != 3 {
+		return nil, &ErrInvalidSerialization{
+			Object: "compact JWS",
+			Reason: fmt.Sprintf("expected 3 parts, found %d", len(parts)),
+		}
+	}
+	decoded, err := decodeCompactParts("compact JWS", parts, []string{"protected", "payload", "signature"})
+	if err != nil {
+		return nil, err
+	}
+	protected, payload, signature := decoded[0], decoded[1], decoded[2]
+	if len(protected) == 0 {
+		return nil, &ErrInvalidSerialization{Object: "compact JWS", Field: "protected", Reason: "missing"}
+	}
+	if _, err := payloadCID(payload); err != nil {
+		return nil, err
+	}
+	return &_EncodedJWS{
+		payload: _Raw{payload},
+		signatures: _EncodedSignatures__Maybe{m: schema.Maybe_Value, v: _EncodedSignatures{[]_EncodedSignature{{
+			protected: _Raw__Maybe{m: schema.Maybe_Value, v: _Raw{protected}},
+			signature: _Raw{signature},
+		}}}},
+	}, nil
+}
+
+// ParseDetachedCompactJWS is like ParseCompactJWS for a JWS whose payload was supplied separately, e.g. one exported by
+// ToCompactJWS with `b64: false`. The payload part of the compact serialization, if not empty, must be the given CID.
+// See: https://datatracker.ietf.org/doc/html/rfc7515#appendix-F
+func ParseDetachedCompactJWS(s string, payload cid.Cid) (EncodedJWS, error) {
+	if !payload.Defined() {
+		return nil, errors.New("cannot attach an undefined CID")
+	}
+	parts := strings.Split(s, ".")
+	if len(parts) == 3 && parts[1] == "" {
+		parts[1] = encodeBase64Url(payload.Bytes())
+	}
+	jws, err := ParseCompactJWS(strings.Join(parts, "."))
+	if err != nil {
+		return nil, err
+	}
+	if existing, err := payloadCID(jws.payload.x); err != nil {
+		return nil, err
+	} else if !existing.Equals(payload) {
+		return nil, &ErrLinkMismatch{Link: payload, Payload: existing}
+	}
+	return jws, nil
+}
+
+// ParseCompactJWE parses a JWE in compact serialization, i.e. `protected.encrypted_key.iv.ciphertext.tag`.
+// See: https://datatracker.ietf.org/doc/html/rfc7516#section-7.1
+func ParseCompactJWE(s string) (EncodedJWE, error) {
+	parts := strings.Split(s, ".")
+	if len(parts) != 5 {
+		return nil, &ErrInvalidSerialization{
+			Object: "compact JWE",
+			Reason: fmt.Sprintf("expected 5 parts, found %d", len(parts)),
+		}
+	}
+	decoded, err := decodeCompactParts("compact JWE", parts, []string{"protected", "encrypted_key", "iv", "ciphertext", "tag"})
+	if err != nil {
+		return nil, err
+	}
+	protected, encryptedKey, iv, ciphertext, tag := decoded[0], decoded[1], decoded[2], decoded[3], decoded[4]
+	if len(protected) == 0 {
+		return nil, &ErrInvalidSerialization{Object: "compact JWE", Field: "protected", Reason: "missing"}
+	}
+	jwe := &_EncodedJWE{
+		ciphertext: _Raw{ciphertext},
+		protected:  _Raw__Maybe{m: schema.Maybe_Value, v: _Raw{protected}},
+	}
+	if len(encryptedKey) > 0 {
+		jwe.recipients = _EncodedRecipients__Maybe{m: schema.Maybe_Value, v: _EncodedRecipients{[]_EncodedRecipient{{
+			encrypted_key: _Raw__Maybe{m: schema.Maybe_Value, v: _Raw{encryptedKey}},
+		}}}}
+	}
+	if len(iv) > 0 {
+		jwe.iv = _Raw__Maybe{m: schema.Maybe_Value, v: _Raw{iv}}
+	}
+	if len(tag) > 0 {
+		jwe.tag = _Raw__Maybe{m: schema.Maybe_Value, v: _Raw{tag}}
+	}
+	return jwe, nil
+}
+
+// ToCompactJWS returns the compact serialization of the given JWS node. Compact serialization can only represent a JWS
+// with exactly one signature and no unprotected header. A JWS with `b64: false` is exported with a detached payload,
+// which can be parsed with ParseDetachedCompactJWS.
+func ToCompactJWS(n datamodel.Node) (string, error) {
+	jws, err := asDecodedJWS(n)
+	if err != nil {
+		return "", err
+	}
+	if !jws.signatures.Exists() || len(jws.signatures.v.x) != 1 {
+		return "", errors.New("compact JWS serialization requires exactly one signature")
+	}
+	signature := jws.signatures.v.x[0]
+	if signature.header.Exists() {
+		return "", errors.New("compact JWS serialization cannot represent an unprotected header")
+	}
+	if !signature.protected.Exists() {
+		return "", errors.New("compact JWS serialization requires a protected header")
+	}
+	protectedString, _ := signature.protected.v.AsString()
+	payloadString := ""
+	// An unencoded payload is always detached since the binary CID cannot be represented in the compact serialization
+	if unencoded, err := jwsHasUnencodedPayload(jws); err != nil {
+		return "", err
+	} else if !unencoded {
+		payloadString, _ = jws.payload.AsString()
+	}
+	signatureString, _ := signature.signature.AsString()
+	return strings.Join([]string{protectedString, payloadString, signatureString}, "."), nil
+}
+
+// ToCompactJWE returns the compact serialization of the given JWE node. Compact serialization can only represent a JWE
+// with at most one recipient, no unprotected headers and no additional authenticated data.
+func ToCompactJWE(n datamodel.Node) (string, error) {
+	jwe, err := asDecodedJWE(n)
+	if err != nil {
+		return "", err
+	}
+	if jwe.unprotected.Exists() {
+		return "", errors.New("compact JWE serialization cannot represent a shared unprotected header")
+	}
+	if jwe.aad.Exists() {
+		return "", errors.New("compact JWE serialization cannot represent additional authenticated data")
+	}
+	if !jwe.protected.Exists() {
+		return "", errors.New("compact JWE serialization requires a protected header")
+	}
+	encryptedKeyString := ""
+	if jwe.recipients.Exists() {
+		if len(jwe.recipients.v.x) > 1 {
+			return "", errors.New("compact JWE serialization requires at most one recipient")
+		} else if len(jwe.recipients.v.x) == 1 {
+			recipient := jwe.recipients.v.x[0]
+			if recipient.header.Exists() {
+				return "", errors.New("compact JWE serialization cannot represent a recipient header")
+			}
+			if recipient.encrypted_key.Exists() {
+				encryptedKeyString, _ = recipient.encrypted_key.v.AsString()
+			}
+		}
+	}
+	protectedString, _ := jwe.protected.v.AsString()
+	ciphertextString, _ := jwe.ciphertext.AsString()
+	ivString, tagString := "", ""
+	if jwe.iv.Exists() {
+		ivString, _ = jwe.iv.v.AsString()
+	}
+	if jwe.tag.Exists() {
+		tagString, _ = jwe.tag.v.AsString()
+	}
+	return strings.Join([]string{protectedString, encryptedKeyString, ivString, ciphertextString, tagString}, "."), nil
+}
+
+// decodeCompactParts decodes the base64url-encoded parts of a compact serialization, which correspond to the given
+// fields.
+func decodeCompactParts(object string, parts []string, fields []string) ([][]byte, error) {
+	decoded := make([][]byte, len(parts))
+	for idx, part := range parts {
+		if decodedPart, err := decodeBase64Url(part); err != nil {
+			return nil, &ErrInvalidSerialization{Object: object, Field: fields[idx], Err: err}
+		} else {
+			decoded[idx] = decodedPart
+		}
+	}
+	return decoded, nil
+}
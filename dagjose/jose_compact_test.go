@@ -0,0 +1,112 @@
+package dagjose
+
+import (
+	"crypto/ecdh"
+	"crypto/rand"
+	"testing"
+
+	gojose "github.com/go-jose/go-jose/v4"
+	"github.com/stretchr/testify/require"
+	"pgregory.net/rapid"
+)
+
+func TestCompactJWSRoundTrip(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		link := cidGen().Draw(t, "payload")
+		privateKey := ed25519PrivateKeyGen().Draw(t, "private key")
+		jws, err := SignCID(link, Signer{Algorithm: gojose.EdDSA, Key: privateKey})
+		require.NoError(t, err)
+		compact, err := ToCompactJWS(jws)
+		require.NoError(t, err)
+
+		parsed, err := ParseCompactJWS(compact)
+		require.NoError(t, err)
+		reserialized, err := ToCompactJWS(parsed)
+		require.NoError(t, err)
+		require.Equal(t, compact, reserialized)
+
+		results, err := VerifyJWS(parsed, privateKey.Public())
+		require.NoError(t, err)
+		require.True(t, results[0].Valid(), "%v", results[0].Err)
+	})
+}
+
+// Compact tokens produced by go-jose should be accepted
+func TestParseCompactJWSFromGoJose(t *testing.T) {
+	link := createCid([]byte("payload"))
+	privateKey := ed25519PrivateKeyGen().Example()
+	signer, err := gojose.NewSigner(gojose.SigningKey{Algorithm: gojose.EdDSA, Key: privateKey}, nil)
+	require.NoError(t, err)
+	joseJws, err := signer.Sign(link.Bytes())
+	require.NoError(t, err)
+	compact, err := joseJws.CompactSerialize()
+	require.NoError(t, err)
+
+	jws, err := ParseCompactJWS(compact)
+	require.NoError(t, err)
+	results, err := VerifyJWS(roundTripJWS(t, jws), privateKey.Public())
+	require.NoError(t, err)
+	require.True(t, results[0].Valid(), "%v", results[0].Err)
+}
+
+func TestCompactJWSErrors(t *testing.T) {
+	link := createCid([]byte("payload"))
+	first, second := ed25519PrivateKeyGen().Example(0), ed25519PrivateKeyGen().Example(1)
+	jws, err := SignCID(link,
+		Signer{Algorithm: gojose.EdDSA, Key: first},
+		Signer{Algorithm: gojose.EdDSA, Key: second},
+	)
+	require.NoError(t, err)
+	_, err = ToCompactJWS(jws)
+	require.ErrorContains(t, err, "exactly one signature")
+
+	jws, err = SignCID(link, Signer{Algorithm: gojose.EdDSA, Key: first, Header: map[string]interface{}{"a": "b"}})
+	require.NoError(t, err)
+	_, err = ToCompactJWS(jws)
+	require.ErrorContains(t, err, "unprotected header")
+
+	_, err = ParseCompactJWS("a.b")
+	require.Error(t, err)
+	_, err = ParseCompactJWS("eyJhbGciOiJFZERTQSJ9.bm90IGEgY2lk.c2ln")
+	require.ErrorContains(t, err, "payload is not a valid CID")
+}
+
+func TestCompactJWERoundTrip(t *testing.T) {
+	key, err := ecdh.X25519().GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	jwe, err := EncryptNode(cleartextNode(), EncryptOptions{Encryption: XC20P},
+		Recipient{Algorithm: gojose.ECDH_ES_A256KW, Key: key.PublicKey()})
+	require.NoError(t, err)
+	compact, err := ToCompactJWE(jwe)
+	require.NoError(t, err)
+
+	parsed, err := ParseCompactJWE(compact)
+	require.NoError(t, err)
+	reserialized, err := ToCompactJWE(parsed)
+	require.NoError(t, err)
+	require.Equal(t, compact, reserialized)
+	requireDecryptsToCleartext(t, roundTripJWE(t, parsed), staticKey(key))
+}
+
+func TestCompactJWEErrors(t *testing.T) {
+	first, err := ecdh.X25519().GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	second, err := ecdh.X25519().GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	jwe, err := EncryptNode(cleartextNode(), EncryptOptions{},
+		Recipient{Algorithm: gojose.ECDH_ES_A256KW, Key: first.PublicKey()},
+		Recipient{Algorithm: gojose.ECDH_ES_A256KW, Key: second.PublicKey()},
+	)
+	require.NoError(t, err)
+	_, err = ToCompactJWE(jwe)
+	require.Error(t, err)
+
+	jwe, err = EncryptNode(cleartextNode(), EncryptOptions{AAD: []byte("aad")},
+		Recipient{Algorithm: gojose.ECDH_ES_A256KW, Key: first.PublicKey()})
+	require.NoError(t, err)
+	_, err = ToCompactJWE(jwe)
+	require.ErrorContains(t, err, "additional authenticated data")
+
+	_, err = ParseCompactJWE("a.b.c")
+	require.Error(t, err)
+}
@@ -2,10 +2,12 @@ package dagjose
 
 import (
 	"bytes"
+	"fmt"
 	"io"
 
 	"github.com/ipld/go-ipld-prime/codec/dagcbor"
 	"github.com/ipld/go-ipld-prime/datamodel"
+	cidlink "github.com/ipld/go-ipld-prime/linking/cid"
 	"github.com/ipld/go-ipld-prime/schema"
 )
 
@@ -14,20 +16,38 @@ import (
 type DecodeOptions struct {
 	// If true and the `payload` field is present, add a `link` field corresponding to the `payload`.
 	AddLink bool
+	// If true, reject any block that is not byte-for-byte what Encode would produce for the decoded object, so that the
+	// same logical object cannot have multiple encodings.
+	Strict bool
 }
 
 // Decode deserializes data from the given io.Reader and feeds it into the given datamodel.NodeAssembler. Decode fits
 // the codec.Decoder function interface.
 func (cfg DecodeOptions) Decode(na datamodel.NodeAssembler, r io.Reader) error {
-	// Since a JWE might be partially decoded by the time we figure out that this isn't a JWE and is probably a JWS, we
-	// can't pass the same reader to both decode methods. Instead, we read off all input bytes and use them in separate
-	// readers to the JWE/JWS decode methods.
+	// The top-level map keys are scanned to find out whether this is a JWE or a JWS before any decoding takes place, so
+	// that the input is only decoded once, by the right decoder. This requires buffering the input.
 	if buf, err := io.ReadAll(r); err != nil {
 		return err
-	} else if err := cfg.DecodeJWE(na, bytes.NewReader(buf)); err != nil {
-		return cfg.DecodeJWS(na, bytes.NewReader(buf))
+	} else if kind, err := peekJOSEKind(buf); err != nil {
+		return err
+	} else {
+		return cfg.decodeBlock(na, buf, kind)
+	}
+}
+
+// decodeBlock is like DecodeJWE or DecodeJWS, depending on the given kind, for a block that has already been read into
+// memory.
+func (cfg DecodeOptions) decodeBlock(na datamodel.NodeAssembler, block []byte, kind joseKind) error {
+	if cfg.Strict {
+		if err := checkCanonical(block, kind); err != nil {
+			return err
+		}
+	}
+	if kind == joseKindJWE {
+		return cfg.decodeJWE(na, bytes.NewReader(block))
+	} else {
+		return cfg.decodeJWS(na, bytes.NewReader(block))
 	}
-	return nil
 }
 
 // Decode deserializes data from the given io.Reader and feeds it into the given datamodel.NodeAssembler. Decode fits
@@ -38,20 +58,25 @@ func Decode(na datamodel.NodeAssembler, r io.Reader) error {
 	}.Decode(na, r)
 }
 
-func (DecodeOptions) DecodeJWE(na datamodel.NodeAssembler, r io.Reader) error {
-	// Check for the fastpath where the passed assembler is already of type `_DecodedJWE__ReprBuilder` or
-	// `_DecodedJWE__ReprAssembler`.
+func (cfg DecodeOptions) DecodeJWE(na datamodel.NodeAssembler, r io.Reader) error {
+	if !cfg.Strict {
+		return cfg.decodeJWE(na, r)
+	} else if buf, err := io.ReadAll(r); err != nil {
+		return err
+	} else {
+		return cfg.decodeBlock(na, buf, joseKindJWE)
+	}
+}
+
+func (cfg DecodeOptions) decodeJWE(na datamodel.NodeAssembler, r io.Reader) error {
+	// Check for the fastpath where the passed assembler is already of type `_DecodedJWE__ReprBuilder`.
 	copyRequired := false
 	jweBuilder, castOk := na.(*_DecodedJWE__ReprBuilder)
 	if !castOk {
-		// This could still be `_DecodedJWE__ReprAssembler`, so check for that.
-		_, castOk := na.(*_DecodedJWE__ReprAssembler)
-		if !castOk {
-			// No fastpath possible, just create a new `_DecodedJWE__ReprBuilder`, use it, then copy the built node into
-			// the assembler the caller passed in.
-			jweBuilder = Type.DecodedJWE__Repr.NewBuilder().(*_DecodedJWE__ReprBuilder)
-			copyRequired = true
-		}
+		// No fastpath possible, just create a new `_DecodedJWE__ReprBuilder`, use it, then copy the built node into the
+		// assembler the caller passed in.
+		jweBuilder = Type.DecodedJWE__Repr.NewBuilder().(*_DecodedJWE__ReprBuilder)
+		copyRequired = true
 	}
 	// DAG-CBOR is a superset of DAG-JOSE and can be used to decode valid DAG-JOSE objects.
 	// See: https://specs.ipld.io/block-layer/codecs/dag-jose.html
@@ -67,35 +92,44 @@ func (DecodeOptions) DecodeJWE(na datamodel.NodeAssembler, r io.Reader) error {
 }
 
 func (cfg DecodeOptions) DecodeJWS(na datamodel.NodeAssembler, r io.Reader) error {
-	// Check for the fastpath where the passed assembler is already of type `_DecodedJWS__ReprBuilder` or
-	// `_DecodedJWS__ReprAssembler`.
+	if !cfg.Strict {
+		return cfg.decodeJWS(na, r)
+	} else if buf, err := io.ReadAll(r); err != nil {
+		return err
+	} else {
+		return cfg.decodeBlock(na, buf, joseKindJWS)
+	}
+}
+
+func (cfg DecodeOptions) decodeJWS(na datamodel.NodeAssembler, r io.Reader) error {
+	// Check for the fastpath where the passed assembler is already of type `_DecodedJWS__ReprBuilder`.
 	copyRequired := false
 	jwsBuilder, castOk := na.(*_DecodedJWS__ReprBuilder)
 	if !castOk {
-		// This could still be `_DecodedJWS__ReprAssembler`, so check for that.
-		_, castOk := na.(*_DecodedJWS__ReprAssembler)
-		if !castOk {
-			// No fastpath possible, just create a new `_DecodedJWE__ReprBuilder`, use it, then copy the built node into
-			// the assembler the caller passed in.
-			jwsBuilder = Type.DecodedJWS__Repr.NewBuilder().(*_DecodedJWS__ReprBuilder)
-			copyRequired = true
-		}
+		// No fastpath possible, just create a new `_DecodedJWS__ReprBuilder`, use it, then copy the built node into the
+		// assembler the caller passed in.
+		jwsBuilder = Type.DecodedJWS__Repr.NewBuilder().(*_DecodedJWS__ReprBuilder)
+		copyRequired = true
 	}
 	// DAG-CBOR is a superset of DAG-JOSE and can be used to decode valid DAG-JOSE objects.
 	// See: https://specs.ipld.io/block-layer/codecs/dag-jose.html
 	if err := dagcbor.Decode(jwsBuilder, r); err != nil {
 		return err
 	}
-	if cfg.AddLink {
-		// If `payload` is present but `link` is not, add `link` with the corresponding encoded CID.
-		linkNode := &jwsBuilder.w.link
-		if !linkNode.Exists() {
-			if link, err := Type.Base64Url.Link(&jwsBuilder.w.payload); err != nil {
-				return err
-			} else {
-				linkNode.m = schema.Maybe_Value
-				linkNode.v = *link
-			}
+	// The `link` field is not covered by the signatures, so an existing `link` must match the `payload`, otherwise it
+	// could be used to substitute the signed payload.
+	linkNode := &jwsBuilder.w.link
+	if linkNode.Exists() || cfg.AddLink {
+		if link, err := Type.Base64Url.Link(&jwsBuilder.w.payload); err != nil {
+			return err
+		} else if !linkNode.Exists() {
+			// If `payload` is present but `link` is not, add `link` with the corresponding encoded CID.
+			linkNode.m = schema.Maybe_Value
+			linkNode.v = *link
+		} else if existing, castOk := linkNode.v.x.(cidlink.Link); !castOk {
+			return &ErrInvalidSerialization{Object: "JWS", Field: "link", Reason: fmt.Sprintf("unsupported link type %T", linkNode.v.x)}
+		} else if payload := link.x.(cidlink.Link); existing.Cid != payload.Cid {
+			return &ErrLinkMismatch{Link: existing.Cid, Payload: payload.Cid}
 		}
 	}
 	// The "representation" node gives an accurate view of fields that are actually present
@@ -105,3 +139,45 @@ func (cfg DecodeOptions) DecodeJWS(na datamodel.NodeAssembler, r io.Reader) erro
 	}
 	return nil
 }
+
+type joseKind uint8
+
+const (
+	joseKindJWS joseKind = iota
+	joseKindJWE
+)
+
+// peekJOSEKind scans the keys of the top-level CBOR map in the given block, without decoding any values, and reports
+// whether the block is a JWE (it has a `ciphertext` field) or a JWS (it has a `payload` field).
+func peekJOSEKind(block []byte) (joseKind, error) {
+	s := cborScanner{buf: block}
+	major, count, err := s.readHead()
+	if err != nil {
+		return 0, fmt.Errorf("%w: %w", ErrNotJOSE, err)
+	} else if major != cborMajorMap {
+		return 0, fmt.Errorf("%w: expected a CBOR map, found major type %d", ErrNotJOSE, major)
+	}
+	hasCiphertext, hasPayload := false, false
+	for i := uint64(0); i < count; i++ {
+		if key, err := s.readTextString(); err != nil {
+			return 0, fmt.Errorf("%w: %w", ErrNotJOSE, err)
+		} else if key == "ciphertext" {
+			hasCiphertext = true
+		} else if key == "payload" {
+			hasPayload = true
+		}
+		if err := s.skipItem(); err != nil {
+			return 0, fmt.Errorf("%w: %w", ErrNotJOSE, err)
+		}
+	}
+	switch {
+	case hasCiphertext && hasPayload:
+		return 0, fmt.Errorf("%w: found both `ciphertext` (JWE) and `payload` (JWS) fields", ErrNotJOSE)
+	case hasCiphertext:
+		return joseKindJWE, nil
+	case hasPayload:
+		return joseKindJWS, nil
+	default:
+		return 0, fmt.Errorf("%w: found neither `ciphertext` (JWE) nor `payload` (JWS) field", ErrNotJOSE)
+	}
+}
@@ -0,0 +1,225 @@
+package dagjose
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/ipfs/go-cid"
+	"github.com/ipld/go-ipld-prime/codec/dagcbor"
+	"github.com/ipld/go-ipld-prime/codec/dagjson"
+	"github.com/ipld/go-ipld-prime/datamodel"
+	"github.com/ipld/go-ipld-prime/fluent"
+	"github.com/ipld/go-ipld-prime/linking/cid"
+	"github.com/ipld/go-ipld-prime/node/basicnode"
+	"github.com/stretchr/testify/require"
+	"pgregory.net/rapid"
+)
+
+func encodeDagCBOR(t require.TestingT, n datamodel.Node) []byte {
+	buf := bytes.Buffer{}
+	require.NoError(t, dagcbor.Encode(n, &buf))
+	return buf.Bytes()
+}
+
+// The kind of a valid JWS or JWE should be detected from its keys alone
+func TestPeekJOSEKind(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		jws := validJWSGen().Draw(t, "JWS")
+		kind, err := peekJOSEKind(encodeDagCBOR(t, jws))
+		require.NoError(t, err)
+		require.Equal(t, joseKindJWS, kind)
+
+		jwe := jweGen(rapid.IntRange(0, 3).Draw(t, "recipients")).Draw(t, "JWE")
+		kind, err = peekJOSEKind(encodeDagCBOR(t, jwe))
+		require.NoError(t, err)
+		require.Equal(t, joseKindJWE, kind)
+	})
+}
+
+func TestDecodeRejectsNonJOSE(t *testing.T) {
+	scenarios := map[string]datamodel.Node{
+		"not a map": basicnode.NewString("payload"),
+		"neither": fluent.MustBuildMap(basicnode.Prototype.Map, 1, func(ma fluent.MapAssembler) {
+			ma.AssembleEntry("signatures").AssignString("abc")
+		}),
+		"both": fluent.MustBuildMap(basicnode.Prototype.Map, 2, func(ma fluent.MapAssembler) {
+			ma.AssembleEntry("ciphertext").AssignBytes([]byte("abc"))
+			ma.AssembleEntry("payload").AssignBytes([]byte("abc"))
+		}),
+	}
+	for name, n := range scenarios {
+		err := Decode(basicnode.Prototype.Any.NewBuilder(), bytes.NewReader(encodeDagCBOR(t, n)))
+		require.Error(t, err, name)
+		require.Contains(t, err.Error(), "invalid JOSE object", name)
+	}
+}
+
+// Truncated blocks should be rejected without reading past the end of the input
+func TestPeekJOSEKindTruncated(t *testing.T) {
+	block := encodeDagCBOR(t, validJWSGen().Example())
+	for i := 0; i < len(block); i++ {
+		_, err := peekJOSEKind(block[:i])
+		require.Error(t, err)
+	}
+}
+
+// Strict decoding should accept anything produced by Encode
+func TestStrictDecodeAcceptsCanonicalBlocks(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		for _, n := range []datamodel.Node{
+			validJWSGen().Draw(t, "JWS"),
+			jweGen(rapid.IntRange(0, 3).Draw(t, "recipients")).Draw(t, "JWE"),
+		} {
+			buf := bytes.Buffer{}
+			require.NoError(t, Encode(n, &buf))
+			nb := basicnode.Prototype.Any.NewBuilder()
+			require.NoError(t, DecodeOptions{Strict: true}.Decode(nb, &buf))
+		}
+	})
+}
+
+func TestStrictDecodeRejectsNonCanonicalBlocks(t *testing.T) {
+	jws := validJWSGen().Example()
+	payloadNode, err := jws.LookupByString("payload")
+	require.NoError(t, err)
+	payload, err := payloadNode.AsString()
+	require.NoError(t, err)
+	rawPayload, err := payloadNode.AsBytes()
+	require.NoError(t, err)
+	signatures, err := jws.LookupByString("signatures")
+	require.NoError(t, err)
+	payloadCid, err := cid.Cast(rawPayload)
+	require.NoError(t, err)
+	encodeUnsorted := func(build func(ma fluent.MapAssembler)) []byte {
+		buf := bytes.Buffer{}
+		require.NoError(t, dagcbor.EncodeOptions{AllowLinks: true}.Encode(fluent.MustBuildMap(basicnode.Prototype.Map, 3, build), &buf))
+		return buf.Bytes()
+	}
+	canonical := encodeDagCBOR(t, fluent.MustBuildMap(basicnode.Prototype.Map, 1, func(ma fluent.MapAssembler) {
+		ma.AssembleEntry("payload").AssignBytes(rawPayload)
+	}))
+	// The header of a 1-entry map, followed by the same entry count encoded in an extra byte
+	nonMinimal := append([]byte{0xb8, 0x01}, canonical[1:]...)
+
+	scenarios := map[string][]byte{
+		"unsorted keys": encodeUnsorted(func(ma fluent.MapAssembler) {
+			ma.AssembleEntry("signatures").AssignNode(signatures)
+			ma.AssembleEntry("payload").AssignBytes(rawPayload)
+		}),
+		"string payload": encodeUnsorted(func(ma fluent.MapAssembler) {
+			ma.AssembleEntry("payload").AssignString(payload)
+		}),
+		"link": encodeUnsorted(func(ma fluent.MapAssembler) {
+			ma.AssembleEntry("link").AssignLink(cidlink.Link{Cid: payloadCid})
+			ma.AssembleEntry("payload").AssignBytes(rawPayload)
+		}),
+		"non-minimal integer": nonMinimal,
+		"trailing bytes":      append(append([]byte{}, canonical...), 0x00),
+	}
+	for name, block := range scenarios {
+		err := DecodeOptions{Strict: true}.Decode(basicnode.Prototype.Any.NewBuilder(), bytes.NewReader(block))
+		require.Error(t, err, name)
+		require.Contains(t, err.Error(), "non-canonical DAG-JOSE block", name)
+	}
+	require.NoError(t, DecodeOptions{Strict: true}.Decode(basicnode.Prototype.Any.NewBuilder(), bytes.NewReader(canonical)))
+}
+
+// Strict decoding should reject deeply nested blocks instead of recursing without bound
+func TestStrictDecodeLimitsNestingDepth(t *testing.T) {
+	payload := createCid([]byte("payload")).Bytes()
+	nestedBlock := func(depth int) []byte {
+		header := basicnode.NewString("value")
+		for i := 0; i < depth; i++ {
+			nested := header
+			header = fluent.MustBuildList(basicnode.Prototype.List, 1, func(la fluent.ListAssembler) {
+				la.AssembleValue().AssignNode(nested)
+			})
+		}
+		return encodeDagCBOR(t, fluent.MustBuildMap(basicnode.Prototype.Map, 2, func(ma fluent.MapAssembler) {
+			ma.AssembleEntry("payload").AssignBytes(payload)
+			ma.AssembleEntry("signatures").CreateList(1, func(la fluent.ListAssembler) {
+				la.AssembleValue().CreateMap(2, func(ma fluent.MapAssembler) {
+					ma.AssembleEntry("header").AssignNode(header)
+					ma.AssembleEntry("signature").AssignBytes([]byte("signature"))
+				})
+			})
+		}))
+	}
+	// The signature header is at depth 3
+	require.NoError(t, DecodeOptions{Strict: true}.Decode(basicnode.Prototype.Any.NewBuilder(), bytes.NewReader(nestedBlock(maxCBORNestingDepth-3))))
+	err := DecodeOptions{Strict: true}.Decode(basicnode.Prototype.Any.NewBuilder(), bytes.NewReader(nestedBlock(maxCBORNestingDepth-2)))
+	require.ErrorContains(t, err, "nesting depth")
+	err = DecodeOptions{Strict: true}.DecodeJWS(basicnode.Prototype.Any.NewBuilder(), bytes.NewReader(nestedBlock(10*maxCBORNestingDepth)))
+	require.ErrorContains(t, err, "nesting depth")
+}
+
+// Reusing an assembler that has already been assigned should return an error instead of panicking
+func TestDecodeIntoFinishedAssemblerReturnsError(t *testing.T) {
+	jws := encodeDagCBOR(t, validJWSGen().Example())
+	jwsBuilder := Type.DecodedJWS__Repr.NewBuilder()
+	require.NoError(t, DecodeOptions{}.DecodeJWS(jwsBuilder, bytes.NewReader(jws)))
+	err := DecodeOptions{}.DecodeJWS(jwsBuilder, bytes.NewReader(jws))
+	require.ErrorContains(t, err, "already finished")
+
+	for _, np := range []datamodel.NodePrototype{Type.Base64Url, Type.Raw} {
+		nb := np.NewBuilder()
+		require.NoError(t, nb.AssignBytes([]byte("abc")))
+		require.ErrorContains(t, nb.AssignBytes([]byte("abc")), "already finished")
+		require.ErrorContains(t, nb.AssignNull(), "already finished")
+	}
+}
+
+// Decoding arbitrary bytes must return an error instead of panicking, whichever decoder and assembler are used
+func FuzzDecode(f *testing.F) {
+	for idx := 0; idx < 4; idx++ {
+		f.Add(encodeDagCBOR(f, validJWSGen().Example(idx)))
+		f.Add(encodeDagCBOR(f, jweGen(idx).Example(idx)))
+	}
+	// Absent and null optional fields, which are read through the Maybe accessors of Base64Url and Raw
+	payload := createCid([]byte("payload")).Bytes()
+	f.Add(encodeDagCBOR(f, fluent.MustBuildMap(basicnode.Prototype.Map, 1, func(ma fluent.MapAssembler) {
+		ma.AssembleEntry("payload").AssignBytes(payload)
+	})))
+	f.Add(encodeDagCBOR(f, fluent.MustBuildMap(basicnode.Prototype.Map, 2, func(ma fluent.MapAssembler) {
+		ma.AssembleEntry("payload").AssignBytes(payload)
+		ma.AssembleEntry("signatures").CreateList(1, func(la fluent.ListAssembler) {
+			la.AssembleValue().CreateMap(2, func(ma fluent.MapAssembler) {
+				ma.AssembleEntry("protected").AssignNull()
+				ma.AssembleEntry("signature").AssignBytes([]byte("signature"))
+			})
+		})
+	})))
+	f.Add(encodeDagCBOR(f, fluent.MustBuildMap(basicnode.Prototype.Map, 3, func(ma fluent.MapAssembler) {
+		ma.AssembleEntry("aad").AssignNull()
+		ma.AssembleEntry("ciphertext").AssignBytes([]byte("ciphertext"))
+		ma.AssembleEntry("protected").AssignNull()
+	})))
+	f.Fuzz(func(t *testing.T, data []byte) {
+		decoders := []func(datamodel.NodeAssembler, io.Reader) error{
+			Decode,
+			DecodeOptions{Strict: true}.Decode,
+			DecodeOptions{}.DecodeJWE,
+			DecodeOptions{}.DecodeJWS,
+			DecodeOptions{Strict: true}.DecodeJWE,
+			DecodeOptions{Strict: true}.DecodeJWS,
+		}
+		for _, decode := range decoders {
+			for _, np := range []datamodel.NodePrototype{
+				basicnode.Prototype.Any,
+				Type.DecodedJWE__Repr,
+				Type.DecodedJWS__Repr,
+				Type.DecodedJWE,
+				Type.DecodedJWS,
+				Type.Base64Url,
+				Type.Raw,
+			} {
+				nb := np.NewBuilder()
+				if err := decode(nb, bytes.NewReader(data)); err == nil {
+					// Encoding reads every field of the decoded node
+					_ = dagjson.Encode(nb.Build(), io.Discard)
+				}
+			}
+		}
+	})
+}
@@ -0,0 +1,483 @@
+package dagjose
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	gojose "github.com/go-jose/go-jose/v4"
+	josecipher "github.com/go-jose/go-jose/v4/cipher"
+	"github.com/go-jose/go-jose/v4/json"
+	"github.com/ipld/go-ipld-prime/codec/dagcbor"
+	"github.com/ipld/go-ipld-prime/datamodel"
+	"github.com/ipld/go-ipld-prime/schema"
+	"golang.org/x/crypto/chacha20poly1305"
+)
+
+// XC20P is the XChaCha20-Poly1305 content encryption algorithm. It is commonly used with DAG-JOSE but is not supported
+// by go-jose.
+const XC20P = gojose.ContentEncryption("XC20P")
+
+// ECDH_ES_XC20PKW is ECDH-ES key agreement with the content encryption key wrapped using XChaCha20-Poly1305, as used by
+// did-jwt for X25519 recipients. The nonce and authentication tag of the wrapped key are carried in the `iv` and `tag`
+// header parameters of the recipient.
+const ECDH_ES_XC20PKW = gojose.KeyAlgorithm("ECDH-ES+XC20PKW")
+
+// DecryptJWE decrypts the given JWE node using the first recipient for which the resolver returns a working decryption
+// key, and feeds the cleartext, decoded as DAG-CBOR, into the given datamodel.NodeAssembler.
+//
+// Supported key management algorithms are `dir`, `A128KW`, `A192KW`, `A256KW`, `ECDH-ES`, `ECDH-ES+A*KW` and
+// `ECDH-ES+XC20PKW` over the P-256, P-384, P-521 and X25519 curves. Supported content encryption algorithms are `A128GCM`, `A192GCM`, `A256GCM`,
+// `A*CBC-HS*` and `XC20P`.
+func DecryptJWE(ctx context.Context, n datamodel.Node, resolver KeyResolver, na datamodel.NodeAssembler) error {
+	if jwe, err := asDecodedJWE(n); err != nil {
+		return err
+	} else if cleartext, err := decryptJWE(ctx, jwe, resolver); err != nil {
+		return err
+	} else {
+		return decodeCleartext(cleartext, na)
+	}
+}
+
+func decryptJWE(ctx context.Context, jwe DecodedJWE, resolver KeyResolver) ([]byte, error) {
+	shared, err := jweSharedHeaders(jwe)
+	if err != nil {
+		return nil, err
+	}
+	var recipients []_DecodedRecipient
+	if jwe.recipients.Exists() {
+		recipients = jwe.recipients.v.x
+	}
+	if len(recipients) == 0 {
+		// A JWE without recipients can only have been encrypted directly with a shared symmetric key
+		recipients = []_DecodedRecipient{{}}
+	}
+	errs := make([]error, 0, len(recipients))
+	for idx := range recipients {
+		if cleartext, err := decryptForRecipient(ctx, jwe, shared, &recipients[idx], resolver); err != nil {
+			errs = append(errs, fmt.Errorf("recipient %d: %w", idx, err))
+		} else {
+			return cleartext, nil
+		}
+	}
+	return nil, fmt.Errorf("unable to decrypt JWE: %w", errors.Join(errs...))
+}
+
+func decryptForRecipient(ctx context.Context, jwe DecodedJWE, shared map[string]interface{}, recipient DecodedRecipient, resolver KeyResolver) ([]byte, error) {
+	headers := make(map[string]interface{}, len(shared))
+	for k, v := range shared {
+		headers[k] = v
+	}
+	if recipient.header.Exists() {
+		if err := mergeHeader(headers, recipient.header.v); err != nil {
+			return nil, err
+		}
+	}
+	alg, _ := headers["alg"].(string)
+	enc, _ := headers["enc"].(string)
+	if alg == "" || enc == "" {
+		return nil, errors.New("missing `alg` or `enc` header parameter")
+	}
+	key, err := resolver.ResolveDecryptionKey(ctx, headers)
+	if err != nil {
+		return nil, err
+	}
+	if jwk, castOk := key.(gojose.JSONWebKey); castOk {
+		key = jwk.Key
+	} else if jwk, castOk := key.(*gojose.JSONWebKey); castOk {
+		key = jwk.Key
+	}
+	var encryptedKey []byte
+	if recipient.encrypted_key.Exists() {
+		encryptedKey, _ = recipient.encrypted_key.v.AsBytes()
+	}
+	cek, err := unwrapContentKey(gojose.KeyAlgorithm(alg), gojose.ContentEncryption(enc), headers, key, encryptedKey)
+	if err != nil {
+		return nil, err
+	}
+	return decryptContent(gojose.ContentEncryption(enc), cek, jwe)
+}
+
+// unwrapContentKey determines the content encryption key for a recipient.
+// See: https://datatracker.ietf.org/doc/html/rfc7516#section-5.2
+func unwrapContentKey(alg gojose.KeyAlgorithm, enc gojose.ContentEncryption, headers map[string]interface{}, key crypto.PrivateKey, encryptedKey []byte) ([]byte, error) {
+	switch alg {
+	case gojose.DIRECT:
+		if cek, castOk := key.([]byte); !castOk {
+			return nil, fmt.Errorf("invalid key type for %s: %T", alg, key)
+		} else if len(encryptedKey) != 0 {
+			return nil, errors.New("encrypted key must be empty for direct encryption")
+		} else {
+			return cek, nil
+		}
+	case gojose.A128KW, gojose.A192KW, gojose.A256KW:
+		if kek, castOk := key.([]byte); !castOk {
+			return nil, fmt.Errorf("invalid key type for %s: %T", alg, key)
+		} else {
+			return aesKeyUnwrap(kek, encryptedKey)
+		}
+	case gojose.ECDH_ES, gojose.ECDH_ES_A128KW, gojose.ECDH_ES_A192KW, gojose.ECDH_ES_A256KW, ECDH_ES_XC20PKW:
+		privateKey, err := ecdhPrivateKey(key)
+		if err != nil {
+			return nil, fmt.Errorf("invalid key type for %s: %w", alg, err)
+		}
+		epk, err := ephemeralPublicKey(headers, privateKey.Curve())
+		if err != nil {
+			return nil, err
+		}
+		z, err := privateKey.ECDH(epk)
+		if err != nil {
+			return nil, err
+		}
+		apu, apv, err := agreementPartyInfo(headers)
+		if err != nil {
+			return nil, err
+		}
+		if alg == gojose.ECDH_ES {
+			if len(encryptedKey) != 0 {
+				return nil, errors.New("encrypted key must be empty for direct key agreement")
+			}
+			if keySize, err := contentKeySize(enc); err != nil {
+				return nil, err
+			} else {
+				return concatKDF(z, string(enc), apu, apv, keySize), nil
+			}
+		}
+		if alg == ECDH_ES_XC20PKW {
+			return xc20pKeyUnwrap(concatKDF(z, string(alg), apu, apv, chacha20poly1305.KeySize), headers, encryptedKey)
+		}
+		return aesKeyUnwrap(concatKDF(z, string(alg), apu, apv, keyWrapSize(alg)), encryptedKey)
+	default:
+		return nil, fmt.Errorf("unsupported key management algorithm: %s", alg)
+	}
+}
+
+// decryptContent decrypts the JWE ciphertext with the given content encryption key.
+// See: https://datatracker.ietf.org/doc/html/rfc7516#section-5.2
+func decryptContent(enc gojose.ContentEncryption, cek []byte, jwe DecodedJWE) ([]byte, error) {
+	aead, err := contentCipher(enc, cek)
+	if err != nil {
+		return nil, err
+	}
+	var iv, tag []byte
+	if jwe.iv.Exists() {
+		iv, _ = jwe.iv.v.AsBytes()
+	}
+	if jwe.tag.Exists() {
+		tag, _ = jwe.tag.v.AsBytes()
+	}
+	if len(iv) != aead.NonceSize() {
+		return nil, fmt.Errorf("invalid initialization vector length: %d", len(iv))
+	}
+	ciphertext, _ := jwe.ciphertext.AsBytes()
+	sealed := make([]byte, 0, len(ciphertext)+len(tag))
+	sealed = append(append(sealed, ciphertext...), tag...)
+	return aead.Open(nil, iv, sealed, additionalAuthenticatedData(jwe.protected, jwe.aad))
+}
+
+// additionalAuthenticatedData computes the AAD for content encryption from the protected header and the `aad` field,
+// which is ASCII(BASE64URL(UTF8(JWE Protected Header)) || '.' || BASE64URL(JWE AAD)) if `aad` is present, and
+// ASCII(BASE64URL(UTF8(JWE Protected Header))) otherwise.
+func additionalAuthenticatedData(protected, aad _Base64Url__Maybe) []byte {
+	authData := ""
+	if protected.Exists() {
+		authData, _ = protected.v.AsString()
+	}
+	if aad.Exists() {
+		aadString, _ := aad.v.AsString()
+		authData += "." + aadString
+	}
+	return []byte(authData)
+}
+
+func contentCipher(enc gojose.ContentEncryption, cek []byte) (cipher.AEAD, error) {
+	if keySize, err := contentKeySize(enc); err != nil {
+		return nil, err
+	} else if len(cek) != keySize {
+		return nil, fmt.Errorf("invalid content encryption key length for %s: %d", enc, len(cek))
+	}
+	switch enc {
+	case gojose.A128GCM, gojose.A192GCM, gojose.A256GCM:
+		if block, err := aes.NewCipher(cek); err != nil {
+			return nil, err
+		} else {
+			return cipher.NewGCM(block)
+		}
+	case gojose.A128CBC_HS256, gojose.A192CBC_HS384, gojose.A256CBC_HS512:
+		return josecipher.NewCBCHMAC(cek, aes.NewCipher)
+	case XC20P:
+		return chacha20poly1305.NewX(cek)
+	default:
+		return nil, fmt.Errorf("unsupported content encryption algorithm: %s", enc)
+	}
+}
+
+// contentKeySize returns the size in bytes of the content encryption key for the given algorithm.
+func contentKeySize(enc gojose.ContentEncryption) (int, error) {
+	switch enc {
+	case gojose.A128GCM:
+		return 16, nil
+	case gojose.A192GCM:
+		return 24, nil
+	case gojose.A256GCM, gojose.A128CBC_HS256, XC20P:
+		return 32, nil
+	case gojose.A192CBC_HS384:
+		return 48, nil
+	case gojose.A256CBC_HS512:
+		return 64, nil
+	default:
+		return 0, fmt.Errorf("unsupported content encryption algorithm: %s", enc)
+	}
+}
+
+// keyWrapSize returns the size in bytes of the key encryption key for an ECDH-ES key wrapping algorithm.
+func keyWrapSize(alg gojose.KeyAlgorithm) int {
+	switch alg {
+	case gojose.ECDH_ES_A128KW:
+		return 16
+	case gojose.ECDH_ES_A192KW:
+		return 24
+	default:
+		return 32
+	}
+}
+
+func aesKeyUnwrap(kek []byte, encryptedKey []byte) ([]byte, error) {
+	if block, err := aes.NewCipher(kek); err != nil {
+		return nil, err
+	} else {
+		return josecipher.KeyUnwrap(block, encryptedKey)
+	}
+}
+
+// xc20pKeyUnwrap decrypts a content encryption key wrapped with XChaCha20-Poly1305, using the nonce and authentication
+// tag from the `iv` and `tag` header parameters.
+func xc20pKeyUnwrap(kek []byte, headers map[string]interface{}, encryptedKey []byte) ([]byte, error) {
+	aead, err := chacha20poly1305.NewX(kek)
+	if err != nil {
+		return nil, err
+	}
+	iv, err := bytesHeaderParam(headers, "iv")
+	if err != nil {
+		return nil, err
+	}
+	tag, err := bytesHeaderParam(headers, "tag")
+	if err != nil {
+		return nil, err
+	}
+	if len(iv) != aead.NonceSize() {
+		return nil, fmt.Errorf("invalid `iv` header parameter length: %d", len(iv))
+	}
+	sealed := make([]byte, 0, len(encryptedKey)+len(tag))
+	sealed = append(append(sealed, encryptedKey...), tag...)
+	return aead.Open(nil, iv, sealed, nil)
+}
+
+// bytesHeaderParam returns the decoded value of a required base64url encoded header parameter.
+func bytesHeaderParam(headers map[string]interface{}, name string) ([]byte, error) {
+	if encoded, castOk := headers[name].(string); !castOk {
+		return nil, fmt.Errorf("missing `%s` header parameter", name)
+	} else if decoded, err := decodeBase64Url(encoded); err != nil {
+		return nil, fmt.Errorf("invalid `%s` header parameter: %w", name, err)
+	} else {
+		return decoded, nil
+	}
+}
+
+// concatKDF derives a key from an ECDH shared secret using the Concat KDF with SHA-256.
+// See: https://datatracker.ietf.org/doc/html/rfc7518#section-4.6.2
+func concatKDF(z []byte, algID string, apu []byte, apv []byte, keySize int) []byte {
+	supPubInfo := make([]byte, 4)
+	binary.BigEndian.PutUint32(supPubInfo, uint32(keySize)*8)
+	reader := josecipher.NewConcatKDF(crypto.SHA256, z, lengthPrefixed([]byte(algID)), lengthPrefixed(apu), lengthPrefixed(apv), supPubInfo, []byte{})
+	key := make([]byte, keySize)
+	// Reading from the Concat KDF never fails
+	_, _ = reader.Read(key)
+	return key
+}
+
+func lengthPrefixed(data []byte) []byte {
+	out := make([]byte, len(data)+4)
+	binary.BigEndian.PutUint32(out, uint32(len(data)))
+	copy(out[4:], data)
+	return out
+}
+
+// agreementPartyInfo returns the decoded `apu` and `apv` header parameters, if present.
+func agreementPartyInfo(headers map[string]interface{}) ([]byte, []byte, error) {
+	var apu, apv []byte
+	if encoded, found := headers["apu"].(string); found {
+		if decoded, err := decodeBase64Url(encoded); err != nil {
+			return nil, nil, fmt.Errorf("invalid `apu` header parameter: %w", err)
+		} else {
+			apu = decoded
+		}
+	}
+	if encoded, found := headers["apv"].(string); found {
+		if decoded, err := decodeBase64Url(encoded); err != nil {
+			return nil, nil, fmt.Errorf("invalid `apv` header parameter: %w", err)
+		} else {
+			apv = decoded
+		}
+	}
+	return apu, apv, nil
+}
+
+func ecdhPrivateKey(key crypto.PrivateKey) (*ecdh.PrivateKey, error) {
+	switch k := key.(type) {
+	case *ecdh.PrivateKey:
+		return k, nil
+	case *ecdsa.PrivateKey:
+		return k.ECDH()
+	default:
+		return nil, fmt.Errorf("%T", key)
+	}
+}
+
+// ephemeralPublicKey parses the `epk` header parameter as a public key on the given curve.
+func ephemeralPublicKey(headers map[string]interface{}, curve ecdh.Curve) (*ecdh.PublicKey, error) {
+	epk, castOk := headers["epk"].(map[string]interface{})
+	if !castOk {
+		return nil, errors.New("missing or invalid `epk` header parameter")
+	}
+	return jwkToECDHPublicKey(epk, curve)
+}
+
+// jwkToECDHPublicKey converts an EC or OKP public JWK, as a map of its members, to a public key on the given curve.
+func jwkToECDHPublicKey(jwk map[string]interface{}, curve ecdh.Curve) (*ecdh.PublicKey, error) {
+	kty, _ := jwk["kty"].(string)
+	crv, _ := jwk["crv"].(string)
+	if expectedCrv := curveName(curve); crv != expectedCrv {
+		return nil, fmt.Errorf("unexpected key curve: %s, expected %s", crv, expectedCrv)
+	}
+	x, err := jwkCoordinate(jwk, "x")
+	if err != nil {
+		return nil, err
+	}
+	switch kty {
+	case "OKP":
+		return curve.NewPublicKey(x)
+	case "EC":
+		if y, err := jwkCoordinate(jwk, "y"); err != nil {
+			return nil, err
+		} else {
+			// Build the uncompressed point encoding expected by crypto/ecdh
+			point := make([]byte, 0, 1+len(x)+len(y))
+			point = append(append(append(point, 4), x...), y...)
+			return curve.NewPublicKey(point)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported key type: %s", kty)
+	}
+}
+
+func jwkCoordinate(jwk map[string]interface{}, name string) ([]byte, error) {
+	if encoded, castOk := jwk[name].(string); !castOk {
+		return nil, fmt.Errorf("missing `%s` key parameter", name)
+	} else if decoded, err := decodeBase64Url(encoded); err != nil {
+		return nil, fmt.Errorf("invalid `%s` key parameter: %w", name, err)
+	} else {
+		return decoded, nil
+	}
+}
+
+func curveName(curve ecdh.Curve) string {
+	switch curve {
+	case ecdh.P256():
+		return "P-256"
+	case ecdh.P384():
+		return "P-384"
+	case ecdh.P521():
+		return "P-521"
+	case ecdh.X25519():
+		return "X25519"
+	default:
+		return ""
+	}
+}
+
+// jweSharedHeaders returns the union of the protected and shared unprotected header parameters of a JWE.
+func jweSharedHeaders(jwe DecodedJWE) (map[string]interface{}, error) {
+	headers := make(map[string]interface{})
+	if jwe.protected.Exists() {
+		protected, _ := jwe.protected.v.AsBytes()
+		if err := json.Unmarshal(protected, &headers); err != nil {
+			return nil, fmt.Errorf("invalid protected header: %w", err)
+		}
+	}
+	if jwe.unprotected.Exists() {
+		if err := mergeHeader(headers, jwe.unprotected.v); err != nil {
+			return nil, err
+		}
+	}
+	return headers, nil
+}
+
+// mergeHeader adds the parameters of an unprotected header node to the given header parameters. Per RFC 7516, the
+// sets of header parameters must be disjoint.
+func mergeHeader(headers map[string]interface{}, header Any) error {
+	unprotected, err := headerFromMaybeAny(_Any__Maybe{m: schema.Maybe_Value, v: header})
+	if err != nil {
+		return fmt.Errorf("invalid unprotected header: %w", err)
+	}
+	for key, value := range unprotected {
+		if _, found := headers[key]; found {
+			return fmt.Errorf("duplicate header parameter: %s", key)
+		}
+		headers[key] = value
+	}
+	return nil
+}
+
+// decodeCleartext decodes decrypted JWE content as DAG-CBOR. Any bytes following the encoded node must be zero, which
+// allows the cleartext to be padded in order to hide its exact length.
+func decodeCleartext(cleartext []byte, na datamodel.NodeAssembler) error {
+	r := bytes.NewReader(cleartext)
+	if err := (dagcbor.DecodeOptions{
+		AllowLinks:         true,
+		DontParseBeyondEnd: true,
+	}.Decode(na, r)); err != nil {
+		return err
+	}
+	for r.Len() > 0 {
+		if b, _ := r.ReadByte(); b != 0 {
+			return errors.New("invalid cleartext: unexpected data after DAG-CBOR node")
+		}
+	}
+	return nil
+}
+
+// asDecodedJWE returns a typed JWE node corresponding to the given node, which may be in general or flattened form.
+func asDecodedJWE(n datamodel.Node) (DecodedJWE, error) {
+	switch jwe := n.(type) {
+	case *_DecodedJWE:
+		return jwe, nil
+	case *_DecodedJWE__Repr:
+		return (*_DecodedJWE)(jwe), nil
+	}
+	if tn, castOk := n.(schema.TypedNode); castOk {
+		// The "representation" node gives an accurate view of fields that are actually present
+		n = tn.Representation()
+	}
+	if jwe, err := isJWE(n); err != nil {
+		return nil, err
+	} else if !jwe {
+		return nil, &ErrInvalidSerialization{Object: "JWE", Reason: "not a JWE"}
+	}
+	if n, err := unflattenJWE(n); err != nil {
+		return nil, err
+	} else {
+		jweBuilder := Type.DecodedJWE__Repr.NewBuilder()
+		if err := assembleJOSE("JWE", n, jweBuilder); err != nil {
+			return nil, err
+		}
+		return jweBuilder.Build().(DecodedJWE), nil
+	}
+}
@@ -0,0 +1,183 @@
+package dagjose
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"testing"
+
+	gojose "github.com/go-jose/go-jose/v4"
+	"github.com/ipld/go-ipld-prime/codec/dagcbor"
+	"github.com/ipld/go-ipld-prime/datamodel"
+	"github.com/ipld/go-ipld-prime/fluent"
+	"github.com/ipld/go-ipld-prime/node/basicnode"
+	"github.com/stretchr/testify/require"
+)
+
+func cleartextNode() datamodel.Node {
+	return fluent.MustBuildMap(basicnode.Prototype.Map, 2, func(ma fluent.MapAssembler) {
+		ma.AssembleEntry("n").AssignInt(42)
+		ma.AssembleEntry("secret").AssignString("hello")
+	})
+}
+
+// Encrypt the DAG-CBOR encoding of cleartextNode with go-jose and return the decoded DAG-JOSE node
+func goJoseJWE(t *testing.T, enc gojose.ContentEncryption, recipients ...gojose.Recipient) datamodel.Node {
+	buf := bytes.Buffer{}
+	require.NoError(t, dagcbor.Encode(cleartextNode(), &buf))
+	if len(recipients) == 1 {
+		encrypter, err := gojose.NewEncrypter(enc, recipients[0], nil)
+		require.NoError(t, err)
+		joseJwe, err := encrypter.Encrypt(buf.Bytes())
+		require.NoError(t, err)
+		return decodeJOSEJSON(t, []byte(joseJwe.FullSerialize()))
+	}
+	encrypter, err := gojose.NewMultiEncrypter(enc, recipients, nil)
+	require.NoError(t, err)
+	joseJwe, err := encrypter.Encrypt(buf.Bytes())
+	require.NoError(t, err)
+	// go-jose also emits the first recipient's `encrypted_key` at the top level of a general JWE, which is invalid
+	var general map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(joseJwe.FullSerialize()), &general))
+	delete(general, "encrypted_key")
+	generalBytes, err := json.Marshal(general)
+	require.NoError(t, err)
+	return decodeJOSEJSON(t, generalBytes)
+}
+
+// staticKeyResolver is a KeyResolver that returns the same key for any header parameters
+type staticKeyResolver struct {
+	key crypto.PrivateKey
+}
+
+func staticKey(key crypto.PrivateKey) KeyResolver {
+	return staticKeyResolver{key}
+}
+
+func (r staticKeyResolver) ResolveSigningKey(context.Context, map[string]interface{}) (crypto.PrivateKey, error) {
+	return r.key, nil
+}
+
+func (r staticKeyResolver) ResolveVerificationKey(context.Context, map[string]interface{}) (crypto.PublicKey, error) {
+	return r.key, nil
+}
+
+func (r staticKeyResolver) ResolveEncryptionKey(context.Context, map[string]interface{}) (crypto.PublicKey, error) {
+	return r.key, nil
+}
+
+func (r staticKeyResolver) ResolveDecryptionKey(context.Context, map[string]interface{}) (crypto.PrivateKey, error) {
+	return r.key, nil
+}
+
+func requireDecryptsToCleartext(t *testing.T, jwe datamodel.Node, resolver KeyResolver) {
+	nb := basicnode.Prototype.Any.NewBuilder()
+	require.NoError(t, DecryptJWE(context.Background(), jwe, resolver, nb))
+	require.True(t, datamodel.DeepEqual(cleartextNode(), nb.Build()))
+}
+
+func TestDecryptJWE(t *testing.T) {
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	symmetricKey := make([]byte, 32)
+	_, err = rand.Read(symmetricKey)
+	require.NoError(t, err)
+
+	scenarios := []struct {
+		name      string
+		enc       gojose.ContentEncryption
+		recipient gojose.Recipient
+		key       crypto.PrivateKey
+	}{
+		{"ECDH-ES+A256KW", gojose.A256GCM, gojose.Recipient{Algorithm: gojose.ECDH_ES_A256KW, Key: &ecKey.PublicKey}, ecKey},
+		{"ECDH-ES", gojose.A256GCM, gojose.Recipient{Algorithm: gojose.ECDH_ES, Key: &ecKey.PublicKey}, ecKey},
+		{"A256KW", gojose.A256GCM, gojose.Recipient{Algorithm: gojose.A256KW, Key: symmetricKey}, symmetricKey},
+		{"dir", gojose.A256GCM, gojose.Recipient{Algorithm: gojose.DIRECT, Key: symmetricKey}, symmetricKey},
+		{"A128CBC-HS256", gojose.A128CBC_HS256, gojose.Recipient{Algorithm: gojose.A256KW, Key: symmetricKey}, symmetricKey},
+	}
+	for _, scenario := range scenarios {
+		t.Run(scenario.name, func(t *testing.T) {
+			jwe := goJoseJWE(t, scenario.enc, scenario.recipient)
+			requireDecryptsToCleartext(t, jwe, staticKey(scenario.key))
+		})
+	}
+}
+
+// The resolver should be consulted for each recipient until one of them can be decrypted
+func TestDecryptJWEMultipleRecipients(t *testing.T) {
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	symmetricKey := make([]byte, 32)
+	_, err = rand.Read(symmetricKey)
+	require.NoError(t, err)
+	jwe := goJoseJWE(t, gojose.A256GCM,
+		gojose.Recipient{Algorithm: gojose.A256KW, Key: symmetricKey, KeyID: "symmetric"},
+		gojose.Recipient{Algorithm: gojose.ECDH_ES_A256KW, Key: &ecKey.PublicKey, KeyID: "ec"},
+	)
+	resolver := NewMemoryKeyResolver()
+	resolver.Add("ec", ecKey)
+	requireDecryptsToCleartext(t, jwe, resolver)
+}
+
+func TestDecryptJWEWithWrongKey(t *testing.T) {
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	otherKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	jwe := goJoseJWE(t, gojose.A256GCM, gojose.Recipient{Algorithm: gojose.ECDH_ES_A256KW, Key: &ecKey.PublicKey})
+	err = DecryptJWE(context.Background(), jwe, staticKey(otherKey), basicnode.Prototype.Any.NewBuilder())
+	require.Error(t, err)
+}
+
+// Fixed X25519 JWEs with XC20P content encryption, generated with fixed keys and nonces by a separate JavaScript
+// implementation built on Node's crypto module. The first uses `ECDH-ES+XC20PKW` key wrapping in the shape produced by
+// the did-jwt x25519Encrypter, the second direct key agreement. The recipient's private key is 32 bytes of 0x11, and
+// the cleartext is the DAG-CBOR encoding of {"hello": "world"} padded with zero bytes to 16 bytes.
+func TestDecryptX25519XC20PVectors(t *testing.T) {
+	vectors := map[string]string{
+		"ECDH-ES+XC20PKW": `{"protected":"eyJlbmMiOiJYQzIwUCJ9","iv":"MzMzMzMzMzMzMzMzMzMzMzMzMzMzMzMz","ciphertext":"Y3Naf4TBAVBGkf7l8sVJWw","tag":"CbhPAt7-6_o2E7fJY5WJ6Q","recipients":[{"encrypted_key":"8jxP2OuqyfPGoUvSAzvq42YNkkuY8x3_YxbgZ2xfFqA","header":{"alg":"ECDH-ES+XC20PKW","iv":"VVVVVVVVVVVVVVVVVVVVVVVVVVVVVVVV","tag":"QdeZ1MWMPlk-XNp18xtXZQ","epk":{"kty":"OKP","crv":"X25519","x":"D6poTtKIZ7l_Smot7l34zpdOdrcBjj8iocTPJnhXDyA"},"kid":"did:example:recipient#key"}}]}`,
+		"ECDH-ES":         `{"protected":"eyJhbGciOiJFQ0RILUVTIiwiZW5jIjoiWEMyMFAiLCJlcGsiOnsia3R5IjoiT0tQIiwiY3J2IjoiWDI1NTE5IiwieCI6IkQ2cG9UdEtJWjdsX1Ntb3Q3bDM0enBkT2RyY0Jqajhpb2NUUEpuaFhEeUEifX0","iv":"MzMzMzMzMzMzMzMzMzMzMzMzMzMzMzMz","ciphertext":"_BLBO5qAFlVLv_deJmJIxA","tag":"vsIcVUFud_K_V96XB5qtZQ"}`,
+	}
+	privateKey, err := ecdh.X25519().NewPrivateKey(bytes.Repeat([]byte{0x11}, 32))
+	require.NoError(t, err)
+	require.Equal(t, "e06Qm75__kTEZaIgA31gjuNYl9Me-XLwf3SJLLD3PxM", encodeBase64Url(privateKey.PublicKey().Bytes()))
+	expected := fluent.MustBuildMap(basicnode.Prototype.Map, 1, func(ma fluent.MapAssembler) {
+		ma.AssembleEntry("hello").AssignString("world")
+	})
+	paddedCleartext, err := hex.DecodeString("a16568656c6c6f65776f726c64000000")
+	require.NoError(t, err)
+
+	for name, vector := range vectors {
+		jwe := decodeJOSEJSON(t, []byte(vector))
+		decoded, err := asDecodedJWE(jwe)
+		require.NoError(t, err, name)
+		cleartext, err := decryptJWE(context.Background(), decoded, staticKey(privateKey))
+		require.NoError(t, err, name)
+		require.Equal(t, paddedCleartext, cleartext, name)
+
+		nb := basicnode.Prototype.Any.NewBuilder()
+		require.NoError(t, DecryptJWE(context.Background(), jwe, staticKey(privateKey), nb), name)
+		require.True(t, datamodel.DeepEqual(expected, nb.Build()), name)
+
+		otherKey, err := ecdh.X25519().GenerateKey(rand.Reader)
+		require.NoError(t, err)
+		require.Error(t, DecryptJWE(context.Background(), jwe, staticKey(otherKey), basicnode.Prototype.Any.NewBuilder()), name)
+	}
+}
+
+func TestDecodeCleartextAllowsZeroPadding(t *testing.T) {
+	buf := bytes.Buffer{}
+	require.NoError(t, dagcbor.Encode(cleartextNode(), &buf))
+	padded := append(buf.Bytes(), 0, 0, 0, 0)
+	nb := basicnode.Prototype.Any.NewBuilder()
+	require.NoError(t, decodeCleartext(padded, nb))
+	require.True(t, datamodel.DeepEqual(cleartextNode(), nb.Build()))
+
+	require.Error(t, decodeCleartext(append(buf.Bytes(), 1), basicnode.Prototype.Any.NewBuilder()))
+}
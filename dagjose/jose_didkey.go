@@ -0,0 +1,119 @@
+package dagjose
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"github.com/multiformats/go-multibase"
+	"github.com/multiformats/go-varint"
+)
+
+// Multicodec codes for the public key types supported by did:key.
+// See: https://github.com/multiformats/multicodec/blob/master/table.csv
+const (
+	multicodecSecp256k1Pub = 0xe7
+	multicodecX25519Pub    = 0xec
+	multicodecEd25519Pub   = 0xed
+	multicodecP256Pub      = 0x1200
+)
+
+const didKeyPrefix = "did:key:"
+
+// DIDKeyResolver is a KeyResolver for `kid` header parameters that are did:key DID URLs, such as
+// `did:key:z6Mk...#z6Mk...`. The key is contained in the identifier itself, so resolution works offline.
+// See: https://w3c-ccg.github.io/did-method-key/
+type DIDKeyResolver struct{}
+
+var _ KeyResolver = DIDKeyResolver{}
+
+// ResolveVerificationKey returns the public key identified by the `kid` header parameter. X25519 keys are rejected
+// since they cannot verify signatures.
+func (DIDKeyResolver) ResolveVerificationKey(_ context.Context, header map[string]interface{}) (crypto.PublicKey, error) {
+	kid, _ := header["kid"].(string)
+	if key, err := ParseDIDKey(kid); err != nil {
+		return nil, err
+	} else if _, castOk := key.(*ecdh.PublicKey); castOk {
+		return nil, errors.New("did:key is a key agreement key and cannot verify signatures")
+	} else {
+		return key, nil
+	}
+}
+
+// ParseDIDKey returns the public key identified by the given did:key DID or DID URL. Any fragment is ignored, since the
+// key is fully identified by the DID.
+//
+// Ed25519 keys are returned as ed25519.PublicKey, P-256 keys as *ecdsa.PublicKey, secp256k1 keys as
+// *secp256k1.PublicKey and X25519 keys as *ecdh.PublicKey.
+func ParseDIDKey(did string) (crypto.PublicKey, error) {
+	if !strings.HasPrefix(did, didKeyPrefix) {
+		return nil, fmt.Errorf("not a did:key identifier: %q", did)
+	}
+	identifier, _, _ := strings.Cut(strings.TrimPrefix(did, didKeyPrefix), "#")
+	// did:key identifiers are always base58btc encoded
+	if encoding, data, err := multibase.Decode(identifier); err != nil {
+		return nil, fmt.Errorf("invalid did:key identifier: %w", err)
+	} else if encoding != multibase.Base58BTC {
+		return nil, fmt.Errorf("invalid did:key identifier: unexpected multibase encoding %q", string(rune(encoding)))
+	} else if code, n, err := varint.FromUvarint(data); err != nil {
+		return nil, fmt.Errorf("invalid did:key identifier: %w", err)
+	} else {
+		return parseMulticodecPublicKey(code, data[n:])
+	}
+}
+
+func parseMulticodecPublicKey(code uint64, keyBytes []byte) (crypto.PublicKey, error) {
+	switch code {
+	case multicodecEd25519Pub:
+		if len(keyBytes) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("invalid Ed25519 public key length: %d", len(keyBytes))
+		}
+		return ed25519.PublicKey(keyBytes), nil
+	case multicodecX25519Pub:
+		return ecdh.X25519().NewPublicKey(keyBytes)
+	case multicodecP256Pub:
+		if x, y := elliptic.UnmarshalCompressed(elliptic.P256(), keyBytes); x == nil {
+			return nil, errors.New("invalid P-256 public key")
+		} else {
+			return &ecdsa.PublicKey{Curve: elliptic.P256(), X: x, Y: y}, nil
+		}
+	case multicodecSecp256k1Pub:
+		// did:key identifiers contain compressed secp256k1 public keys, which ParsePubKey decompresses
+		if len(keyBytes) != secp256k1.PubKeyBytesLenCompressed {
+			return nil, fmt.Errorf("invalid secp256k1 public key length: %d", len(keyBytes))
+		}
+		return secp256k1.ParsePubKey(keyBytes)
+	default:
+		return nil, fmt.Errorf("unsupported did:key public key type: 0x%x", code)
+	}
+}
+
+// ResolveSigningKey always fails, since did:key identifiers only contain public keys.
+func (DIDKeyResolver) ResolveSigningKey(context.Context, map[string]interface{}) (crypto.PrivateKey, error) {
+	return nil, errors.New("did:key identifiers do not contain signing keys")
+}
+
+// ResolveEncryptionKey returns the public key identified by the `kid` header parameter, which must be usable for ECDH-ES
+// key agreement, i.e. an X25519 or P-256 key.
+func (DIDKeyResolver) ResolveEncryptionKey(_ context.Context, header map[string]interface{}) (crypto.PublicKey, error) {
+	kid, _ := header["kid"].(string)
+	if key, err := ParseDIDKey(kid); err != nil {
+		return nil, err
+	} else if _, err := ecdhPublicKey(key); err != nil {
+		return nil, fmt.Errorf("did:key cannot be used for key agreement: %w", err)
+	} else {
+		return key, nil
+	}
+}
+
+// ResolveDecryptionKey always fails, since did:key identifiers only contain public keys.
+func (DIDKeyResolver) ResolveDecryptionKey(context.Context, map[string]interface{}) (crypto.PrivateKey, error) {
+	return nil, errors.New("did:key identifiers do not contain decryption keys")
+}
@@ -0,0 +1,105 @@
+package dagjose
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"testing"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	gojose "github.com/go-jose/go-jose/v4"
+	"github.com/multiformats/go-multibase"
+	"github.com/multiformats/go-varint"
+	"github.com/stretchr/testify/require"
+	"pgregory.net/rapid"
+)
+
+func didKey(t require.TestingT, code uint64, keyBytes []byte) string {
+	identifier, err := multibase.Encode(multibase.Base58BTC, append(varint.ToUvarint(code), keyBytes...))
+	require.NoError(t, err)
+	return didKeyPrefix + identifier
+}
+
+func TestParseDIDKey(t *testing.T) {
+	edKey := ed25519PrivateKeyGen().Example()
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	secpKey, err := secp256k1.GeneratePrivateKey()
+	require.NoError(t, err)
+	xKey, err := ecdh.X25519().GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	scenarios := []struct {
+		did      string
+		prefix   string
+		expected interface{ Equal(x crypto.PublicKey) bool }
+	}{
+		{didKey(t, multicodecEd25519Pub, edKey.Public().(ed25519.PublicKey)), "did:key:z6Mk", edKey.Public().(ed25519.PublicKey)},
+		{didKey(t, multicodecP256Pub, elliptic.MarshalCompressed(elliptic.P256(), ecKey.X, ecKey.Y)), "did:key:zDn", &ecKey.PublicKey},
+		{didKey(t, multicodecX25519Pub, xKey.PublicKey().Bytes()), "did:key:z6LS", xKey.PublicKey()},
+	}
+	for _, scenario := range scenarios {
+		require.Contains(t, scenario.did, scenario.prefix)
+		key, err := ParseDIDKey(scenario.did + "#fragment")
+		require.NoError(t, err)
+		require.True(t, scenario.expected.Equal(key), scenario.did)
+	}
+
+	secpDID := didKey(t, multicodecSecp256k1Pub, secpKey.PubKey().SerializeCompressed())
+	require.Contains(t, secpDID, "did:key:zQ3s")
+	key, err := ParseDIDKey(secpDID)
+	require.NoError(t, err)
+	require.True(t, secpKey.PubKey().IsEqual(key.(*secp256k1.PublicKey)))
+
+	// Test vector from the did:key specification
+	key, err = ParseDIDKey("did:key:zQ3shokFTS3brHcDQrn82RUDfCZESWL1ZdCEJwekUDPQiYBme")
+	require.NoError(t, err)
+	x, y := key.(*secp256k1.PublicKey).X().Bytes(), key.(*secp256k1.PublicKey).Y().Bytes()
+	require.Equal(t, "h0wVx_2iDlOcblulc8E5iEw1EYh5n1RYtLQfeSTyNc0", encodeBase64Url(x))
+	require.Equal(t, "O2EATIGbu6DezKFptj5scAIRntgfecanVNXxat1rnwE", encodeBase64Url(y))
+
+	for _, invalid := range []string{
+		"did:web:example.com",
+		"did:key:invalid",
+		didKey(t, multicodecEd25519Pub, []byte{1, 2, 3}),
+		didKey(t, multicodecSecp256k1Pub, secpKey.PubKey().SerializeUncompressed()),
+		didKey(t, 0x12, make([]byte, 32)),
+	} {
+		_, err := ParseDIDKey(invalid)
+		require.Error(t, err, invalid)
+	}
+}
+
+// A JWS signed with a did:key `kid` should be verified by the did:key resolver
+func TestVerifyJWSWithDIDKeyResolver(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		link := cidGen().Draw(t, "payload")
+		privateKey := ed25519PrivateKeyGen().Draw(t, "private key")
+		did := didKey(t, multicodecEd25519Pub, privateKey.Public().(ed25519.PublicKey))
+		jws, err := SignCID(link, Signer{Algorithm: gojose.EdDSA, Key: privateKey, KeyID: did + "#" + did[len(didKeyPrefix):]})
+		require.NoError(t, err)
+		results, err := VerifyJWSWithResolver(context.Background(), roundTripJWS(t, jws), DIDKeyResolver{})
+		require.NoError(t, err)
+		require.True(t, results[0].Valid(), "%v", results[0].Err)
+	})
+
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	did := didKey(t, multicodecP256Pub, elliptic.MarshalCompressed(elliptic.P256(), ecKey.X, ecKey.Y))
+	jws, err := SignCID(createCid([]byte("payload")), Signer{Algorithm: gojose.ES256, Key: ecKey, KeyID: did})
+	require.NoError(t, err)
+	results, err := VerifyJWSWithResolver(context.Background(), roundTripJWS(t, jws), DIDKeyResolver{})
+	require.NoError(t, err)
+	require.True(t, results[0].Valid(), "%v", results[0].Err)
+
+	// A signature without a did:key `kid` cannot be resolved
+	jws, err = SignCID(createCid([]byte("payload")), Signer{Algorithm: gojose.ES256, Key: ecKey})
+	require.NoError(t, err)
+	results, err = VerifyJWSWithResolver(context.Background(), roundTripJWS(t, jws), DIDKeyResolver{})
+	require.NoError(t, err)
+	require.False(t, results[0].Valid())
+}
@@ -1,16 +1,14 @@
 package dagjose
 
 import (
-	"errors"
+	"fmt"
 	"io"
 
-	"github.com/ipfs/go-cid"
 	"github.com/ipld/go-ipld-prime/codec"
 	"github.com/ipld/go-ipld-prime/codec/dagcbor"
 	"github.com/ipld/go-ipld-prime/datamodel"
 	"github.com/ipld/go-ipld-prime/linking/cid"
 	"github.com/ipld/go-ipld-prime/schema"
-	"github.com/multiformats/go-multibase"
 )
 
 // Encode walks the given datamodel.Node and serializes it to the given io.Writer. Encode fits the codec.Encoder
@@ -37,7 +35,7 @@ func Encode(n datamodel.Node, w io.Writer) error {
 			return err
 		}
 	} else {
-		return errors.New("invalid JOSE object")
+		return ErrNotJOSE
 	}
 	return nil
 }
@@ -49,7 +47,7 @@ func EncodeJWE(n datamodel.Node, w io.Writer) error {
 		if _, castOk := n.(*_EncodedJWE); !castOk {
 			// No fastpath possible, just create a new `_EncodedJWE__ReprBuilder` and copy the passed node into it.
 			jweBuilder := Type.EncodedJWE__Repr.NewBuilder().(*_EncodedJWE__ReprBuilder)
-			if err := datamodel.Copy(n, jweBuilder); err != nil {
+			if err := assembleJOSE("JWE", n, jweBuilder); err != nil {
 				return err
 			}
 			n = jweBuilder.Build()
@@ -60,6 +58,7 @@ func EncodeJWE(n datamodel.Node, w io.Writer) error {
 	// DAG-CBOR is a superset of DAG-JOSE and can be used to encode valid DAG-JOSE objects.
 	// See: https://specs.ipld.io/block-layer/codecs/dag-jose.html
 	return dagcbor.EncodeOptions{
+		AllowLinks:  true,
 		MapSortMode: codec.MapSortMode_RFC7049,
 	}.Encode(n, w)
 }
@@ -75,7 +74,7 @@ func EncodeJWS(n datamodel.Node, w io.Writer) error {
 			}
 			// No fastpath possible, just create a new `_EncodedJWS__ReprBuilder` and copy the passed node into it.
 			jwsBuilder := Type.EncodedJWS__Repr.NewBuilder().(*_EncodedJWS__ReprBuilder)
-			if err := datamodel.Copy(n, jwsBuilder); err != nil {
+			if err := assembleJOSE("JWS", n, jwsBuilder); err != nil {
 				return err
 			}
 			n = jwsBuilder.Build()
@@ -86,6 +85,7 @@ func EncodeJWS(n datamodel.Node, w io.Writer) error {
 	// DAG-CBOR is a superset of DAG-JOSE and can be used to encode valid DAG-JOSE objects.
 	// See: https://specs.ipld.io/block-layer/codecs/dag-jose.html
 	return dagcbor.EncodeOptions{
+		AllowLinks:  true,
 		MapSortMode: codec.MapSortMode_RFC7049,
 	}.Encode(n, w)
 }
@@ -97,15 +97,17 @@ func validateLink(n datamodel.Node) error {
 		// If `link` was present then `payload` must be present and the two must match. If any error occurs here
 		// (including `payload` being absent) return it.
 		if payloadNode, err := n.LookupByString("payload"); err != nil {
-			return err
+			return &ErrInvalidSerialization{Object: "JWS", Field: "payload", Reason: "missing", Err: err}
 		} else if payloadString, err := payloadNode.AsString(); err != nil {
-			return err
-		} else if cidFromPayload, err := cid.Decode(string(multibase.Base64url) + payloadString); err != nil {
+			return &ErrInvalidSerialization{Object: "JWS", Field: "payload", Err: err}
+		} else if cidFromPayload, err := encodedPayloadCID(payloadString); err != nil {
 			return err
 		} else if linkFromNode, err := linkNode.AsLink(); err != nil {
-			return err
-		} else if linkFromNode.(cidlink.Link).Cid != cidFromPayload {
-			return errors.New("cid mismatch")
+			return &ErrInvalidSerialization{Object: "JWS", Field: "link", Err: err}
+		} else if cl, castOk := linkFromNode.(cidlink.Link); !castOk {
+			return &ErrInvalidSerialization{Object: "JWS", Field: "link", Reason: fmt.Sprintf("unsupported link type %T", linkFromNode)}
+		} else if cl.Cid != cidFromPayload {
+			return &ErrLinkMismatch{Link: cl.Cid, Payload: cidFromPayload}
 		}
 	}
 	return nil
@@ -0,0 +1,356 @@
+package dagjose
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/aes"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"errors"
+	"fmt"
+
+	gojose "github.com/go-jose/go-jose/v4"
+	josecipher "github.com/go-jose/go-jose/v4/cipher"
+	"github.com/go-jose/go-jose/v4/json"
+	"github.com/ipld/go-ipld-prime/codec/dagcbor"
+	"github.com/ipld/go-ipld-prime/datamodel"
+	"github.com/ipld/go-ipld-prime/fluent"
+	"github.com/ipld/go-ipld-prime/node/basicnode"
+	"github.com/ipld/go-ipld-prime/schema"
+)
+
+// Recipient describes a public or shared key for which the content of a JWE is encrypted.
+type Recipient struct {
+	// Algorithm is the JWE key management `alg` used for this recipient, e.g. ECDH-ES+A256KW.
+	Algorithm gojose.KeyAlgorithm
+	// Key is the key of the recipient. Symmetric keys (for `dir` and AES key wrap) are []byte, while keys for ECDH-ES
+	// key agreement are *ecdh.PublicKey or *ecdsa.PublicKey. Any of these may also be wrapped in a go-jose JSONWebKey.
+	Key crypto.PublicKey
+	// KeyID, if not empty, is added to the recipient's header parameters as `kid`. If empty and Key is a JSONWebKey
+	// with a key ID, that key ID is used instead.
+	KeyID string
+	// Header contains additional unprotected header parameters for this recipient, if any.
+	Header map[string]interface{}
+}
+
+// EncryptOptions can be used to customize the JWE produced by EncryptNode.
+type EncryptOptions struct {
+	// Encryption is the JWE content encryption `enc`. If empty, A256GCM is used.
+	Encryption gojose.ContentEncryption
+	// Protected contains additional protected header parameters. It must not contain `enc`, which is set from
+	// Encryption, or any parameter set by EncryptNode for the recipients, such as `alg`, `kid` or `epk`.
+	Protected map[string]interface{}
+	// Unprotected contains shared unprotected header parameters, if any. As required by RFC 7516, these must be
+	// distinct from the protected and per-recipient header parameters.
+	// See: https://datatracker.ietf.org/doc/html/rfc7516#section-7.2.1
+	Unprotected map[string]interface{}
+	// AAD is additional authenticated data that is integrity protected but not encrypted, if any.
+	AAD []byte
+	// BlockSize, if greater than zero, pads the cleartext with zero bytes to a multiple of this size in order to hide
+	// its exact length.
+	BlockSize int
+}
+
+// EncryptNode serializes the given node as DAG-CBOR, encrypts it for each of the given recipients and returns the
+// resulting JWE, ready to be stored with the dag-jose codec. The cleartext can be recovered with DecryptJWE.
+//
+// As recommended by the DAG-JOSE spec, a bare link is wrapped in a map as `{ "_": CID }` before it is encrypted, since
+// the cleartext must be a DAG-CBOR map or list.
+//
+// When there is a single recipient, its `alg`, `kid` and `epk` header parameters are integrity protected along with
+// `enc`, otherwise they are placed in each recipient's unprotected header. The `dir` and `ECDH-ES` algorithms, which
+// determine the content encryption key directly, can only be used with a single recipient.
+func EncryptNode(n datamodel.Node, opts EncryptOptions, recipients ...Recipient) (EncodedJWE, error) {
+	if len(recipients) == 0 {
+		return nil, errors.New("at least one recipient is required")
+	}
+	enc := opts.Encryption
+	if enc == "" {
+		enc = gojose.A256GCM
+	}
+	cleartext, err := encodeCleartext(n, opts.BlockSize)
+	if err != nil {
+		return nil, err
+	}
+	keySize, err := contentKeySize(enc)
+	if err != nil {
+		return nil, err
+	}
+
+	// Determine the content encryption key, and the parameters and encrypted key for each recipient
+	var cek []byte
+	if len(recipients) == 1 {
+		switch recipients[0].Algorithm {
+		case gojose.DIRECT, gojose.ECDH_ES:
+			// The key is determined by the recipient
+		default:
+			cek = make([]byte, keySize)
+		}
+	} else {
+		cek = make([]byte, keySize)
+	}
+	if cek != nil {
+		if _, err := rand.Read(cek); err != nil {
+			return nil, err
+		}
+	}
+	recipientHeaders := make([]map[string]interface{}, len(recipients))
+	encryptedKeys := make([][]byte, len(recipients))
+	for idx, recipient := range recipients {
+		if params, encryptedKey, derivedKey, err := recipient.wrapContentKey(enc, cek); err != nil {
+			return nil, fmt.Errorf("recipient %d: %w", idx, err)
+		} else {
+			recipientHeaders[idx] = params
+			encryptedKeys[idx] = encryptedKey
+			if derivedKey != nil {
+				if len(recipients) > 1 {
+					return nil, fmt.Errorf("recipient %d: %s cannot be used with multiple recipients", idx, recipient.Algorithm)
+				}
+				cek = derivedKey
+			}
+		}
+	}
+
+	protected := make(map[string]interface{}, len(opts.Protected)+4)
+	for k, v := range opts.Protected {
+		protected[k] = v
+	}
+	if _, found := protected["enc"]; found {
+		return nil, errors.New("duplicate header parameter: enc")
+	}
+	protected["enc"] = string(enc)
+	if len(recipients) == 1 {
+		for k, v := range recipientHeaders[0] {
+			if _, found := protected[k]; found {
+				return nil, fmt.Errorf("duplicate header parameter: %s", k)
+			}
+			protected[k] = v
+		}
+		recipientHeaders[0] = nil
+	}
+	for k := range opts.Unprotected {
+		if _, found := protected[k]; found {
+			return nil, fmt.Errorf("duplicate header parameter: %s", k)
+		}
+	}
+	protectedBytes, err := json.Marshal(protected)
+	if err != nil {
+		return nil, err
+	}
+
+	// Encrypt the cleartext
+	aead, err := contentCipher(enc, cek)
+	if err != nil {
+		return nil, err
+	}
+	iv := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(iv); err != nil {
+		return nil, err
+	}
+	jwe := &_EncodedJWE{
+		iv:        _Raw__Maybe{m: schema.Maybe_Value, v: _Raw{iv}},
+		protected: _Raw__Maybe{m: schema.Maybe_Value, v: _Raw{protectedBytes}},
+	}
+	if len(opts.AAD) > 0 {
+		jwe.aad = _Raw__Maybe{m: schema.Maybe_Value, v: _Raw{opts.AAD}}
+	}
+	authData := encodeBase64Url(protectedBytes)
+	if jwe.aad.Exists() {
+		authData += "." + encodeBase64Url(opts.AAD)
+	}
+	sealed := aead.Seal(nil, iv, cleartext, []byte(authData))
+	tagSize := contentTagSize(enc)
+	jwe.ciphertext = _Raw{sealed[:len(sealed)-tagSize]}
+	jwe.tag = _Raw__Maybe{m: schema.Maybe_Value, v: _Raw{sealed[len(sealed)-tagSize:]}}
+
+	// Assemble the recipients
+	encodedRecipients := make([]_EncodedRecipient, 0, len(recipients))
+	for idx, recipient := range recipients {
+		header := recipientHeaders[idx]
+		if len(recipient.Header) > 0 && header == nil {
+			header = make(map[string]interface{}, len(recipient.Header))
+		}
+		for k, v := range recipient.Header {
+			if _, found := header[k]; found {
+				return nil, fmt.Errorf("recipient %d: duplicate header parameter: %s", idx, k)
+			}
+			header[k] = v
+		}
+		for k := range header {
+			_, inProtected := protected[k]
+			_, inUnprotected := opts.Unprotected[k]
+			if inProtected || inUnprotected {
+				return nil, fmt.Errorf("recipient %d: duplicate header parameter: %s", idx, k)
+			}
+		}
+		encodedRecipient := _EncodedRecipient{}
+		if len(header) > 0 {
+			if headerNode, err := maybeAny(header); err != nil {
+				return nil, err
+			} else {
+				encodedRecipient.header = headerNode
+			}
+		}
+		if len(encryptedKeys[idx]) > 0 {
+			encodedRecipient.encrypted_key = _Raw__Maybe{m: schema.Maybe_Value, v: _Raw{encryptedKeys[idx]}}
+		}
+		encodedRecipients = append(encodedRecipients, encodedRecipient)
+	}
+	// A lone recipient without any header parameters or encrypted key (i.e. `dir`) is omitted altogether
+	if len(encodedRecipients) > 1 || encodedRecipients[0].header.Exists() || encodedRecipients[0].encrypted_key.Exists() {
+		jwe.recipients = _EncodedRecipients__Maybe{m: schema.Maybe_Value, v: _EncodedRecipients{encodedRecipients}}
+	}
+	if len(opts.Unprotected) > 0 {
+		if unprotected, err := maybeAny(opts.Unprotected); err != nil {
+			return nil, err
+		} else {
+			jwe.unprotected = unprotected
+		}
+	}
+	return jwe, nil
+}
+
+// wrapContentKey returns the header parameters and encrypted key for the recipient. For `dir` and `ECDH-ES`, where the
+// recipient determines the content encryption key, that key is returned instead of an encrypted key.
+// See: https://datatracker.ietf.org/doc/html/rfc7516#section-5.1
+func (r Recipient) wrapContentKey(enc gojose.ContentEncryption, cek []byte) (map[string]interface{}, []byte, []byte, error) {
+	key, kid := r.Key, r.KeyID
+	if jwk, castOk := key.(gojose.JSONWebKey); castOk {
+		key = jwk.Key
+		if kid == "" {
+			kid = jwk.KeyID
+		}
+	} else if jwk, castOk := key.(*gojose.JSONWebKey); castOk {
+		key = jwk.Key
+		if kid == "" {
+			kid = jwk.KeyID
+		}
+	}
+	params := map[string]interface{}{"alg": string(r.Algorithm)}
+	if kid != "" {
+		params["kid"] = kid
+	}
+	switch r.Algorithm {
+	case gojose.DIRECT:
+		if sharedKey, castOk := key.([]byte); !castOk {
+			return nil, nil, nil, fmt.Errorf("invalid key type for %s: %T", r.Algorithm, key)
+		} else {
+			return params, nil, sharedKey, nil
+		}
+	case gojose.A128KW, gojose.A192KW, gojose.A256KW:
+		if kek, castOk := key.([]byte); !castOk {
+			return nil, nil, nil, fmt.Errorf("invalid key type for %s: %T", r.Algorithm, key)
+		} else if encryptedKey, err := aesKeyWrap(kek, cek); err != nil {
+			return nil, nil, nil, err
+		} else {
+			return params, encryptedKey, nil, nil
+		}
+	case gojose.ECDH_ES, gojose.ECDH_ES_A128KW, gojose.ECDH_ES_A192KW, gojose.ECDH_ES_A256KW:
+		publicKey, err := ecdhPublicKey(key)
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("invalid key type for %s: %w", r.Algorithm, err)
+		}
+		ephemeralKey, err := publicKey.Curve().GenerateKey(rand.Reader)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		z, err := ephemeralKey.ECDH(publicKey)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		params["epk"] = ecdhPublicKeyToJWK(ephemeralKey.PublicKey())
+		if r.Algorithm == gojose.ECDH_ES {
+			keySize, _ := contentKeySize(enc)
+			return params, nil, concatKDF(z, string(enc), nil, nil, keySize), nil
+		}
+		if encryptedKey, err := aesKeyWrap(concatKDF(z, string(r.Algorithm), nil, nil, keyWrapSize(r.Algorithm)), cek); err != nil {
+			return nil, nil, nil, err
+		} else {
+			return params, encryptedKey, nil, nil
+		}
+	default:
+		return nil, nil, nil, fmt.Errorf("unsupported key management algorithm: %s", r.Algorithm)
+	}
+}
+
+func aesKeyWrap(kek []byte, cek []byte) ([]byte, error) {
+	if block, err := aes.NewCipher(kek); err != nil {
+		return nil, err
+	} else {
+		return josecipher.KeyWrap(block, cek)
+	}
+}
+
+// contentTagSize returns the size in bytes of the authentication tag produced by the given algorithm.
+func contentTagSize(enc gojose.ContentEncryption) int {
+	switch enc {
+	case gojose.A128CBC_HS256:
+		return 16
+	case gojose.A192CBC_HS384:
+		return 24
+	case gojose.A256CBC_HS512:
+		return 32
+	default:
+		return 16
+	}
+}
+
+func ecdhPublicKey(key crypto.PublicKey) (*ecdh.PublicKey, error) {
+	switch k := key.(type) {
+	case *ecdh.PublicKey:
+		return k, nil
+	case *ecdsa.PublicKey:
+		return k.ECDH()
+	default:
+		return nil, fmt.Errorf("%T", key)
+	}
+}
+
+// ecdhPublicKeyToJWK returns the members of the public JWK corresponding to the given key.
+func ecdhPublicKeyToJWK(publicKey *ecdh.PublicKey) map[string]interface{} {
+	curve := publicKey.Curve()
+	point := publicKey.Bytes()
+	if curve == ecdh.X25519() {
+		return map[string]interface{}{
+			"kty": "OKP",
+			"crv": curveName(curve),
+			"x":   encodeBase64Url(point),
+		}
+	}
+	// Split the uncompressed point encoding into its coordinates
+	coordinateSize := (len(point) - 1) / 2
+	return map[string]interface{}{
+		"kty": "EC",
+		"crv": curveName(curve),
+		"x":   encodeBase64Url(point[1 : 1+coordinateSize]),
+		"y":   encodeBase64Url(point[1+coordinateSize:]),
+	}
+}
+
+// encodeCleartext serializes a node as DAG-CBOR for encryption, wrapping bare links and padding the result to the given
+// block size.
+func encodeCleartext(n datamodel.Node, blockSize int) ([]byte, error) {
+	if tn, castOk := n.(schema.TypedNode); castOk {
+		n = tn.Representation()
+	}
+	if n.Kind() == datamodel.Kind_Link {
+		if link, err := n.AsLink(); err != nil {
+			return nil, err
+		} else {
+			n = fluent.MustBuildMap(basicnode.Prototype.Map, 1, func(ma fluent.MapAssembler) {
+				ma.AssembleEntry("_").AssignLink(link)
+			})
+		}
+	}
+	buf := bytes.Buffer{}
+	if err := dagcbor.Encode(n, &buf); err != nil {
+		return nil, err
+	}
+	if blockSize > 0 {
+		if remainder := buf.Len() % blockSize; remainder != 0 {
+			buf.Write(make([]byte, blockSize-remainder))
+		}
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,138 @@
+package dagjose
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"testing"
+
+	gojose "github.com/go-jose/go-jose/v4"
+	"github.com/ipld/go-ipld-prime/datamodel"
+	"github.com/ipld/go-ipld-prime/linking/cid"
+	"github.com/ipld/go-ipld-prime/node/basicnode"
+	"github.com/stretchr/testify/require"
+)
+
+// Encode the JWE to DAG-JOSE bytes and back, returning the decoded node
+func roundTripJWE(t *testing.T, jwe EncodedJWE) datamodel.Node {
+	buf := bytes.Buffer{}
+	require.NoError(t, Encode(jwe, &buf))
+	nb := basicnode.Prototype.Any.NewBuilder()
+	require.NoError(t, Decode(nb, &buf))
+	return nb.Build()
+}
+
+func TestEncryptNodeRoundTrip(t *testing.T) {
+	x25519Key, err := ecdh.X25519().GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	symmetricKey := make([]byte, 32)
+	_, err = rand.Read(symmetricKey)
+	require.NoError(t, err)
+
+	scenarios := []struct {
+		name       string
+		enc        gojose.ContentEncryption
+		recipient  Recipient
+		privateKey crypto.PrivateKey
+	}{
+		{"X25519 ECDH-ES+A256KW XC20P", XC20P, Recipient{Algorithm: gojose.ECDH_ES_A256KW, Key: x25519Key.PublicKey()}, x25519Key},
+		{"X25519 ECDH-ES XC20P", XC20P, Recipient{Algorithm: gojose.ECDH_ES, Key: x25519Key.PublicKey()}, x25519Key},
+		{"P-256 ECDH-ES+A256KW A256GCM", gojose.A256GCM, Recipient{Algorithm: gojose.ECDH_ES_A256KW, Key: &ecKey.PublicKey}, ecKey},
+		{"A256KW A128CBC-HS256", gojose.A128CBC_HS256, Recipient{Algorithm: gojose.A256KW, Key: symmetricKey}, symmetricKey},
+		{"dir A256GCM", gojose.A256GCM, Recipient{Algorithm: gojose.DIRECT, Key: symmetricKey}, symmetricKey},
+	}
+	for _, scenario := range scenarios {
+		t.Run(scenario.name, func(t *testing.T) {
+			jwe, err := EncryptNode(cleartextNode(), EncryptOptions{Encryption: scenario.enc, BlockSize: 24}, scenario.recipient)
+			require.NoError(t, err)
+			requireDecryptsToCleartext(t, roundTripJWE(t, jwe), staticKey(scenario.privateKey))
+		})
+	}
+}
+
+func TestEncryptNodeMultipleRecipients(t *testing.T) {
+	first, err := ecdh.X25519().GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	second, err := ecdh.X25519().GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	jwe, err := EncryptNode(cleartextNode(), EncryptOptions{Encryption: XC20P, AAD: []byte("additional data")},
+		Recipient{Algorithm: gojose.ECDH_ES_A256KW, Key: first.PublicKey(), KeyID: "first"},
+		Recipient{Algorithm: gojose.ECDH_ES_A256KW, Key: second.PublicKey(), KeyID: "second"},
+	)
+	require.NoError(t, err)
+	require.Equal(t, int64(2), jwe.FieldRecipients().Must().Length())
+
+	decoded := roundTripJWE(t, jwe)
+	for kid, key := range map[string]*ecdh.PrivateKey{"first": first, "second": second} {
+		resolver := NewMemoryKeyResolver()
+		resolver.Add(kid, key)
+		requireDecryptsToCleartext(t, decoded, resolver)
+	}
+
+	// Direct key agreement cannot be shared between recipients
+	_, err = EncryptNode(cleartextNode(), EncryptOptions{},
+		Recipient{Algorithm: gojose.ECDH_ES, Key: first.PublicKey()},
+		Recipient{Algorithm: gojose.ECDH_ES, Key: second.PublicKey()},
+	)
+	require.Error(t, err)
+}
+
+// A bare link should be wrapped as `{ "_": CID }`
+func TestEncryptNodeWrapsLink(t *testing.T) {
+	key, err := ecdh.X25519().GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	link := cidlink.Link{Cid: createCid([]byte("secret"))}
+	jwe, err := EncryptNode(basicnode.NewLink(link), EncryptOptions{Encryption: XC20P},
+		Recipient{Algorithm: gojose.ECDH_ES_A256KW, Key: key.PublicKey()})
+	require.NoError(t, err)
+
+	nb := basicnode.Prototype.Any.NewBuilder()
+	require.NoError(t, DecryptJWE(context.Background(), roundTripJWE(t, jwe), staticKey(key), nb))
+	wrapped, err := nb.Build().LookupByString("_")
+	require.NoError(t, err)
+	decryptedLink, err := wrapped.AsLink()
+	require.NoError(t, err)
+	require.Equal(t, link.String(), decryptedLink.String())
+}
+
+// Header parameters must not be silently overwritten or repeated across the protected and unprotected headers
+func TestEncryptNodeRejectsDuplicateHeaderParameters(t *testing.T) {
+	first, err := ecdh.X25519().GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	second, err := ecdh.X25519().GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	single := []Recipient{{Algorithm: gojose.ECDH_ES_A256KW, Key: first.PublicKey(), KeyID: "first"}}
+	multiple := []Recipient{
+		{Algorithm: gojose.ECDH_ES_A256KW, Key: first.PublicKey(), KeyID: "first"},
+		{Algorithm: gojose.ECDH_ES_A256KW, Key: second.PublicKey(), KeyID: "second"},
+	}
+	scenarios := []struct {
+		opts       EncryptOptions
+		recipients []Recipient
+	}{
+		{EncryptOptions{Protected: map[string]interface{}{"enc": "A128GCM"}}, single},
+		{EncryptOptions{Protected: map[string]interface{}{"alg": "dir"}}, single},
+		{EncryptOptions{Protected: map[string]interface{}{"kid": "other"}}, single},
+		{EncryptOptions{Protected: map[string]interface{}{"epk": map[string]interface{}{}}}, single},
+		{EncryptOptions{Protected: map[string]interface{}{"kid": "other"}}, multiple},
+		{EncryptOptions{Protected: map[string]interface{}{"typ": "JWE"}, Unprotected: map[string]interface{}{"typ": "JWE"}}, single},
+		{EncryptOptions{Unprotected: map[string]interface{}{"enc": "A128GCM"}}, single},
+		{EncryptOptions{Unprotected: map[string]interface{}{"kid": "other"}}, multiple},
+	}
+	for _, scenario := range scenarios {
+		_, err := EncryptNode(cleartextNode(), scenario.opts, scenario.recipients...)
+		require.ErrorContains(t, err, "duplicate header parameter", "%v", scenario.opts)
+	}
+
+	_, err = EncryptNode(cleartextNode(), EncryptOptions{
+		Protected:   map[string]interface{}{"typ": "JWE"},
+		Unprotected: map[string]interface{}{"note": "shared"},
+	}, multiple...)
+	require.NoError(t, err)
+}
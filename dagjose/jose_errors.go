@@ -0,0 +1,86 @@
+package dagjose
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/ipfs/go-cid"
+)
+
+// ErrNotJOSE is returned when a node or block is neither a JWS nor a JWE, i.e. it has neither a `payload` nor a
+// `ciphertext` field, or has both.
+var ErrNotJOSE = errors.New("invalid JOSE object")
+
+// ErrPayloadNotCID is returned when the payload of a JWS is not a valid CID, which DAG-JOSE requires.
+var ErrPayloadNotCID = errors.New("payload is not a valid CID")
+
+// ErrInvalidSerialization is returned when a field of a JWS or JWE is missing, has an invalid value, or is not allowed
+// in the serialization it appears in.
+type ErrInvalidSerialization struct {
+	// Object is the kind of object, e.g. "JWS", "JWE" or "compact JWS".
+	Object string
+	// Field is the path of the offending field, e.g. "payload" or "signatures/0/protected", or empty if the error is
+	// not specific to a field.
+	Field string
+	// Reason describes the problem with the field.
+	Reason string
+	// Err is the underlying error, if any.
+	Err error
+}
+
+func (e *ErrInvalidSerialization) Error() string {
+	msg := fmt.Sprintf("invalid %s serialization", e.Object)
+	if e.Field != "" {
+		msg += fmt.Sprintf(": `%s`", e.Field)
+	}
+	if e.Reason != "" {
+		msg += ": " + e.Reason
+	}
+	if e.Err != nil {
+		msg += ": " + e.Err.Error()
+	}
+	return msg
+}
+
+func (e *ErrInvalidSerialization) Unwrap() error {
+	return e.Err
+}
+
+// ErrLinkMismatch is returned when the `link` of a JWS does not match its payload.
+type ErrLinkMismatch struct {
+	// Link is the CID from the `link` field, or the CID that was expected to match the payload.
+	Link cid.Cid
+	// Payload is the CID from the `payload` field.
+	Payload cid.Cid
+}
+
+func (e *ErrLinkMismatch) Error() string {
+	return fmt.Sprintf("cid mismatch: link %s does not match payload %s", e.Link, e.Payload)
+}
+
+// payloadCID parses the payload of a JWS as a CID, returning an error wrapping ErrPayloadNotCID if it is not one.
+func payloadCID(payload []byte) (cid.Cid, error) {
+	if c, err := cid.Cast(payload); err != nil {
+		return cid.Undef, fmt.Errorf("%w: %v", ErrPayloadNotCID, err)
+	} else {
+		return c, nil
+	}
+}
+
+// encodedPayloadCID is like payloadCID, for a base64url-encoded payload.
+func encodedPayloadCID(payload string) (cid.Cid, error) {
+	if payloadBytes, err := decodeBase64Url(payload); err != nil {
+		return cid.Undef, fmt.Errorf("%w: %v", ErrPayloadNotCID, err)
+	} else {
+		return payloadCID(payloadBytes)
+	}
+}
+
+// assemblerStateError is returned instead of panicking when an assembler is used out of order, e.g. when assigning into
+// an assembler that's already finished. The assemblers generated in ipldsch_satisfaction.go are patched by gen.go to
+// return it, like those of Base64Url and Raw.
+type assemblerStateError string
+
+func (e assemblerStateError) Error() string {
+	return string(e)
+}
@@ -0,0 +1,190 @@
+package dagjose
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/ipld/go-ipld-prime"
+	"github.com/ipld/go-ipld-prime/codec/dagcbor"
+	"github.com/ipld/go-ipld-prime/datamodel"
+	"github.com/ipld/go-ipld-prime/fluent"
+	cidlink "github.com/ipld/go-ipld-prime/linking/cid"
+	"github.com/ipld/go-ipld-prime/node/basicnode"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncodeNonJOSEReturnsErrNotJOSE(t *testing.T) {
+	n := fluent.MustBuildMap(basicnode.Prototype.Map, 1, func(ma fluent.MapAssembler) {
+		ma.AssembleEntry("foo").AssignString("bar")
+	})
+	err := Encode(n, &bytes.Buffer{})
+	require.ErrorIs(t, err, ErrNotJOSE)
+}
+
+func TestDecodeNonJOSEReturnsErrNotJOSE(t *testing.T) {
+	n := fluent.MustBuildMap(basicnode.Prototype.Map, 1, func(ma fluent.MapAssembler) {
+		ma.AssembleEntry("foo").AssignString("bar")
+	})
+	buf := bytes.Buffer{}
+	require.NoError(t, dagcbor.Encode(n, &buf))
+	err := Decode(basicnode.Prototype.Any.NewBuilder(), &buf)
+	require.ErrorIs(t, err, ErrNotJOSE)
+}
+
+func TestLinkMismatchReturnsErrLinkMismatch(t *testing.T) {
+	payload, link := createCid([]byte("payload")), createCid([]byte("other"))
+	n := fluent.MustBuildMap(basicnode.Prototype.Map, 3, func(ma fluent.MapAssembler) {
+		ma.AssembleEntry("payload").AssignString(encodeBase64Url(payload.Bytes()))
+		ma.AssembleEntry("signatures").CreateList(1, func(la fluent.ListAssembler) {
+			la.AssembleValue().CreateMap(1, func(ma fluent.MapAssembler) {
+				ma.AssembleEntry("signature").AssignString(encodeBase64Url([]byte("signature")))
+			})
+		})
+		ma.AssembleEntry("link").AssignLink(cidlink.Link{Cid: link})
+	})
+	err := EncodeJWS(n, &bytes.Buffer{})
+	var mismatch *ErrLinkMismatch
+	require.ErrorAs(t, err, &mismatch)
+	require.Equal(t, link, mismatch.Link)
+	require.Equal(t, payload, mismatch.Payload)
+	require.Contains(t, err.Error(), "cid mismatch")
+}
+
+func TestNonCIDPayloadReturnsErrPayloadNotCID(t *testing.T) {
+	n := fluent.MustBuildMap(basicnode.Prototype.Map, 2, func(ma fluent.MapAssembler) {
+		ma.AssembleEntry("payload").AssignString(encodeBase64Url([]byte{0x00}))
+		ma.AssembleEntry("signature").AssignString(encodeBase64Url([]byte("signature")))
+	})
+	err := Encode(n, &bytes.Buffer{})
+	require.ErrorIs(t, err, ErrPayloadNotCID)
+
+	_, err = ParseCompactJWS("eyJhbGciOiJFZERTQSJ9.AA.c2ln")
+	require.ErrorIs(t, err, ErrPayloadNotCID)
+}
+
+func TestDecodeNonCIDPayloadReturnsErrPayloadNotCID(t *testing.T) {
+	n := fluent.MustBuildMap(basicnode.Prototype.Map, 2, func(ma fluent.MapAssembler) {
+		ma.AssembleEntry("payload").AssignBytes([]byte{0x00})
+		ma.AssembleEntry("signatures").CreateList(1, func(la fluent.ListAssembler) {
+			la.AssembleValue().CreateMap(1, func(ma fluent.MapAssembler) {
+				ma.AssembleEntry("signature").AssignBytes([]byte("signature"))
+			})
+		})
+	})
+	buf := bytes.Buffer{}
+	require.NoError(t, dagcbor.Encode(n, &buf))
+	err := Decode(basicnode.Prototype.Any.NewBuilder(), bytes.NewReader(buf.Bytes()))
+	require.ErrorIs(t, err, ErrPayloadNotCID)
+
+	ls := memoryLinkSystem()
+	jwsCid := storeRawJOSE(t, ls, buf.Bytes())
+	_, err = LoadJOSE(cidlink.Link{Cid: jwsCid}, ipld.LinkContext{}, ls)
+	require.ErrorIs(t, err, ErrPayloadNotCID)
+}
+
+func TestInvalidSerializationReportsField(t *testing.T) {
+	payload := encodeBase64Url(createCid([]byte("payload")).Bytes())
+	testCases := []struct {
+		name   string
+		object string
+		field  string
+		node   datamodel.Node
+	}{
+		{
+			name:   "JWS with both flattened and general fields",
+			object: "JWS",
+			field:  "signature",
+			node: fluent.MustBuildMap(basicnode.Prototype.Map, 3, func(ma fluent.MapAssembler) {
+				ma.AssembleEntry("payload").AssignString(payload)
+				ma.AssembleEntry("signature").AssignString(encodeBase64Url([]byte("signature")))
+				ma.AssembleEntry("signatures").CreateList(1, func(la fluent.ListAssembler) {
+					la.AssembleValue().CreateMap(1, func(ma fluent.MapAssembler) {
+						ma.AssembleEntry("signature").AssignString(encodeBase64Url([]byte("signature")))
+					})
+				})
+			}),
+		},
+		{
+			name:   "JWS with a non-string protected header",
+			object: "JWS",
+			field:  "protected",
+			node: fluent.MustBuildMap(basicnode.Prototype.Map, 3, func(ma fluent.MapAssembler) {
+				ma.AssembleEntry("payload").AssignString(payload)
+				ma.AssembleEntry("protected").AssignInt(1)
+				ma.AssembleEntry("signature").AssignString(encodeBase64Url([]byte("signature")))
+			}),
+		},
+		{
+			name:   "JWE with both flattened and general fields",
+			object: "JWE",
+			field:  "encrypted_key",
+			node: fluent.MustBuildMap(basicnode.Prototype.Map, 3, func(ma fluent.MapAssembler) {
+				ma.AssembleEntry("ciphertext").AssignString(encodeBase64Url([]byte("ciphertext")))
+				ma.AssembleEntry("encrypted_key").AssignString(encodeBase64Url([]byte("key")))
+				ma.AssembleEntry("recipients").CreateList(1, func(la fluent.ListAssembler) {
+					la.AssembleValue().CreateMap(1, func(ma fluent.MapAssembler) {
+						ma.AssembleEntry("encrypted_key").AssignString(encodeBase64Url([]byte("key")))
+					})
+				})
+			}),
+		},
+		{
+			name:   "JWS with an invalid protected header in the second signature",
+			object: "JWS",
+			field:  "signatures/1/protected",
+			node: fluent.MustBuildMap(basicnode.Prototype.Map, 2, func(ma fluent.MapAssembler) {
+				ma.AssembleEntry("payload").AssignString(payload)
+				ma.AssembleEntry("signatures").CreateList(2, func(la fluent.ListAssembler) {
+					la.AssembleValue().CreateMap(1, func(ma fluent.MapAssembler) {
+						ma.AssembleEntry("signature").AssignString(encodeBase64Url([]byte("signature")))
+					})
+					la.AssembleValue().CreateMap(2, func(ma fluent.MapAssembler) {
+						ma.AssembleEntry("protected").AssignInt(1)
+						ma.AssembleEntry("signature").AssignString(encodeBase64Url([]byte("signature")))
+					})
+				})
+			}),
+		},
+		{
+			name:   "JWE with an invalid encrypted key in the first recipient",
+			object: "JWE",
+			field:  "recipients/0/encrypted_key",
+			node: fluent.MustBuildMap(basicnode.Prototype.Map, 2, func(ma fluent.MapAssembler) {
+				ma.AssembleEntry("ciphertext").AssignString(encodeBase64Url([]byte("ciphertext")))
+				ma.AssembleEntry("recipients").CreateList(1, func(la fluent.ListAssembler) {
+					la.AssembleValue().CreateMap(1, func(ma fluent.MapAssembler) {
+						ma.AssembleEntry("encrypted_key").AssignString("!!")
+					})
+				})
+			}),
+		},
+		{
+			name:   "JWE with a non-string iv",
+			object: "JWE",
+			field:  "iv",
+			node: fluent.MustBuildMap(basicnode.Prototype.Map, 2, func(ma fluent.MapAssembler) {
+				ma.AssembleEntry("ciphertext").AssignString(encodeBase64Url([]byte("ciphertext")))
+				ma.AssembleEntry("iv").AssignBool(true)
+			}),
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			err := Encode(testCase.node, &bytes.Buffer{})
+			var serializationErr *ErrInvalidSerialization
+			require.ErrorAs(t, err, &serializationErr)
+			require.Equal(t, testCase.object, serializationErr.Object)
+			require.Equal(t, testCase.field, serializationErr.Field)
+			require.False(t, errors.Is(err, ErrNotJOSE))
+		})
+	}
+}
+
+func TestCompactSerializationReportsField(t *testing.T) {
+	_, err := ParseCompactJWE("eyJhbGciOiJkaXIifQ..!!.Y2lwaGVydGV4dA.")
+	var serializationErr *ErrInvalidSerialization
+	require.ErrorAs(t, err, &serializationErr)
+	require.Equal(t, "compact JWE", serializationErr.Object)
+	require.Equal(t, "iv", serializationErr.Field)
+}
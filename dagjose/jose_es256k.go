@@ -0,0 +1,23 @@
+package dagjose
+
+import (
+	"crypto/sha256"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	secp256k1ecdsa "github.com/decred/dcrd/dcrec/secp256k1/v4/ecdsa"
+)
+
+// signES256K returns the 64-byte R||S signature of the signing input. Signatures are deterministic (RFC 6979) and always
+// have a low S value, so that they are accepted by verifiers that reject malleable signatures.
+func signES256K(privateKey *secp256k1.PrivateKey, signingInput []byte) []byte {
+	hash := sha256.Sum256(signingInput)
+	sig := secp256k1ecdsa.Sign(privateKey, hash[:])
+	r, s := sig.R(), sig.S()
+	if s.IsOverHalfOrder() {
+		s.Negate()
+	}
+	signature := make([]byte, 2*secp256k1CoordinateSize)
+	r.PutBytesUnchecked(signature[:secp256k1CoordinateSize])
+	s.PutBytesUnchecked(signature[secp256k1CoordinateSize:])
+	return signature
+}
@@ -0,0 +1,39 @@
+package dagjose
+
+import (
+	"testing"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"github.com/stretchr/testify/require"
+	"pgregory.net/rapid"
+)
+
+func secp256k1PrivateKeyGen() *rapid.Generator[*secp256k1.PrivateKey] {
+	return rapid.Custom(func(t *rapid.T) *secp256k1.PrivateKey {
+		return secp256k1.PrivKeyFromBytes(rapid.SliceOfN(rapid.Byte(), 32, 32).Draw(t, "private key bytes"))
+	}).Filter(func(key *secp256k1.PrivateKey) bool {
+		return !key.Key.IsZero()
+	})
+}
+
+func TestES256KRoundTrip(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		link := cidGen().Draw(t, "payload")
+		privateKey := secp256k1PrivateKeyGen().Draw(t, "private key")
+		jws, err := SignCID(link, Signer{Algorithm: ES256K, Key: privateKey})
+		require.NoError(t, err)
+		signature, err := jws.FieldSignatures().Must().Lookup(0).FieldSignature().AsBytes()
+		require.NoError(t, err)
+		require.Len(t, signature, 64)
+		var s secp256k1.ModNScalar
+		s.SetByteSlice(signature[32:])
+		require.False(t, s.IsOverHalfOrder())
+
+		results, err := VerifyJWS(roundTripJWS(t, jws), privateKey.PubKey())
+		require.NoError(t, err)
+		require.True(t, results[0].Valid(), "%v", results[0].Err)
+		results, err = VerifyJWS(roundTripJWS(t, jws), secp256k1PrivateKeyGen().Draw(t, "other key").PubKey())
+		require.NoError(t, err)
+		require.False(t, results[0].Valid())
+	})
+}
@@ -0,0 +1,129 @@
+package dagjose
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/ipld/go-ipld-prime/codec/dagjson"
+	"github.com/ipld/go-ipld-prime/datamodel"
+	"github.com/ipld/go-ipld-prime/node/basicnode"
+)
+
+// ProtectedHeader is the parsed form of the `protected` field of a JWS signature or JWE. The registered header
+// parameters commonly used with DAG-JOSE are available as fields, and all other parameters are kept in Extra.
+type ProtectedHeader struct {
+	// Alg is the `alg` (algorithm) header parameter.
+	Alg string
+	// Kid is the `kid` (key ID) header parameter.
+	Kid string
+	// Typ is the `typ` (type) header parameter.
+	Typ string
+	// Cty is the `cty` (content type) header parameter.
+	Cty string
+	// Crit is the `crit` (critical) header parameter.
+	Crit []string
+	// B64 is the `b64` (base64url-encode payload) header parameter of a JWS, or nil if absent.
+	// See: https://datatracker.ietf.org/doc/html/rfc7797#section-3
+	B64 *bool
+	// Enc is the `enc` (encryption algorithm) header parameter of a JWE.
+	Enc string
+	// Epk is the `epk` (ephemeral public key) header parameter of a JWE, as the members of a JWK.
+	Epk map[string]interface{}
+	// Extra contains all other header parameters. Values are represented in the same way as JWS header values, so
+	// integers are int64 and other numbers float64.
+	Extra map[string]interface{}
+
+	raw []byte
+}
+
+// ProtectedHeader returns the parsed protected header of the signature. If the signature has no protected header, an
+// empty header is returned.
+func (n *_DecodedSignature) ProtectedHeader() (*ProtectedHeader, error) {
+	return parseProtectedHeader(n.protected)
+}
+
+// ProtectedHeader returns the parsed protected header of the JWE. If the JWE has no protected header, an empty header
+// is returned.
+func (n *_DecodedJWE) ProtectedHeader() (*ProtectedHeader, error) {
+	return parseProtectedHeader(n.protected)
+}
+
+// Node returns the protected header as an IPLD map node, which can be traversed with selectors.
+func (h *ProtectedHeader) Node() (datamodel.Node, error) {
+	raw := h.raw
+	if len(raw) == 0 {
+		raw = []byte("{}")
+	}
+	return decodeProtectedHeader(raw)
+}
+
+// decodeProtectedHeader decodes the JSON of a protected header into a map node. Objects that look like DAG-JSON links
+// or bytes are kept as maps since the header is plain JSON.
+func decodeProtectedHeader(raw []byte) (datamodel.Node, error) {
+	nb := basicnode.Prototype.Map.NewBuilder()
+	if err := (dagjson.DecodeOptions{
+		ParseLinks: false,
+		ParseBytes: false,
+	}.Decode(nb, bytes.NewReader(raw))); err != nil {
+		return nil, err
+	}
+	return nb.Build(), nil
+}
+
+func parseProtectedHeader(protected _Base64Url__Maybe) (*ProtectedHeader, error) {
+	header := &ProtectedHeader{Extra: map[string]interface{}{}}
+	if !protected.Exists() {
+		return header, nil
+	}
+	header.raw, _ = protected.v.AsBytes()
+	// The parameters are converted structurally from the decoded node, so that integers are kept exactly as int64
+	// instead of being rounded to float64.
+	var params map[string]interface{}
+	if n, err := decodeProtectedHeader(header.raw); err != nil {
+		return nil, fmt.Errorf("invalid protected header: %w", err)
+	} else if value, err := goValueFromNode(n); err != nil {
+		return nil, fmt.Errorf("invalid protected header: %w", err)
+	} else {
+		params = value.(map[string]interface{})
+	}
+	for key, value := range params {
+		var castOk bool
+		switch key {
+		case "alg":
+			header.Alg, castOk = value.(string)
+		case "kid":
+			header.Kid, castOk = value.(string)
+		case "typ":
+			header.Typ, castOk = value.(string)
+		case "cty":
+			header.Cty, castOk = value.(string)
+		case "enc":
+			header.Enc, castOk = value.(string)
+		case "b64":
+			var b64 bool
+			if b64, castOk = value.(bool); castOk {
+				header.B64 = &b64
+			}
+		case "epk":
+			header.Epk, castOk = value.(map[string]interface{})
+		case "crit":
+			var crit []interface{}
+			if crit, castOk = value.([]interface{}); castOk {
+				header.Crit = make([]string, 0, len(crit))
+				for _, entry := range crit {
+					if name, isString := entry.(string); !isString {
+						castOk = false
+					} else {
+						header.Crit = append(header.Crit, name)
+					}
+				}
+			}
+		default:
+			header.Extra[key], castOk = value, true
+		}
+		if !castOk {
+			return nil, fmt.Errorf("invalid protected header: invalid `%s` header parameter", key)
+		}
+	}
+	return header, nil
+}
@@ -0,0 +1,77 @@
+package dagjose
+
+import (
+	"bytes"
+	"crypto/ecdh"
+	"crypto/rand"
+	"testing"
+
+	gojose "github.com/go-jose/go-jose/v4"
+	"github.com/ipld/go-ipld-prime/datamodel"
+	"github.com/ipld/go-ipld-prime/schema"
+	"github.com/ipld/go-ipld-prime/traversal"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSignatureProtectedHeader(t *testing.T) {
+	jws, err := SignCID(createCid([]byte("payload")), Signer{
+		Algorithm: gojose.EdDSA,
+		Key:       ed25519PrivateKeyGen().Example(),
+		KeyID:     "did:key:z6Mk#z6Mk",
+		Protected: map[string]interface{}{"typ": "JWT", "cap": "ipfs://bafy"},
+	})
+	require.NoError(t, err)
+	buf := bytes.Buffer{}
+	require.NoError(t, Encode(jws, &buf))
+	nb := Type.DecodedJWS__Repr.NewBuilder()
+	require.NoError(t, Decode(nb, &buf))
+	decoded := nb.Build().(DecodedJWS)
+
+	header, err := decoded.FieldSignatures().Must().Lookup(0).ProtectedHeader()
+	require.NoError(t, err)
+	require.Equal(t, "EdDSA", header.Alg)
+	require.Equal(t, "did:key:z6Mk#z6Mk", header.Kid)
+	require.Equal(t, "JWT", header.Typ)
+	require.Equal(t, map[string]interface{}{"cap": "ipfs://bafy"}, header.Extra)
+
+	headerNode, err := header.Node()
+	require.NoError(t, err)
+	capNode, err := traversal.Get(headerNode, datamodel.ParsePath("cap"))
+	require.NoError(t, err)
+	capString, err := capNode.AsString()
+	require.NoError(t, err)
+	require.Equal(t, "ipfs://bafy", capString)
+}
+
+func TestJWEProtectedHeader(t *testing.T) {
+	key, err := ecdh.X25519().GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	jwe, err := EncryptNode(cleartextNode(), EncryptOptions{Encryption: XC20P},
+		Recipient{Algorithm: gojose.ECDH_ES_A256KW, Key: key.PublicKey(), KeyID: "recipient"})
+	require.NoError(t, err)
+	decoded, err := asDecodedJWE(jwe)
+	require.NoError(t, err)
+
+	header, err := decoded.ProtectedHeader()
+	require.NoError(t, err)
+	require.Equal(t, "ECDH-ES+A256KW", header.Alg)
+	require.Equal(t, "XC20P", header.Enc)
+	require.Equal(t, "recipient", header.Kid)
+	require.Equal(t, "X25519", header.Epk["crv"])
+}
+
+func TestInvalidProtectedHeader(t *testing.T) {
+	_, err := parseProtectedHeader(_Base64Url__Maybe{m: schema.Maybe_Value, v: _Base64Url{`{"alg": 1}`}})
+	require.Error(t, err)
+	_, err = parseProtectedHeader(_Base64Url__Maybe{m: schema.Maybe_Value, v: _Base64Url{`{"crit": ["b64", 1]}`}})
+	require.Error(t, err)
+	_, err = parseProtectedHeader(_Base64Url__Maybe{m: schema.Maybe_Value, v: _Base64Url{`not json`}})
+	require.Error(t, err)
+}
+
+// Integers beyond the precision of float64 must be kept exactly
+func TestProtectedHeaderNumbers(t *testing.T) {
+	header, err := parseProtectedHeader(_Base64Url__Maybe{m: schema.Maybe_Value, v: _Base64Url{`{"alg": "EdDSA", "iat": 9007199254740993, "ratio": 0.5}`}})
+	require.NoError(t, err)
+	require.Equal(t, map[string]interface{}{"iat": int64(9007199254740993), "ratio": 0.5}, header.Extra)
+}
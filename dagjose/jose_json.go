@@ -0,0 +1,234 @@
+package dagjose
+
+import (
+	"errors"
+	"io"
+
+	"github.com/go-jose/go-jose/v4/json"
+	"github.com/ipfs/go-cid"
+	"github.com/ipld/go-ipld-prime/codec/dagjson"
+	"github.com/ipld/go-ipld-prime/datamodel"
+	"github.com/ipld/go-ipld-prime/node/basicnode"
+	"github.com/ipld/go-ipld-prime/schema"
+)
+
+// FlattenMode selects between the general and flattened serializations when exporting a JWS or JWE with ToJSON, or when
+// signing with SignCIDNode.
+type FlattenMode int
+
+const (
+	// FlattenAuto uses the flattened serialization if there is exactly one signature or recipient, and the general
+	// serialization otherwise.
+	FlattenAuto FlattenMode = iota
+	// FlattenAlways always uses the flattened serialization, and fails if there are multiple signatures or recipients.
+	FlattenAlways
+	// FlattenNever always uses the general serialization.
+	FlattenNever
+)
+
+// FromJSON reads a JWS or JWE in general or flattened JSON serialization and returns the corresponding node, in the
+// same general form produced by Decode. For a JWS, this includes the `link` field corresponding to the payload.
+// See: https://datatracker.ietf.org/doc/html/rfc7515#section-7.2 and
+// https://datatracker.ietf.org/doc/html/rfc7516#section-7.2
+func FromJSON(r io.Reader) (datamodel.Node, error) {
+	if anyNode, err := decodeJSON(r); err != nil {
+		return nil, err
+	} else {
+		return fromJSONNode(anyNode)
+	}
+}
+
+// FromDetachedJSON is like FromJSON for a JWS whose payload was supplied separately, e.g. one exported by ToJSON with
+// `b64: false`. The `payload` of the JSON object, if present and not empty, must be the given CID.
+// See: https://datatracker.ietf.org/doc/html/rfc7515#appendix-F
+func FromDetachedJSON(r io.Reader, payload cid.Cid) (datamodel.Node, error) {
+	anyNode, err := decodeJSON(r)
+	if err != nil {
+		return nil, err
+	}
+	if jwe, err := isJWE(anyNode); err != nil {
+		return nil, err
+	} else if jwe {
+		return nil, &ErrInvalidSerialization{Object: "JWS", Field: "ciphertext", Reason: "detached payloads require a JWS"}
+	}
+	if anyNode, err = AttachPayload(anyNode, payload); err != nil {
+		return nil, err
+	}
+	return fromJSONNode(anyNode)
+}
+
+func decodeJSON(r io.Reader) (datamodel.Node, error) {
+	anyBuilder := basicnode.Prototype.Any.NewBuilder()
+	if err := (dagjson.DecodeOptions{
+		ParseLinks: false,
+		ParseBytes: false,
+	}.Decode(anyBuilder, r)); err != nil {
+		return nil, err
+	}
+	return anyBuilder.Build(), nil
+}
+
+func fromJSONNode(anyNode datamodel.Node) (datamodel.Node, error) {
+	if jwe, err := isJWE(anyNode); err != nil {
+		return nil, err
+	} else if jwe {
+		if decoded, err := asDecodedJWE(anyNode); err != nil {
+			return nil, err
+		} else {
+			return decoded.Representation(), nil
+		}
+	} else if jws, err := isJWS(anyNode); err != nil {
+		return nil, err
+	} else if jws {
+		if decoded, err := asDecodedJWS(anyNode); err != nil {
+			return nil, err
+		} else if link, err := Type.Base64Url.Link(&decoded.payload); err != nil {
+			return nil, err
+		} else {
+			decoded.link = _Link__Maybe{m: schema.Maybe_Value, v: *link}
+			return decoded.Representation(), nil
+		}
+	}
+	return nil, ErrNotJOSE
+}
+
+// ToJSON returns the JSON serialization of the given JWS or JWE node, in flattened or general form depending on the
+// given mode. The `link` field of a decoded JWS is omitted since the payload already contains the CID. A JWS with
+// `b64: false` is exported with a detached payload, which can be parsed with FromDetachedJSON.
+func ToJSON(n datamodel.Node, mode FlattenMode) ([]byte, error) {
+	if tn, castOk := n.(schema.TypedNode); castOk {
+		// The "representation" node gives an accurate view of fields that are actually present
+		n = tn.Representation()
+	}
+	if jwe, err := isJWE(n); err != nil {
+		return nil, err
+	} else if jwe {
+		if decoded, err := asDecodedJWE(n); err != nil {
+			return nil, err
+		} else {
+			return jweToJSON(decoded, mode)
+		}
+	} else if jws, err := isJWS(n); err != nil {
+		return nil, err
+	} else if jws {
+		if decoded, err := asDecodedJWS(n); err != nil {
+			return nil, err
+		} else {
+			return jwsToJSON(decoded, mode)
+		}
+	}
+	return nil, ErrNotJOSE
+}
+
+func jwsToJSON(jws DecodedJWS, mode FlattenMode) ([]byte, error) {
+	jwsMap := make(map[string]interface{})
+	// An unencoded payload is always detached since the binary CID cannot be represented in JSON
+	if unencoded, err := jwsHasUnencodedPayload(jws); err != nil {
+		return nil, err
+	} else if !unencoded {
+		jwsMap["payload"], _ = jws.payload.AsString()
+	}
+	var signatures []_DecodedSignature
+	if jws.signatures.Exists() {
+		signatures = jws.signatures.v.x
+	}
+	signatureList := make([]map[string]interface{}, 0, len(signatures))
+	for idx := range signatures {
+		signature := &signatures[idx]
+		signatureMap := make(map[string]interface{}, 3)
+		if signature.header.Exists() {
+			if headerMap, err := headerFromMaybeAny(signature.header); err != nil {
+				return nil, err
+			} else {
+				signatureMap["header"] = headerMap
+			}
+		}
+		if signature.protected.Exists() {
+			signatureMap["protected"], _ = signature.protected.v.AsString()
+		}
+		signatureMap["signature"], _ = signature.signature.AsString()
+		signatureList = append(signatureList, signatureMap)
+	}
+	if flatten, err := shouldFlatten(mode, len(signatureList)); err != nil {
+		return nil, err
+	} else if flatten {
+		for k, v := range signatureList[0] {
+			jwsMap[k] = v
+		}
+	} else {
+		jwsMap["signatures"] = signatureList
+	}
+	return json.Marshal(jwsMap)
+}
+
+func jweToJSON(jwe DecodedJWE, mode FlattenMode) ([]byte, error) {
+	ciphertextString, _ := jwe.ciphertext.AsString()
+	jweMap := map[string]interface{}{
+		"ciphertext": ciphertextString,
+	}
+	for key, field := range map[string]_Base64Url__Maybe{
+		"aad":       jwe.aad,
+		"iv":        jwe.iv,
+		"protected": jwe.protected,
+		"tag":       jwe.tag,
+	} {
+		if field.Exists() {
+			jweMap[key], _ = field.v.AsString()
+		}
+	}
+	if jwe.unprotected.Exists() {
+		if unprotectedMap, err := headerFromMaybeAny(jwe.unprotected); err != nil {
+			return nil, err
+		} else {
+			jweMap["unprotected"] = unprotectedMap
+		}
+	}
+	var recipients []_DecodedRecipient
+	if jwe.recipients.Exists() {
+		recipients = jwe.recipients.v.x
+	}
+	recipientList := make([]map[string]interface{}, 0, len(recipients))
+	for idx := range recipients {
+		recipient := &recipients[idx]
+		recipientMap := make(map[string]interface{}, 2)
+		if recipient.header.Exists() {
+			if headerMap, err := headerFromMaybeAny(recipient.header); err != nil {
+				return nil, err
+			} else {
+				recipientMap["header"] = headerMap
+			}
+		}
+		if recipient.encrypted_key.Exists() {
+			recipientMap["encrypted_key"], _ = recipient.encrypted_key.v.AsString()
+		}
+		recipientList = append(recipientList, recipientMap)
+	}
+	if len(recipientList) == 0 {
+		// A JWE without recipients (i.e. using direct encryption) has a single recipient with no parameters
+		recipientList = append(recipientList, map[string]interface{}{})
+	}
+	if flatten, err := shouldFlatten(mode, len(recipientList)); err != nil {
+		return nil, err
+	} else if flatten {
+		for k, v := range recipientList[0] {
+			jweMap[k] = v
+		}
+	} else {
+		jweMap["recipients"] = recipientList
+	}
+	return json.Marshal(jweMap)
+}
+
+func shouldFlatten(mode FlattenMode, count int) (bool, error) {
+	switch mode {
+	case FlattenAlways:
+		if count != 1 {
+			return false, errors.New("flattened serialization requires exactly one signature or recipient")
+		}
+		return true, nil
+	case FlattenNever:
+		return false, nil
+	default:
+		return count == 1, nil
+	}
+}
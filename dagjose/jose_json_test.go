@@ -0,0 +1,116 @@
+package dagjose
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/json"
+	"testing"
+
+	gojose "github.com/go-jose/go-jose/v4"
+	"github.com/ipld/go-ipld-prime/codec/dagcbor"
+	"github.com/stretchr/testify/require"
+	"pgregory.net/rapid"
+)
+
+// A JWS exported with ToJSON should be accepted and verified by go-jose, in both flattened and general form
+func TestToJSONJWSInteroperatesWithGoJose(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		link := cidGen().Draw(t, "payload")
+		privateKey := ed25519PrivateKeyGen().Draw(t, "private key")
+		jws, err := SignCID(link, Signer{Algorithm: gojose.EdDSA, Key: privateKey})
+		require.NoError(t, err)
+		for _, mode := range []FlattenMode{FlattenAuto, FlattenAlways, FlattenNever} {
+			jsonBytes, err := ToJSON(roundTripJWS(t, jws), mode)
+			require.NoError(t, err)
+			joseJws, err := gojose.ParseSigned(string(jsonBytes), []gojose.SignatureAlgorithm{gojose.EdDSA})
+			require.NoError(t, err)
+			payload, err := joseJws.Verify(privateKey.Public())
+			require.NoError(t, err)
+			require.Equal(t, link.Bytes(), payload)
+		}
+	})
+}
+
+func TestToJSONFlattenModes(t *testing.T) {
+	link := createCid([]byte("payload"))
+	first, second := ed25519PrivateKeyGen().Example(0), ed25519PrivateKeyGen().Example(1)
+	single, err := SignCID(link, Signer{Algorithm: gojose.EdDSA, Key: first})
+	require.NoError(t, err)
+	multiple, err := SignCID(link, Signer{Algorithm: gojose.EdDSA, Key: first}, Signer{Algorithm: gojose.EdDSA, Key: second})
+	require.NoError(t, err)
+
+	scenarios := []struct {
+		jws       EncodedJWS
+		mode      FlattenMode
+		flattened bool
+	}{
+		{single, FlattenAuto, true},
+		{single, FlattenAlways, true},
+		{single, FlattenNever, false},
+		{multiple, FlattenAuto, false},
+		{multiple, FlattenNever, false},
+	}
+	for _, scenario := range scenarios {
+		jsonBytes, err := ToJSON(scenario.jws, scenario.mode)
+		require.NoError(t, err)
+		var jsonMap map[string]interface{}
+		require.NoError(t, json.Unmarshal(jsonBytes, &jsonMap))
+		_, hasSignature := jsonMap["signature"]
+		_, hasSignatures := jsonMap["signatures"]
+		require.Equal(t, scenario.flattened, hasSignature)
+		require.Equal(t, !scenario.flattened, hasSignatures)
+		_, hasLink := jsonMap["link"]
+		require.False(t, hasLink)
+	}
+
+	_, err = ToJSON(multiple, FlattenAlways)
+	require.Error(t, err)
+}
+
+// FromJSON should accept its own output and produce the same node as Decode, including `link`
+func TestFromJSONRoundTrip(t *testing.T) {
+	link := createCid([]byte("payload"))
+	jws, err := SignCID(link, Signer{Algorithm: gojose.EdDSA, Key: ed25519PrivateKeyGen().Example()})
+	require.NoError(t, err)
+	jsonBytes, err := ToJSON(jws, FlattenAuto)
+	require.NoError(t, err)
+	parsed, err := FromJSON(bytes.NewReader(jsonBytes))
+	require.NoError(t, err)
+
+	linkNode, err := parsed.LookupByString("link")
+	require.NoError(t, err)
+	parsedLink, err := linkNode.AsLink()
+	require.NoError(t, err)
+	require.Equal(t, link.String(), parsedLink.String())
+
+	reexported, err := ToJSON(parsed, FlattenAuto)
+	require.NoError(t, err)
+	require.JSONEq(t, string(jsonBytes), string(reexported))
+}
+
+// A JWE exported with ToJSON should be decryptable by go-jose
+func TestToJSONJWEInteroperatesWithGoJose(t *testing.T) {
+	first, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	second, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	expected := bytes.Buffer{}
+	require.NoError(t, dagcbor.Encode(cleartextNode(), &expected))
+
+	for _, recipients := range [][]Recipient{
+		{{Algorithm: gojose.ECDH_ES_A256KW, Key: &first.PublicKey}},
+		{{Algorithm: gojose.ECDH_ES_A256KW, Key: &first.PublicKey}, {Algorithm: gojose.ECDH_ES_A256KW, Key: &second.PublicKey}},
+	} {
+		jwe, err := EncryptNode(cleartextNode(), EncryptOptions{}, recipients...)
+		require.NoError(t, err)
+		jsonBytes, err := ToJSON(roundTripJWE(t, jwe), FlattenAuto)
+		require.NoError(t, err)
+		joseJwe, err := gojose.ParseEncrypted(string(jsonBytes), []gojose.KeyAlgorithm{gojose.ECDH_ES_A256KW}, []gojose.ContentEncryption{gojose.A256GCM})
+		require.NoError(t, err)
+		_, _, cleartext, err := joseJwe.DecryptMulti(first)
+		require.NoError(t, err)
+		require.Equal(t, expected.Bytes(), cleartext)
+	}
+}
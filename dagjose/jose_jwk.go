@@ -0,0 +1,210 @@
+package dagjose
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdh"
+	"errors"
+	"fmt"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	gojose "github.com/go-jose/go-jose/v4"
+	"github.com/go-jose/go-jose/v4/json"
+)
+
+const secp256k1CoordinateSize = 32
+
+// ParseJWK parses a JSON Web Key. Key types supported by go-jose are parsed by go-jose, and X25519 (`OKP`) and
+// secp256k1 (`EC`) keys, which go-jose does not support, are parsed to *ecdh.PublicKey or *ecdh.PrivateKey and
+// *secp256k1.PublicKey or *secp256k1.PrivateKey respectively.
+// See: https://datatracker.ietf.org/doc/html/rfc7517#section-4
+func ParseJWK(data []byte) (*gojose.JSONWebKey, error) {
+	var members map[string]interface{}
+	if err := json.Unmarshal(data, &members); err != nil {
+		return nil, fmt.Errorf("invalid JWK: %w", err)
+	}
+	kty, _ := members["kty"].(string)
+	crv, _ := members["crv"].(string)
+	var key interface{}
+	var err error
+	switch {
+	case kty == "OKP" && crv == "X25519":
+		key, err = parseX25519JWK(members)
+	case kty == "EC" && crv == "secp256k1":
+		key, err = parseSecp256k1JWK(members)
+	default:
+		jwk := new(gojose.JSONWebKey)
+		if err := jwk.UnmarshalJSON(data); err != nil {
+			return nil, fmt.Errorf("invalid JWK: %w", err)
+		}
+		return jwk, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s JWK: %w", crv, err)
+	}
+	jwk := &gojose.JSONWebKey{Key: key}
+	jwk.KeyID, _ = members["kid"].(string)
+	jwk.Algorithm, _ = members["alg"].(string)
+	jwk.Use, _ = members["use"].(string)
+	return jwk, nil
+}
+
+// ParseJWKSet parses a JSON Web Key Set, with the same key support as ParseJWK.
+// See: https://datatracker.ietf.org/doc/html/rfc7517#section-5
+func ParseJWKSet(data []byte) ([]gojose.JSONWebKey, error) {
+	var set struct {
+		Keys []json.RawMessage `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, fmt.Errorf("invalid JWK Set: %w", err)
+	} else if set.Keys == nil {
+		return nil, errors.New("invalid JWK Set: missing `keys` member")
+	}
+	keys := make([]gojose.JSONWebKey, 0, len(set.Keys))
+	for idx, raw := range set.Keys {
+		if jwk, err := ParseJWK(raw); err != nil {
+			return nil, fmt.Errorf("invalid JWK Set: key %d: %w", idx, err)
+		} else {
+			keys = append(keys, *jwk)
+		}
+	}
+	return keys, nil
+}
+
+// MarshalJWK serializes a JSON Web Key, including the X25519 and secp256k1 keys returned by ParseJWK.
+func MarshalJWK(jwk gojose.JSONWebKey) ([]byte, error) {
+	var members map[string]interface{}
+	switch k := jwk.Key.(type) {
+	case *ecdh.PublicKey:
+		members = ecdhPublicKeyToJWK(k)
+	case *ecdh.PrivateKey:
+		members = ecdhPublicKeyToJWK(k.PublicKey())
+		members["d"] = encodeBase64Url(k.Bytes())
+	case *secp256k1.PublicKey:
+		members = secp256k1PublicKeyToJWK(k)
+	case *secp256k1.PrivateKey:
+		members = secp256k1PublicKeyToJWK(k.PubKey())
+		members["d"] = encodeBase64Url(k.Serialize())
+	default:
+		return jwk.MarshalJSON()
+	}
+	if members["crv"] == "" {
+		return nil, errors.New("unsupported ECDH curve")
+	}
+	if jwk.KeyID != "" {
+		members["kid"] = jwk.KeyID
+	}
+	if jwk.Algorithm != "" {
+		members["alg"] = jwk.Algorithm
+	}
+	if jwk.Use != "" {
+		members["use"] = jwk.Use
+	}
+	return json.Marshal(members)
+}
+
+// MarshalJWKSet serializes a JSON Web Key Set containing the given keys.
+func MarshalJWKSet(keys []gojose.JSONWebKey) ([]byte, error) {
+	set := struct {
+		Keys []json.RawMessage `json:"keys"`
+	}{make([]json.RawMessage, 0, len(keys))}
+	for _, jwk := range keys {
+		if encoded, err := MarshalJWK(jwk); err != nil {
+			return nil, err
+		} else {
+			set.Keys = append(set.Keys, encoded)
+		}
+	}
+	return json.Marshal(set)
+}
+
+// PublicJWK returns the public JWK of the signer, with the `kid` and `alg` that appear in its signatures, so that it can
+// be published for third parties to verify them.
+func (s Signer) PublicJWK() (gojose.JSONWebKey, error) {
+	key, kid := s.Key, s.KeyID
+	switch k := key.(type) {
+	case gojose.JSONWebKey:
+		key = k.Key
+		if kid == "" {
+			kid = k.KeyID
+		}
+	case *gojose.JSONWebKey:
+		key = k.Key
+		if kid == "" {
+			kid = k.KeyID
+		}
+	}
+	var publicKey crypto.PublicKey
+	switch k := key.(type) {
+	case *secp256k1.PrivateKey:
+		publicKey = k.PubKey()
+	case crypto.Signer:
+		publicKey = k.Public()
+	default:
+		return gojose.JSONWebKey{}, fmt.Errorf("not a signing key: %T", key)
+	}
+	return gojose.JSONWebKey{Key: publicKey, KeyID: kid, Algorithm: string(s.Algorithm), Use: "sig"}, nil
+}
+
+func parseX25519JWK(members map[string]interface{}) (interface{}, error) {
+	x, err := jwkCoordinate(members, "x")
+	if err != nil {
+		return nil, err
+	}
+	publicKey, err := ecdh.X25519().NewPublicKey(x)
+	if err != nil {
+		return nil, err
+	}
+	if _, found := members["d"]; !found {
+		return publicKey, nil
+	}
+	if d, err := jwkCoordinate(members, "d"); err != nil {
+		return nil, err
+	} else if privateKey, err := ecdh.X25519().NewPrivateKey(d); err != nil {
+		return nil, err
+	} else if !privateKey.PublicKey().Equal(publicKey) {
+		return nil, errors.New("public and private key do not match")
+	} else {
+		return privateKey, nil
+	}
+}
+
+func parseSecp256k1JWK(members map[string]interface{}) (interface{}, error) {
+	x, err := jwkCoordinate(members, "x")
+	if err != nil {
+		return nil, err
+	}
+	y, err := jwkCoordinate(members, "y")
+	if err != nil {
+		return nil, err
+	}
+	if len(x) != secp256k1CoordinateSize || len(y) != secp256k1CoordinateSize {
+		return nil, errors.New("invalid coordinate length")
+	}
+	publicKey, err := secp256k1.ParsePubKey(append(append([]byte{4}, x...), y...))
+	if err != nil {
+		return nil, err
+	}
+	if _, found := members["d"]; !found {
+		return publicKey, nil
+	}
+	if d, err := jwkCoordinate(members, "d"); err != nil {
+		return nil, err
+	} else if len(d) != secp256k1CoordinateSize {
+		return nil, errors.New("invalid private key length")
+	} else if privateKey := secp256k1.PrivKeyFromBytes(d); !bytes.Equal(privateKey.PubKey().SerializeUncompressed(), publicKey.SerializeUncompressed()) {
+		return nil, errors.New("public and private key do not match")
+	} else {
+		return privateKey, nil
+	}
+}
+
+func secp256k1PublicKeyToJWK(publicKey *secp256k1.PublicKey) map[string]interface{} {
+	point := publicKey.SerializeUncompressed()
+	return map[string]interface{}{
+		"kty": "EC",
+		"crv": "secp256k1",
+		"x":   encodeBase64Url(point[1 : 1+secp256k1CoordinateSize]),
+		"y":   encodeBase64Url(point[1+secp256k1CoordinateSize:]),
+	}
+}
@@ -0,0 +1,114 @@
+package dagjose
+
+import (
+	"context"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"testing"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	gojose "github.com/go-jose/go-jose/v4"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJWKRoundTrip(t *testing.T) {
+	xKey, err := ecdh.X25519().GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	secpKey, err := secp256k1.GeneratePrivateKey()
+	require.NoError(t, err)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	edKey := ed25519PrivateKeyGen().Example()
+
+	for _, key := range []interface{}{
+		xKey, xKey.PublicKey(), secpKey, secpKey.PubKey(), ecKey, &ecKey.PublicKey, edKey, edKey.Public(),
+	} {
+		encoded, err := MarshalJWK(gojose.JSONWebKey{Key: key, KeyID: "key", Use: "enc"})
+		require.NoError(t, err, "%T", key)
+		jwk, err := ParseJWK(encoded)
+		require.NoError(t, err, "%T", key)
+		require.Equal(t, "key", jwk.KeyID)
+		require.Equal(t, "enc", jwk.Use)
+		require.IsType(t, key, jwk.Key)
+		reencoded, err := MarshalJWK(*jwk)
+		require.NoError(t, err)
+		require.JSONEq(t, string(encoded), string(reencoded))
+	}
+}
+
+func TestParseJWKRejectsInvalidKeys(t *testing.T) {
+	xKey, err := ecdh.X25519().GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	otherKey, err := ecdh.X25519().GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	x, d := encodeBase64Url(xKey.PublicKey().Bytes()), encodeBase64Url(otherKey.Bytes())
+	for _, invalid := range []string{
+		`not json`,
+		`{"kty":"OKP","crv":"X25519"}`,
+		`{"kty":"OKP","crv":"X25519","x":"AAAA"}`,
+		`{"kty":"OKP","crv":"X25519","x":"` + x + `","d":"` + d + `"}`,
+		`{"kty":"EC","crv":"secp256k1","x":"` + x + `"}`,
+		`{"kty":"EC","crv":"secp256k1","x":"` + x + `","y":"` + x + `"}`,
+		`{"kty":"unknown"}`,
+	} {
+		_, err := ParseJWK([]byte(invalid))
+		require.Error(t, err, invalid)
+	}
+	_, err = ParseJWKSet([]byte(`{}`))
+	require.Error(t, err)
+	_, err = ParseJWKSet([]byte(`{"keys":[{"kty":"unknown"}]}`))
+	require.Error(t, err)
+}
+
+// Public JWKs exported for signers should verify their signatures when published as a JWK Set
+func TestSignerPublicJWK(t *testing.T) {
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	edKey := ed25519PrivateKeyGen().Example()
+	signers := []Signer{
+		{Algorithm: gojose.ES256, Key: ecKey, KeyID: "ec"},
+		{Algorithm: gojose.EdDSA, Key: gojose.JSONWebKey{Key: edKey, KeyID: "ed"}},
+	}
+	jws, err := SignCID(createCid([]byte("payload")), signers...)
+	require.NoError(t, err)
+
+	keys := make([]gojose.JSONWebKey, 0, len(signers))
+	for _, signer := range signers {
+		jwk, err := signer.PublicJWK()
+		require.NoError(t, err)
+		require.True(t, jwk.IsPublic())
+		require.Equal(t, string(signer.Algorithm), jwk.Algorithm)
+		keys = append(keys, jwk)
+	}
+	set, err := MarshalJWKSet(keys)
+	require.NoError(t, err)
+	require.NotContains(t, string(set), `"d"`)
+	resolver, err := NewJWKSetResolver(set)
+	require.NoError(t, err)
+	results, err := VerifyJWSWithResolver(context.Background(), roundTripJWS(t, jws), resolver)
+	require.NoError(t, err)
+	for _, result := range results {
+		require.True(t, result.Valid(), "%v", result.Err)
+	}
+
+	_, err = Signer{Algorithm: gojose.ES256, Key: &ecKey.PublicKey}.PublicJWK()
+	require.Error(t, err)
+}
+
+// X25519 keys loaded from a JWK Set should decrypt JWEs for the matching `kid`
+func TestJWKSetResolverX25519(t *testing.T) {
+	xKey, err := ecdh.X25519().GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	set, err := MarshalJWKSet([]gojose.JSONWebKey{{Key: xKey, KeyID: "x25519"}})
+	require.NoError(t, err)
+	resolver, err := NewJWKSetResolver(set)
+	require.NoError(t, err)
+
+	jwe, err := EncryptNode(cleartextNode(), EncryptOptions{},
+		Recipient{Algorithm: gojose.ECDH_ES_A256KW, Key: xKey.PublicKey(), KeyID: "x25519"},
+	)
+	require.NoError(t, err)
+	requireResolverDecrypts(t, jwe, resolver)
+}
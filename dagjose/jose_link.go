@@ -0,0 +1,134 @@
+package dagjose
+
+import (
+	"bytes"
+	"crypto"
+	"errors"
+	"fmt"
+
+	"github.com/ipfs/go-cid"
+	"github.com/ipld/go-ipld-prime"
+	"github.com/ipld/go-ipld-prime/datamodel"
+	"github.com/ipld/go-ipld-prime/linking/cid"
+	"github.com/ipld/go-ipld-prime/node/basicnode"
+)
+
+// LinkPrototype builds CID links to DAG-JOSE objects using the dag-jose multicodec and the sha2-256 multihash. It can be
+// passed to ipld.LinkSystem methods directly by users of the underlying LinkSystem.
+var LinkPrototype = cidlink.LinkPrototype{Prefix: cid.Prefix{
+	Version:  1,    // Usually '1'.
+	Codec:    0x85, // 0x85 means "dag-jose" -- See the multicodecs table: https://github.com/multiformats/multicodec/
+	MhType:   0x12, // 0x12 means "sha2-256" -- See the multicodecs table: https://github.com/multiformats/multicodec/
+	MhLength: 32,   // sha2-256 hash has a 32-byte sum.
+}}
+
+// LoadedJOSE is the result of LoadJOSE. Exactly one of JWS and JWE is set, depending on the type of the loaded object.
+type LoadedJOSE struct {
+	JWS DecodedJWS
+	JWE DecodedJWE
+}
+
+// IsJWS returns true if the loaded object is a JWS.
+func (j LoadedJOSE) IsJWS() bool {
+	return j.JWS != nil
+}
+
+// IsJWE returns true if the loaded object is a JWE.
+func (j LoadedJOSE) IsJWE() bool {
+	return j.JWE != nil
+}
+
+// Node returns the representation node of the loaded object, which gives an accurate view of the fields that are
+// actually present.
+func (j LoadedJOSE) Node() datamodel.Node {
+	if j.JWE != nil {
+		return j.JWE.Representation()
+	}
+	return j.JWS.Representation()
+}
+
+// StoreJOSE is a convenience function that passes LinkPrototype and the given DAG-JOSE object to ipld.LinkSystem.Store.
+func StoreJOSE(linkContext ipld.LinkContext, jose datamodel.Node, linkSystem ipld.LinkSystem) (ipld.Link, error) {
+	return linkSystem.Store(linkContext, LinkPrototype, jose)
+}
+
+// LoadJOSE is a convenience function that loads the block for the given link through ipld.LinkSystem.LoadRaw and
+// decodes it as either a JWE or a JWS. The returned LoadedJOSE indicates which of the two was found. As with Decode, a
+// decoded JWS includes the `link` field corresponding to its payload.
+func LoadJOSE(lnk ipld.Link, linkContext ipld.LinkContext, linkSystem ipld.LinkSystem) (*LoadedJOSE, error) {
+	if cl, castOk := lnk.(cidlink.Link); castOk && cl.Cid.Prefix().Codec != LinkPrototype.Codec {
+		return nil, fmt.Errorf("link does not refer to a DAG-JOSE object: unexpected codec 0x%x", cl.Cid.Prefix().Codec)
+	}
+	block, err := linkSystem.LoadRaw(linkContext, lnk)
+	if err != nil {
+		return nil, err
+	}
+	if kind, err := peekJOSEKind(block); err != nil {
+		return nil, err
+	} else if kind == joseKindJWE {
+		jweBuilder := Type.DecodedJWE__Repr.NewBuilder()
+		if err := (DecodeOptions{}.DecodeJWE(jweBuilder, bytes.NewReader(block))); err != nil {
+			return nil, err
+		}
+		return &LoadedJOSE{JWE: jweBuilder.Build().(DecodedJWE)}, nil
+	} else {
+		jwsBuilder := Type.DecodedJWS__Repr.NewBuilder()
+		if err := (DecodeOptions{AddLink: true}.DecodeJWS(jwsBuilder, bytes.NewReader(block))); err != nil {
+			return nil, err
+		}
+		return &LoadedJOSE{JWS: jwsBuilder.Build().(DecodedJWS)}, nil
+	}
+}
+
+// SignedNode is the result of LoadSigned.
+type SignedNode struct {
+	// JWS is the loaded JWS, including the `link` field.
+	JWS DecodedJWS
+	// Payload is the node loaded from the block the JWS payload links to.
+	Payload datamodel.Node
+	// Verifications contains the result of verifying each signature, if any keys were supplied to LoadSigned.
+	Verifications []SignatureVerification
+}
+
+// LoadSigned loads the JWS for the given link, then loads the block its payload links to with the codec named by the
+// payload CID, using the given prototype (or basicnode.Prototype.Any if nil) to build the payload node.
+//
+// If any keys are supplied, the JWS is verified with VerifyJWS before the payload is loaded, and an error is returned
+// unless at least one signature is valid. The individual results are available in SignedNode.Verifications.
+func LoadSigned(ls ipld.LinkSystem, jwsLink ipld.Link, proto datamodel.NodePrototype, keys ...crypto.PublicKey) (*SignedNode, error) {
+	loaded, err := LoadJOSE(jwsLink, ipld.LinkContext{}, ls)
+	if err != nil {
+		return nil, err
+	} else if !loaded.IsJWS() {
+		return nil, errors.New("link does not refer to a JWS")
+	}
+	signed := &SignedNode{JWS: loaded.JWS}
+	if len(keys) > 0 {
+		if signed.Verifications, err = VerifyJWS(loaded.JWS, keys...); err != nil {
+			return nil, err
+		}
+		verified := false
+		for _, verification := range signed.Verifications {
+			verified = verified || verification.Valid()
+		}
+		if !verified {
+			return nil, errors.New("no valid signature")
+		}
+	}
+	if proto == nil {
+		proto = basicnode.Prototype.Any
+	}
+	linkContext := ipld.LinkContext{
+		LinkPath: datamodel.ParsePath("link"),
+		LinkNode: loaded.JWS.Representation(),
+	}
+	// The payload is loaded through the signed `payload` field rather than `link`, which isn't covered by the signatures
+	payload, err := payloadCID([]byte(loaded.JWS.payload.x))
+	if err != nil {
+		return nil, err
+	}
+	if signed.Payload, err = ls.Load(linkContext, cidlink.Link{Cid: payload}, proto); err != nil {
+		return nil, fmt.Errorf("unable to load payload: %w", err)
+	}
+	return signed, nil
+}
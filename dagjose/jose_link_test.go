@@ -0,0 +1,122 @@
+package dagjose
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	gojose "github.com/go-jose/go-jose/v4"
+	"github.com/ipfs/go-cid"
+	"github.com/ipld/go-ipld-prime"
+	"github.com/ipld/go-ipld-prime/codec/dagcbor"
+	"github.com/ipld/go-ipld-prime/datamodel"
+	"github.com/ipld/go-ipld-prime/fluent"
+	"github.com/ipld/go-ipld-prime/linking/cid"
+	"github.com/ipld/go-ipld-prime/node/basicnode"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ed25519"
+	"pgregory.net/rapid"
+)
+
+// memoryLinkSystem returns a LinkSystem backed by a map
+func memoryLinkSystem() ipld.LinkSystem {
+	store := make(map[string][]byte)
+	ls := cidlink.DefaultLinkSystem()
+	ls.StorageWriteOpener = func(lnkCtx ipld.LinkContext) (io.Writer, ipld.BlockWriteCommitter, error) {
+		buf := bytes.Buffer{}
+		return &buf, func(lnk ipld.Link) error {
+			store[lnk.Binary()] = buf.Bytes()
+			return nil
+		}, nil
+	}
+	ls.StorageReadOpener = func(lnkCtx ipld.LinkContext, lnk ipld.Link) (io.Reader, error) {
+		return bytes.NewReader(store[lnk.Binary()]), nil
+	}
+	return ls
+}
+
+// storeRawJOSE stores the given block as-is under its dag-jose CID, bypassing the checks done by Encode
+func storeRawJOSE(t require.TestingT, ls ipld.LinkSystem, block []byte) cid.Cid {
+	c, err := LinkPrototype.Sum(block)
+	require.NoError(t, err)
+	w, commit, err := ls.StorageWriteOpener(ipld.LinkContext{})
+	require.NoError(t, err)
+	_, err = w.Write(block)
+	require.NoError(t, err)
+	require.NoError(t, commit(cidlink.Link{Cid: c}))
+	return c
+}
+
+func TestLoadSigned(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		ls := memoryLinkSystem()
+		payload := fluent.MustBuildMap(basicnode.Prototype.Map, 1, func(ma fluent.MapAssembler) {
+			ma.AssembleEntry("content").AssignString(rapid.String().Draw(t, "content"))
+		})
+		payloadLink, err := ls.Store(ipld.LinkContext{}, cidlink.LinkPrototype{Prefix: cid.Prefix{
+			Version:  1,
+			Codec:    0x71, // dag-cbor
+			MhType:   0x12,
+			MhLength: 32,
+		}}, payload)
+		require.NoError(t, err)
+		privateKey := ed25519PrivateKeyGen().Draw(t, "private key")
+		jws, err := SignCID(payloadLink.(cidlink.Link).Cid, Signer{Algorithm: gojose.EdDSA, Key: privateKey})
+		require.NoError(t, err)
+		jwsLink, err := StoreJOSE(ipld.LinkContext{}, jws, ls)
+		require.NoError(t, err)
+
+		signed, err := LoadSigned(ls, jwsLink, nil)
+		require.NoError(t, err)
+		require.True(t, datamodel.DeepEqual(payload, signed.Payload))
+		require.Nil(t, signed.Verifications)
+
+		signed, err = LoadSigned(ls, jwsLink, basicnode.Prototype.Map, privateKey.Public())
+		require.NoError(t, err)
+		require.True(t, datamodel.DeepEqual(payload, signed.Payload))
+		require.True(t, signed.Verifications[0].Valid())
+
+		otherKey := ed25519PrivateKeyGen().Filter(func(k ed25519.PrivateKey) bool {
+			return !k.Equal(privateKey)
+		}).Draw(t, "other private key")
+		_, err = LoadSigned(ls, jwsLink, nil, otherKey.Public())
+		require.Error(t, err)
+	})
+}
+
+func TestLoadSignedRejectsJWE(t *testing.T) {
+	ls := memoryLinkSystem()
+	jweLink, err := StoreJOSE(ipld.LinkContext{}, jweGen(1).Example(), ls)
+	require.NoError(t, err)
+	_, err = LoadSigned(ls, jweLink, nil)
+	require.Error(t, err)
+}
+
+// A `link` that doesn't match the signed `payload` must not be used to load a different payload
+func TestLoadSignedRejectsForgedLink(t *testing.T) {
+	ls := memoryLinkSystem()
+	cborPrototype := cidlink.LinkPrototype{Prefix: cid.Prefix{Version: 1, Codec: 0x71, MhType: 0x12, MhLength: 32}}
+	signedLink, err := ls.Store(ipld.LinkContext{}, cborPrototype, basicnode.NewString("signed content"))
+	require.NoError(t, err)
+	forgedLink, err := ls.Store(ipld.LinkContext{}, cborPrototype, basicnode.NewString("attacker content"))
+	require.NoError(t, err)
+	privateKey := ed25519PrivateKeyGen().Example()
+	jws, err := SignCID(signedLink.(cidlink.Link).Cid, Signer{Algorithm: gojose.EdDSA, Key: privateKey})
+	require.NoError(t, err)
+
+	// Encode rejects a mismatched `link`, so the forged block is built with DAG-CBOR directly
+	forged := fluent.MustBuildMap(basicnode.Prototype.Map, 3, func(ma fluent.MapAssembler) {
+		ma.AssembleEntry("payload").AssignNode(jws.FieldPayload().Representation())
+		ma.AssembleEntry("signatures").AssignNode(jws.FieldSignatures().Must().Representation())
+		ma.AssembleEntry("link").AssignLink(forgedLink)
+	})
+	buf := bytes.Buffer{}
+	require.NoError(t, dagcbor.Encode(forged, &buf))
+	jwsCid := storeRawJOSE(t, ls, buf.Bytes())
+
+	_, err = LoadSigned(ls, cidlink.Link{Cid: jwsCid}, nil, privateKey.Public())
+	var mismatch *ErrLinkMismatch
+	require.ErrorAs(t, err, &mismatch)
+	require.Equal(t, forgedLink.(cidlink.Link).Cid, mismatch.Link)
+	require.Equal(t, signedLink.(cidlink.Link).Cid, mismatch.Payload)
+}
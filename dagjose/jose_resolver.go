@@ -0,0 +1,298 @@
+package dagjose
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	gojose "github.com/go-jose/go-jose/v4"
+	"github.com/ipfs/go-cid"
+	"github.com/ipld/go-ipld-prime/datamodel"
+)
+
+// KeyResolver looks up the keys for JOSE objects from their header parameters, e.g. from the `kid` header parameter.
+// Implementations can be plugged in to support different key sources, such as DID methods.
+type KeyResolver interface {
+	// ResolveSigningKey returns the private key to sign with, given the protected header parameters of the signature to
+	// produce. The key is returned in any of the forms accepted for Signer.Key.
+	ResolveSigningKey(ctx context.Context, header map[string]interface{}) (crypto.PrivateKey, error)
+	// ResolveVerificationKey returns the public key to verify a signature with, given the union of the protected and
+	// unprotected header parameters of the signature.
+	ResolveVerificationKey(ctx context.Context, header map[string]interface{}) (crypto.PublicKey, error)
+	// ResolveEncryptionKey returns the key to encrypt the content encryption key of a JWE for a recipient with, given
+	// the header parameters of the recipient. The key is returned in any of the forms accepted for Recipient.Key.
+	ResolveEncryptionKey(ctx context.Context, recipientHeader map[string]interface{}) (crypto.PublicKey, error)
+	// ResolveDecryptionKey returns the key to decrypt the content of a JWE for a recipient with, given the union of the
+	// protected, shared unprotected and per-recipient header parameters. Symmetric keys (for `dir` and AES key wrap)
+	// are returned as []byte. Keys for ECDH-ES key agreement are returned as *ecdh.PrivateKey or *ecdsa.PrivateKey. Any
+	// of these may also be wrapped in a go-jose JSONWebKey.
+	ResolveDecryptionKey(ctx context.Context, recipientHeader map[string]interface{}) (crypto.PrivateKey, error)
+}
+
+// SignCIDWithResolver is like SignCID, but signs with the key returned by the given resolver for each signer that has
+// no Key. The resolver is given the `alg` and `kid` of the signer along with its other protected header parameters.
+func SignCIDWithResolver(ctx context.Context, c cid.Cid, resolver KeyResolver, signers ...Signer) (EncodedJWS, error) {
+	resolved := make([]Signer, len(signers))
+	for idx, signer := range signers {
+		if signer.Key == nil {
+			header := make(map[string]interface{}, len(signer.Protected)+2)
+			for k, v := range signer.Protected {
+				header[k] = v
+			}
+			header["alg"] = string(signer.Algorithm)
+			if signer.KeyID != "" {
+				header["kid"] = signer.KeyID
+			}
+			if key, err := resolver.ResolveSigningKey(ctx, header); err != nil {
+				return nil, fmt.Errorf("signer %d: %w", idx, err)
+			} else {
+				signer.Key = key
+			}
+		}
+		resolved[idx] = signer
+	}
+	return SignCID(c, resolved...)
+}
+
+// VerifyJWSWithResolver is like VerifyJWS, but verifies each signature with the key returned by the given resolver for
+// the header parameters of that signature. Failures to resolve a key are reported through the returned results.
+func VerifyJWSWithResolver(ctx context.Context, n datamodel.Node, resolver KeyResolver) ([]SignatureVerification, error) {
+	return verifyJWS(n, func(headers map[string]interface{}) ([]crypto.PublicKey, error) {
+		if key, err := resolver.ResolveVerificationKey(ctx, headers); err != nil {
+			return nil, err
+		} else {
+			return []crypto.PublicKey{key}, nil
+		}
+	})
+}
+
+// EncryptNodeWithResolver is like EncryptNode, but encrypts for the key returned by the given resolver for each
+// recipient that has no Key. The resolver is given the `alg` and `kid` of the recipient along with its other header
+// parameters.
+func EncryptNodeWithResolver(ctx context.Context, n datamodel.Node, opts EncryptOptions, resolver KeyResolver, recipients ...Recipient) (EncodedJWE, error) {
+	resolved := make([]Recipient, len(recipients))
+	for idx, recipient := range recipients {
+		if recipient.Key == nil {
+			header := make(map[string]interface{}, len(recipient.Header)+2)
+			for k, v := range recipient.Header {
+				header[k] = v
+			}
+			header["alg"] = string(recipient.Algorithm)
+			if recipient.KeyID != "" {
+				header["kid"] = recipient.KeyID
+			}
+			if key, err := resolver.ResolveEncryptionKey(ctx, header); err != nil {
+				return nil, fmt.Errorf("recipient %d: %w", idx, err)
+			} else {
+				recipient.Key = key
+			}
+		}
+		resolved[idx] = recipient
+	}
+	return EncryptNode(n, opts, resolved...)
+}
+
+// MemoryKeyResolver is a KeyResolver holding keys in memory, indexed by key ID. Keys are matched against the `kid`
+// header parameter.
+type MemoryKeyResolver struct {
+	mu   sync.RWMutex
+	keys map[string]interface{}
+}
+
+var _ KeyResolver = (*MemoryKeyResolver)(nil)
+
+// NewMemoryKeyResolver returns an empty MemoryKeyResolver.
+func NewMemoryKeyResolver() *MemoryKeyResolver {
+	return &MemoryKeyResolver{keys: make(map[string]interface{})}
+}
+
+// NewJWKSetResolver returns a MemoryKeyResolver holding the keys from the given JWK Set document, as parsed by
+// ParseJWKSet. Every key in the set must have a key ID, which is matched against the `kid` header parameter. Keys
+// referenced by `jku` or embedded as `jwk` in a header are never used.
+// See: https://datatracker.ietf.org/doc/html/rfc7517#section-5
+func NewJWKSetResolver(data []byte) (*MemoryKeyResolver, error) {
+	keys, err := ParseJWKSet(data)
+	if err != nil {
+		return nil, err
+	}
+	resolver := NewMemoryKeyResolver()
+	for idx, jwk := range keys {
+		if jwk.KeyID == "" {
+			return nil, fmt.Errorf("invalid JWK Set: key %d has no key ID", idx)
+		}
+		resolver.Add(jwk.KeyID, jwk.Key)
+	}
+	return resolver, nil
+}
+
+// NewJWKSetFileResolver is like NewJWKSetResolver, but reads the JWK Set document from the file at the given path.
+func NewJWKSetFileResolver(path string) (*MemoryKeyResolver, error) {
+	if data, err := os.ReadFile(path); err != nil {
+		return nil, err
+	} else {
+		return NewJWKSetResolver(data)
+	}
+}
+
+// Add adds a key with the given key ID, replacing any previous key with the same ID. The key may be a public key, a
+// private key or a []byte symmetric key. Private keys can be used for both verification and decryption.
+func (r *MemoryKeyResolver) Add(kid string, key interface{}) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.keys[kid] = key
+}
+
+// ResolveSigningKey returns the private key for the `kid` header parameter.
+func (r *MemoryKeyResolver) ResolveSigningKey(_ context.Context, header map[string]interface{}) (crypto.PrivateKey, error) {
+	if key, err := r.lookup(header); err != nil {
+		return nil, err
+	} else if !isSigningKey(key) {
+		return nil, fmt.Errorf("key cannot be used for signing: %T", key)
+	} else {
+		return key, nil
+	}
+}
+
+// ResolveVerificationKey returns the public key for the `kid` header parameter.
+func (r *MemoryKeyResolver) ResolveVerificationKey(_ context.Context, header map[string]interface{}) (crypto.PublicKey, error) {
+	if key, err := r.lookup(header); err != nil {
+		return nil, err
+	} else if _, isSymmetric := key.([]byte); isSymmetric {
+		return nil, errors.New("symmetric keys cannot verify signatures")
+	} else {
+		_, publicKey := unwrapKey(key)
+		return publicKey, nil
+	}
+}
+
+// ResolveEncryptionKey returns the public or symmetric key for the `kid` header parameter.
+func (r *MemoryKeyResolver) ResolveEncryptionKey(_ context.Context, header map[string]interface{}) (crypto.PublicKey, error) {
+	if key, err := r.lookup(header); err != nil {
+		return nil, err
+	} else if symmetricKey, isSymmetric := key.([]byte); isSymmetric {
+		return symmetricKey, nil
+	} else {
+		_, publicKey := unwrapKey(key)
+		return publicKey, nil
+	}
+}
+
+// ResolveDecryptionKey returns the private or symmetric key for the `kid` header parameter.
+func (r *MemoryKeyResolver) ResolveDecryptionKey(_ context.Context, header map[string]interface{}) (crypto.PrivateKey, error) {
+	key, err := r.lookup(header)
+	if err != nil {
+		return nil, err
+	}
+	switch k := key.(type) {
+	case gojose.JSONWebKey:
+		key = k.Key
+	case *gojose.JSONWebKey:
+		key = k.Key
+	}
+	if !isDecryptionKey(key) {
+		return nil, fmt.Errorf("key cannot be used for decryption: %T", key)
+	} else {
+		return key, nil
+	}
+}
+
+func (r *MemoryKeyResolver) lookup(header map[string]interface{}) (interface{}, error) {
+	kid, _ := header["kid"].(string)
+	if kid == "" {
+		return nil, errors.New("missing `kid` header parameter")
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if key, found := r.keys[kid]; !found {
+		return nil, fmt.Errorf("unknown key ID: %s", kid)
+	} else {
+		return key, nil
+	}
+}
+
+func isSigningKey(key interface{}) bool {
+	switch k := key.(type) {
+	case gojose.JSONWebKey:
+		return isSigningKey(k.Key)
+	case *gojose.JSONWebKey:
+		return isSigningKey(k.Key)
+	case ed25519.PrivateKey, *ecdsa.PrivateKey, *secp256k1.PrivateKey, *rsa.PrivateKey:
+		return true
+	default:
+		return false
+	}
+}
+
+func isDecryptionKey(key interface{}) bool {
+	switch key.(type) {
+	case []byte, *ecdh.PrivateKey, *ecdsa.PrivateKey:
+		return true
+	default:
+		return false
+	}
+}
+
+// ChainedKeyResolver is a KeyResolver that tries each of its resolvers in turn, and returns the first key found.
+type ChainedKeyResolver []KeyResolver
+
+var _ KeyResolver = ChainedKeyResolver(nil)
+
+// ResolveSigningKey returns the first signing key found by one of the resolvers.
+func (c ChainedKeyResolver) ResolveSigningKey(ctx context.Context, header map[string]interface{}) (crypto.PrivateKey, error) {
+	errs := make([]error, 0, len(c))
+	for _, resolver := range c {
+		if key, err := resolver.ResolveSigningKey(ctx, header); err != nil {
+			errs = append(errs, err)
+		} else {
+			return key, nil
+		}
+	}
+	return nil, fmt.Errorf("unable to resolve signing key: %w", errors.Join(errs...))
+}
+
+// ResolveVerificationKey returns the first verification key found by one of the resolvers.
+func (c ChainedKeyResolver) ResolveVerificationKey(ctx context.Context, header map[string]interface{}) (crypto.PublicKey, error) {
+	errs := make([]error, 0, len(c))
+	for _, resolver := range c {
+		if key, err := resolver.ResolveVerificationKey(ctx, header); err != nil {
+			errs = append(errs, err)
+		} else {
+			return key, nil
+		}
+	}
+	return nil, fmt.Errorf("unable to resolve verification key: %w", errors.Join(errs...))
+}
+
+// ResolveEncryptionKey returns the first encryption key found by one of the resolvers.
+func (c ChainedKeyResolver) ResolveEncryptionKey(ctx context.Context, header map[string]interface{}) (crypto.PublicKey, error) {
+	errs := make([]error, 0, len(c))
+	for _, resolver := range c {
+		if key, err := resolver.ResolveEncryptionKey(ctx, header); err != nil {
+			errs = append(errs, err)
+		} else {
+			return key, nil
+		}
+	}
+	return nil, fmt.Errorf("unable to resolve encryption key: %w", errors.Join(errs...))
+}
+
+// ResolveDecryptionKey returns the first decryption key found by one of the resolvers.
+func (c ChainedKeyResolver) ResolveDecryptionKey(ctx context.Context, header map[string]interface{}) (crypto.PrivateKey, error) {
+	errs := make([]error, 0, len(c))
+	for _, resolver := range c {
+		if key, err := resolver.ResolveDecryptionKey(ctx, header); err != nil {
+			errs = append(errs, err)
+		} else {
+			return key, nil
+		}
+	}
+	return nil, fmt.Errorf("unable to resolve decryption key: %w", errors.Join(errs...))
+}
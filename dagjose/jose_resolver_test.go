@@ -0,0 +1,160 @@
+package dagjose
+
+import (
+	"context"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"os"
+	"path/filepath"
+	"testing"
+
+	gojose "github.com/go-jose/go-jose/v4"
+	"github.com/go-jose/go-jose/v4/json"
+	"github.com/ipld/go-ipld-prime/node/basicnode"
+	"github.com/stretchr/testify/require"
+)
+
+func requireResolverDecrypts(t *testing.T, jwe EncodedJWE, resolver KeyResolver) {
+	nb := basicnode.Prototype.Any.NewBuilder()
+	require.NoError(t, DecryptJWE(context.Background(), roundTripJWE(t, jwe), resolver, nb))
+	require.Equal(t, encodeDagCBOR(t, cleartextNode()), encodeDagCBOR(t, nb.Build()))
+}
+
+func TestMemoryKeyResolver(t *testing.T) {
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	edKey := ed25519PrivateKeyGen().Example()
+	resolver := NewMemoryKeyResolver()
+	resolver.Add("ec", ecKey)
+	resolver.Add("ed", edKey)
+
+	jws, err := SignCID(createCid([]byte("payload")),
+		Signer{Algorithm: gojose.ES256, Key: ecKey, KeyID: "ec"},
+		Signer{Algorithm: gojose.EdDSA, Key: edKey, KeyID: "ed"},
+		Signer{Algorithm: gojose.EdDSA, Key: edKey, KeyID: "unknown"},
+	)
+	require.NoError(t, err)
+	results, err := VerifyJWSWithResolver(context.Background(), roundTripJWS(t, jws), resolver)
+	require.NoError(t, err)
+	require.True(t, results[0].Valid(), "%v", results[0].Err)
+	require.True(t, results[1].Valid(), "%v", results[1].Err)
+	require.False(t, results[2].Valid())
+
+	jwe, err := EncryptNode(cleartextNode(), EncryptOptions{Encryption: gojose.A256GCM},
+		Recipient{Algorithm: gojose.ECDH_ES_A256KW, Key: &ecKey.PublicKey, KeyID: "ec"},
+	)
+	require.NoError(t, err)
+	requireResolverDecrypts(t, jwe, resolver)
+
+	// Signing keys that cannot decrypt are rejected
+	_, err = resolver.ResolveDecryptionKey(context.Background(), map[string]interface{}{"kid": "ed"})
+	require.Error(t, err)
+	_, err = resolver.ResolveDecryptionKey(context.Background(), map[string]interface{}{})
+	require.Error(t, err)
+}
+
+func TestJWKSetFileResolver(t *testing.T) {
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	symmetricKey := make([]byte, 32)
+	_, err = rand.Read(symmetricKey)
+	require.NoError(t, err)
+	set, err := json.Marshal(gojose.JSONWebKeySet{Keys: []gojose.JSONWebKey{
+		{Key: ecKey, KeyID: "ec", Algorithm: string(gojose.ES256)},
+		{Key: symmetricKey, KeyID: "symmetric"},
+	}})
+	require.NoError(t, err)
+	path := filepath.Join(t.TempDir(), "keys.json")
+	require.NoError(t, os.WriteFile(path, set, 0o600))
+
+	resolver, err := NewJWKSetFileResolver(path)
+	require.NoError(t, err)
+	jws, err := SignCID(createCid([]byte("payload")), Signer{Algorithm: gojose.ES256, Key: ecKey, KeyID: "ec"})
+	require.NoError(t, err)
+	results, err := VerifyJWSWithResolver(context.Background(), roundTripJWS(t, jws), resolver)
+	require.NoError(t, err)
+	require.True(t, results[0].Valid(), "%v", results[0].Err)
+
+	jwe, err := EncryptNode(cleartextNode(), EncryptOptions{Encryption: gojose.A256GCM},
+		Recipient{Algorithm: gojose.A256KW, Key: symmetricKey, KeyID: "symmetric"},
+	)
+	require.NoError(t, err)
+	requireResolverDecrypts(t, jwe, resolver)
+
+	// Every key must have a key ID
+	set, err = json.Marshal(gojose.JSONWebKeySet{Keys: []gojose.JSONWebKey{{Key: symmetricKey}}})
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(path, set, 0o600))
+	_, err = NewJWKSetFileResolver(path)
+	require.Error(t, err)
+	_, err = NewJWKSetFileResolver(filepath.Join(t.TempDir(), "missing.json"))
+	require.Error(t, err)
+}
+
+func TestChainedKeyResolver(t *testing.T) {
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	memory := NewMemoryKeyResolver()
+	memory.Add("ec", ecKey)
+	edKey := ed25519PrivateKeyGen().Example()
+	did := didKey(t, multicodecEd25519Pub, edKey.Public().(ed25519.PublicKey))
+	resolver := ChainedKeyResolver{DIDKeyResolver{}, memory}
+
+	jws, err := SignCID(createCid([]byte("payload")),
+		Signer{Algorithm: gojose.ES256, Key: ecKey, KeyID: "ec"},
+		Signer{Algorithm: gojose.EdDSA, Key: edKey, KeyID: did},
+	)
+	require.NoError(t, err)
+	results, err := VerifyJWSWithResolver(context.Background(), roundTripJWS(t, jws), resolver)
+	require.NoError(t, err)
+	require.True(t, results[0].Valid(), "%v", results[0].Err)
+	require.True(t, results[1].Valid(), "%v", results[1].Err)
+
+	jwe, err := EncryptNode(cleartextNode(), EncryptOptions{Encryption: gojose.A256GCM},
+		Recipient{Algorithm: gojose.ECDH_ES_A256KW, Key: &ecKey.PublicKey, KeyID: "ec"},
+	)
+	require.NoError(t, err)
+	requireResolverDecrypts(t, jwe, resolver)
+
+	_, err = resolver.ResolveVerificationKey(context.Background(), map[string]interface{}{"kid": "unknown"})
+	require.Error(t, err)
+}
+
+// Signers and recipients without keys should be resolved by their key ID
+func TestSignAndEncryptWithResolver(t *testing.T) {
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	xKey, err := ecdh.X25519().GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	memory := NewMemoryKeyResolver()
+	memory.Add("ec", ecKey)
+	memory.Add("x", xKey)
+	did := didKey(t, multicodecX25519Pub, xKey.PublicKey().Bytes())
+	resolver := ChainedKeyResolver{DIDKeyResolver{}, memory}
+	ctx := context.Background()
+
+	jws, err := SignCIDWithResolver(ctx, createCid([]byte("payload")), resolver, Signer{Algorithm: gojose.ES256, KeyID: "ec"})
+	require.NoError(t, err)
+	results, err := VerifyJWSWithResolver(ctx, roundTripJWS(t, jws), resolver)
+	require.NoError(t, err)
+	require.True(t, results[0].Valid(), "%v", results[0].Err)
+	_, err = SignCIDWithResolver(ctx, createCid([]byte("payload")), resolver, Signer{Algorithm: gojose.ES256, KeyID: did})
+	require.Error(t, err)
+
+	for _, kid := range []string{"x", did} {
+		jwe, err := EncryptNodeWithResolver(ctx, cleartextNode(), EncryptOptions{Encryption: XC20P}, resolver,
+			Recipient{Algorithm: gojose.ECDH_ES_A256KW, KeyID: kid},
+		)
+		require.NoError(t, err)
+		nb := basicnode.Prototype.Any.NewBuilder()
+		require.NoError(t, DecryptJWE(ctx, roundTripJWE(t, jwe), staticKey(xKey), nb))
+		require.Equal(t, encodeDagCBOR(t, cleartextNode()), encodeDagCBOR(t, nb.Build()))
+	}
+	_, err = EncryptNodeWithResolver(ctx, cleartextNode(), EncryptOptions{}, resolver,
+		Recipient{Algorithm: gojose.ECDH_ES_A256KW, KeyID: "unknown"},
+	)
+	require.Error(t, err)
+}
@@ -0,0 +1,210 @@
+package dagjose
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"errors"
+	"fmt"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	gojose "github.com/go-jose/go-jose/v4"
+	"github.com/go-jose/go-jose/v4/json"
+	"github.com/ipfs/go-cid"
+	"github.com/ipld/go-ipld-prime/datamodel"
+	"github.com/ipld/go-ipld-prime/fluent"
+	"github.com/ipld/go-ipld-prime/node/basicnode"
+	"github.com/ipld/go-ipld-prime/schema"
+)
+
+// Signer describes a private key used to produce one signature of a JWS.
+type Signer struct {
+	// Algorithm is the JWS `alg` used to sign, e.g. EdDSA, ES256 or ES256K.
+	Algorithm gojose.SignatureAlgorithm
+	// Key is the private key used to sign. It may be an ed25519.PrivateKey, *ecdsa.PrivateKey, *secp256k1.PrivateKey,
+	// *rsa.PrivateKey or a go-jose JSONWebKey wrapping one of these.
+	Key crypto.PrivateKey
+	// KeyID, if not empty, is added to the protected header as `kid`. If empty and Key is a JSONWebKey with a key ID,
+	// that key ID is used instead.
+	KeyID string
+	// Protected contains additional parameters for the protected header. `alg` and `kid` are always set from the
+	// fields above.
+	Protected map[string]interface{}
+	// Header contains parameters for the unprotected header, if any.
+	Header map[string]interface{}
+}
+
+// SignCID signs the given CID with each of the given signers and returns the resulting JWS, ready to be stored with the
+// dag-jose codec. The payload of the JWS is the binary CID, and the signatures appear in the same order as the signers.
+//
+// DAG-JOSE always stores the general serialization, so the returned node is in general form regardless of the number of
+// signers. Use SignCIDNode to get the flattened form for a single signer.
+//
+// A signer with `b64: false` and `crit: ["b64"]` in its protected header signs the binary CID as is, instead of its
+// base64url encoding. The JWS is stored in the same way, but is exported with a detached payload.
+func SignCID(c cid.Cid, signers ...Signer) (EncodedJWS, error) {
+	if !c.Defined() {
+		return nil, errors.New("cannot sign an undefined CID")
+	}
+	if len(signers) == 0 {
+		return nil, errors.New("at least one signer is required")
+	}
+	payload := c.Bytes()
+	signatures := make([]_EncodedSignature, 0, len(signers))
+	for _, signer := range signers {
+		if signature, err := signer.sign(payload); err != nil {
+			return nil, err
+		} else {
+			signatures = append(signatures, *signature)
+		}
+	}
+	return &_EncodedJWS{
+		payload:    _Raw{payload},
+		signatures: _EncodedSignatures__Maybe{m: schema.Maybe_Value, v: _EncodedSignatures{signatures}},
+	}, nil
+}
+
+// SignCIDNode is like SignCID, but returns the JWS in the serialization selected by the given mode. With FlattenAuto,
+// the JWS is in flattened form when there is exactly one signer and in general form otherwise. As with objects built by
+// hand, binary fields of the flattened form are base64url-encoded strings, and the node can be passed to Encode as is.
+func SignCIDNode(c cid.Cid, mode FlattenMode, signers ...Signer) (datamodel.Node, error) {
+	jws, err := SignCID(c, signers...)
+	if err != nil {
+		return nil, err
+	}
+	signatures := jws.signatures.v.x
+	if flatten, err := shouldFlatten(mode, len(signatures)); err != nil {
+		return nil, err
+	} else if !flatten {
+		return jws.Representation(), nil
+	}
+	signature := &signatures[0]
+	return fluent.BuildMap(basicnode.Prototype.Map, 4, func(ma fluent.MapAssembler) {
+		ma.AssembleEntry("payload").AssignString(encodeBase64Url(jws.payload.x))
+		if signature.header.Exists() {
+			ma.AssembleEntry("header").AssignNode(signature.header.v.Representation())
+		}
+		if signature.protected.Exists() {
+			ma.AssembleEntry("protected").AssignString(encodeBase64Url(signature.protected.v.x))
+		}
+		ma.AssembleEntry("signature").AssignString(encodeBase64Url(signature.signature.x))
+	})
+}
+
+func (s Signer) sign(payload []byte) (*_EncodedSignature, error) {
+	key, kid := s.Key, s.KeyID
+	switch k := key.(type) {
+	case gojose.JSONWebKey:
+		key = k.Key
+		if kid == "" {
+			kid = k.KeyID
+		}
+	case *gojose.JSONWebKey:
+		key = k.Key
+		if kid == "" {
+			kid = k.KeyID
+		}
+	}
+	protected := make(map[string]interface{}, len(s.Protected)+2)
+	for k, v := range s.Protected {
+		protected[k] = v
+	}
+	protected["alg"] = string(s.Algorithm)
+	if kid != "" {
+		protected["kid"] = kid
+	}
+	for k := range s.Header {
+		if _, found := protected[k]; found {
+			return nil, fmt.Errorf("duplicate header parameter: %s", k)
+		} else if k == "crit" || k == "b64" {
+			return nil, fmt.Errorf("`%s` header parameter must be protected", k)
+		}
+	}
+	protectedBytes, err := json.Marshal(protected)
+	if err != nil {
+		return nil, err
+	}
+	signingInput, err := jwsSigningInput(_Base64Url__Maybe{m: schema.Maybe_Value, v: _Base64Url{string(protectedBytes)}}, payload)
+	if err != nil {
+		return nil, err
+	}
+	signature, err := signWithKey(s.Algorithm, key, signingInput)
+	if err != nil {
+		return nil, err
+	}
+	encodedSignature := &_EncodedSignature{
+		protected: _Raw__Maybe{m: schema.Maybe_Value, v: _Raw{protectedBytes}},
+		signature: _Raw{signature},
+	}
+	if len(s.Header) > 0 {
+		if header, err := maybeAny(s.Header); err != nil {
+			return nil, err
+		} else {
+			encodedSignature.header = header
+		}
+	}
+	return encodedSignature, nil
+}
+
+func signWithKey(alg gojose.SignatureAlgorithm, key crypto.PrivateKey, signingInput []byte) ([]byte, error) {
+	switch alg {
+	case gojose.EdDSA:
+		if privateKey, castOk := key.(ed25519.PrivateKey); !castOk {
+			return nil, fmt.Errorf("invalid key type for %s: %T", alg, key)
+		} else {
+			return ed25519.Sign(privateKey, signingInput), nil
+		}
+	case gojose.ES256, gojose.ES384, gojose.ES512:
+		if privateKey, castOk := key.(*ecdsa.PrivateKey); !castOk {
+			return nil, fmt.Errorf("invalid key type for %s: %T", alg, key)
+		} else {
+			return signECDSA(privateKey, hashFor(alg), signingInput)
+		}
+	case ES256K:
+		if privateKey, castOk := key.(*secp256k1.PrivateKey); !castOk {
+			return nil, fmt.Errorf("invalid key type for %s: %T", alg, key)
+		} else {
+			return signES256K(privateKey, signingInput), nil
+		}
+	case gojose.RS256, gojose.RS384, gojose.RS512, gojose.PS256, gojose.PS384, gojose.PS512:
+		if privateKey, castOk := key.(*rsa.PrivateKey); !castOk {
+			return nil, fmt.Errorf("invalid key type for %s: %T", alg, key)
+		} else {
+			return signRSA(alg, privateKey, signingInput)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported signature algorithm: %s", alg)
+	}
+}
+
+func signECDSA(privateKey *ecdsa.PrivateKey, hash crypto.Hash, signingInput []byte) ([]byte, error) {
+	hasher := hash.New()
+	hasher.Write(signingInput)
+	r, s, err := ecdsa.Sign(rand.Reader, privateKey, hasher.Sum(nil))
+	if err != nil {
+		return nil, err
+	}
+	// ECDSA signatures are the fixed-length concatenation of the big-endian R and S values.
+	// See: https://datatracker.ietf.org/doc/html/rfc7518#section-3.4
+	keySize := (privateKey.Curve.Params().BitSize + 7) / 8
+	signature := make([]byte, 2*keySize)
+	r.FillBytes(signature[:keySize])
+	s.FillBytes(signature[keySize:])
+	return signature, nil
+}
+
+func signRSA(alg gojose.SignatureAlgorithm, privateKey *rsa.PrivateKey, signingInput []byte) ([]byte, error) {
+	hash := hashFor(alg)
+	hasher := hash.New()
+	hasher.Write(signingInput)
+	switch alg {
+	case gojose.PS256, gojose.PS384, gojose.PS512:
+		return rsa.SignPSS(rand.Reader, privateKey, hash, hasher.Sum(nil), &rsa.PSSOptions{
+			SaltLength: rsa.PSSSaltLengthEqualsHash,
+		})
+	default:
+		return rsa.SignPKCS1v15(rand.Reader, privateKey, hash, hasher.Sum(nil))
+	}
+}
@@ -0,0 +1,145 @@
+package dagjose
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"testing"
+
+	gojose "github.com/go-jose/go-jose/v4"
+	"github.com/ipld/go-ipld-prime/datamodel"
+	"github.com/ipld/go-ipld-prime/node/basicnode"
+	"github.com/stretchr/testify/require"
+	"pgregory.net/rapid"
+)
+
+// Encode the JWS to DAG-JOSE bytes and back, returning the decoded node
+func roundTripJWS(t require.TestingT, jws EncodedJWS) datamodel.Node {
+	buf := bytes.Buffer{}
+	require.NoError(t, Encode(jws, &buf))
+	nb := basicnode.Prototype.Any.NewBuilder()
+	require.NoError(t, Decode(nb, &buf))
+	return nb.Build()
+}
+
+// A JWS produced by SignCID should survive a round trip through the codec and verify with the signer's public key
+func TestSignCIDRoundTrip(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		link := cidGen().Draw(t, "payload")
+		privateKey := ed25519PrivateKeyGen().Draw(t, "private key")
+		jws, err := SignCID(link, Signer{Algorithm: gojose.EdDSA, Key: privateKey, KeyID: "key-1"})
+		require.NoError(t, err)
+
+		decoded := roundTripJWS(t, jws)
+
+		linkNode, err := decoded.LookupByString("link")
+		require.NoError(t, err)
+		decodedLink, err := linkNode.AsLink()
+		require.NoError(t, err)
+		require.Equal(t, link.String(), decodedLink.String())
+
+		results, err := VerifyJWS(decoded, privateKey.Public())
+		require.NoError(t, err)
+		require.Len(t, results, 1)
+		require.True(t, results[0].Valid(), "%v", results[0].Err)
+		require.Equal(t, "key-1", results[0].KeyID)
+	})
+}
+
+func TestSignCIDMultipleSigners(t *testing.T) {
+	link := createCid([]byte("payload"))
+	edKey := ed25519PrivateKeyGen().Example()
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	jws, err := SignCID(link,
+		Signer{Algorithm: gojose.EdDSA, Key: edKey},
+		Signer{Algorithm: gojose.ES256, Key: ecKey, Header: map[string]interface{}{"note": "second"}},
+	)
+	require.NoError(t, err)
+	require.Equal(t, int64(2), jws.FieldSignatures().Must().Length())
+
+	results, err := VerifyJWS(jws, edKey.Public(), ecKey.Public())
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	for _, result := range results {
+		require.True(t, result.Valid(), "%v", result.Err)
+	}
+	require.Equal(t, gojose.ES256, results[1].Algorithm)
+}
+
+func TestSignCIDRejectsMismatchedKey(t *testing.T) {
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	_, err = SignCID(createCid([]byte("payload")), Signer{Algorithm: gojose.EdDSA, Key: ecKey})
+	require.Error(t, err)
+	_, err = SignCID(createCid([]byte("payload")))
+	require.Error(t, err)
+}
+
+func TestSignCIDHeaderValueTypes(t *testing.T) {
+	edKey := ed25519PrivateKeyGen().Example()
+	header := map[string]interface{}{
+		"bytes":   []byte{1, 2, 3},
+		"strings": []string{"a", "b"},
+		"nested":  map[string]int{"n": 1},
+	}
+	jws, err := SignCID(createCid([]byte("payload")), Signer{Algorithm: gojose.EdDSA, Key: edKey, Header: header})
+	require.NoError(t, err)
+
+	decoded := roundTripJWS(t, jws)
+	signatures, err := decoded.LookupByString("signatures")
+	require.NoError(t, err)
+	signature, err := signatures.LookupByIndex(0)
+	require.NoError(t, err)
+	headerNode, err := signature.LookupByString("header")
+	require.NoError(t, err)
+	value, err := goValueFromNode(headerNode)
+	require.NoError(t, err)
+	require.Equal(t, map[string]interface{}{
+		"bytes":   []byte{1, 2, 3},
+		"strings": []interface{}{"a", "b"},
+		"nested":  map[string]interface{}{"n": int64(1)},
+	}, value)
+
+	results, err := VerifyJWS(decoded, edKey.Public())
+	require.NoError(t, err)
+	require.True(t, results[0].Valid(), "%v", results[0].Err)
+}
+
+func TestSignCIDNodeFlattenModes(t *testing.T) {
+	link := createCid([]byte("payload"))
+	edKey := ed25519PrivateKeyGen().Example()
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	signer := Signer{Algorithm: gojose.EdDSA, Key: edKey, Header: map[string]interface{}{"note": "flattened"}}
+
+	flattened, err := SignCIDNode(link, FlattenAuto, signer)
+	require.NoError(t, err)
+	for _, key := range []string{"payload", "protected", "signature", "header"} {
+		_, err := flattened.LookupByString(key)
+		require.NoError(t, err, key)
+	}
+	_, err = flattened.LookupByString("signatures")
+	require.Error(t, err)
+	buf := bytes.Buffer{}
+	require.NoError(t, Encode(flattened, &buf))
+	nb := basicnode.Prototype.Any.NewBuilder()
+	require.NoError(t, Decode(nb, &buf))
+	results, err := VerifyJWS(nb.Build(), edKey.Public())
+	require.NoError(t, err)
+	require.True(t, results[0].Valid(), "%v", results[0].Err)
+
+	general, err := SignCIDNode(link, FlattenNever, signer)
+	require.NoError(t, err)
+	_, err = general.LookupByString("signatures")
+	require.NoError(t, err)
+
+	general, err = SignCIDNode(link, FlattenAuto, signer, Signer{Algorithm: gojose.ES256, Key: ecKey})
+	require.NoError(t, err)
+	_, err = general.LookupByString("signatures")
+	require.NoError(t, err)
+
+	_, err = SignCIDNode(link, FlattenAlways, signer, Signer{Algorithm: gojose.ES256, Key: ecKey})
+	require.Error(t, err)
+}
@@ -0,0 +1,465 @@
+package dagjose
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+
+	"github.com/ipfs/go-cid"
+	"github.com/ipld/go-ipld-prime/codec/dagjson"
+	"github.com/ipld/go-ipld-prime/datamodel"
+	cidlink "github.com/ipld/go-ipld-prime/linking/cid"
+	"github.com/ipld/go-ipld-prime/node/basicnode"
+	"github.com/ipld/go-ipld-prime/schema"
+)
+
+// JWS is a plain Go representation of a DAG-JOSE JWS, for use without the IPLD node API.
+//
+// Header values are represented with nil for null, bool, int64, float64, string, []byte, cid.Cid for links,
+// []interface{} and map[string]interface{}.
+type JWS struct {
+	// Payload is the CID the JWS signs.
+	Payload cid.Cid
+	// Signatures are the signatures of the JWS, in order. If nil, the `signatures` field is omitted.
+	Signatures []Signature
+}
+
+// Signature is one signature of a JWS.
+type Signature struct {
+	// Protected is the JSON-encoded protected header, or nil if the signature has none.
+	Protected []byte
+	// Header contains the unprotected header parameters, or is nil if the signature has none.
+	Header map[string]interface{}
+	// Signature is the signature value.
+	Signature []byte
+}
+
+// JWE is a plain Go representation of a DAG-JOSE JWE, for use without the IPLD node API. Byte fields that are nil are
+// omitted. Header values are represented in the same way as for JWS.
+type JWE struct {
+	// Protected is the JSON-encoded protected header, or nil if the JWE has none.
+	Protected []byte
+	// Unprotected contains the shared unprotected header parameters, or is nil if the JWE has none.
+	Unprotected map[string]interface{}
+	// Recipients are the recipients of the JWE, in order. If nil, the `recipients` field is omitted.
+	Recipients []JWERecipient
+	// AAD is the additional authenticated data, if any.
+	AAD []byte
+	// IV is the initialization vector, if any.
+	IV []byte
+	// Ciphertext is the encrypted content.
+	Ciphertext []byte
+	// Tag is the authentication tag, if any.
+	Tag []byte
+}
+
+// JWERecipient is one recipient of a JWE.
+type JWERecipient struct {
+	// Header contains the per-recipient unprotected header parameters, or is nil if the recipient has none.
+	Header map[string]interface{}
+	// EncryptedKey is the encrypted content encryption key, or nil for direct key agreement or encryption.
+	EncryptedKey []byte
+}
+
+// ProtectedHeader returns the parsed protected header of the signature. If the signature has no protected header, an
+// empty header is returned.
+func (s *Signature) ProtectedHeader() (*ProtectedHeader, error) {
+	return parseProtectedHeader(maybeBase64Url(s.Protected))
+}
+
+// ProtectedHeader returns the parsed protected header of the JWE. If the JWE has no protected header, an empty header
+// is returned.
+func (j *JWE) ProtectedHeader() (*ProtectedHeader, error) {
+	return parseProtectedHeader(maybeBase64Url(j.Protected))
+}
+
+// ToNode returns the JWS as an EncodedJWS node, ready to be stored with the dag-jose codec.
+func (j *JWS) ToNode() (datamodel.Node, error) {
+	if !j.Payload.Defined() {
+		return nil, errors.New("JWS payload is not defined")
+	}
+	jws := &_EncodedJWS{payload: _Raw{j.Payload.Bytes()}}
+	if j.Signatures == nil {
+		return jws, nil
+	}
+	signatures := make([]_EncodedSignature, 0, len(j.Signatures))
+	for _, sig := range j.Signatures {
+		encoded := _EncodedSignature{
+			protected: maybeRaw(sig.Protected),
+			signature: _Raw{sig.Signature},
+		}
+		if header, err := maybeAny(sig.Header); err != nil {
+			return nil, err
+		} else {
+			encoded.header = header
+		}
+		signatures = append(signatures, encoded)
+	}
+	jws.signatures = _EncodedSignatures__Maybe{m: schema.Maybe_Value, v: _EncodedSignatures{signatures}}
+	return jws, nil
+}
+
+// FromNode sets the JWS from the given node, which may be the output of Decode, or any general or flattened JWS node.
+func (j *JWS) FromNode(n datamodel.Node) error {
+	jws, err := asDecodedJWS(n)
+	if err != nil {
+		return err
+	}
+	payload, err := payloadCID([]byte(jws.payload.x))
+	if err != nil {
+		return err
+	}
+	var signatures []Signature
+	if jws.signatures.Exists() {
+		signatures = make([]Signature, 0, len(jws.signatures.v.x))
+		for _, sig := range jws.signatures.v.x {
+			header, err := headerFromMaybeAny(sig.header)
+			if err != nil {
+				return err
+			}
+			signatures = append(signatures, Signature{
+				Protected: bytesFromMaybeBase64Url(sig.protected),
+				Header:    header,
+				Signature: []byte(sig.signature.x),
+			})
+		}
+	}
+	*j = JWS{Payload: payload, Signatures: signatures}
+	return nil
+}
+
+// MarshalBinary encodes the JWS as a dag-jose block.
+func (j *JWS) MarshalBinary() ([]byte, error) {
+	if n, err := j.ToNode(); err != nil {
+		return nil, err
+	} else {
+		buf := bytes.Buffer{}
+		if err := EncodeJWS(n, &buf); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	}
+}
+
+// UnmarshalBinary decodes the JWS from a dag-jose block.
+func (j *JWS) UnmarshalBinary(data []byte) error {
+	nb := Type.DecodedJWS__Repr.NewBuilder()
+	if err := (DecodeOptions{}.DecodeJWS(nb, bytes.NewReader(data))); err != nil {
+		return err
+	}
+	return j.FromNode(nb.Build())
+}
+
+// ToNode returns the JWE as an EncodedJWE node, ready to be stored with the dag-jose codec.
+func (j *JWE) ToNode() (datamodel.Node, error) {
+	if j.Ciphertext == nil {
+		return nil, errors.New("JWE ciphertext is missing")
+	}
+	jwe := &_EncodedJWE{
+		aad:        maybeRaw(j.AAD),
+		ciphertext: _Raw{j.Ciphertext},
+		iv:         maybeRaw(j.IV),
+		protected:  maybeRaw(j.Protected),
+		tag:        maybeRaw(j.Tag),
+	}
+	if unprotected, err := maybeAny(j.Unprotected); err != nil {
+		return nil, err
+	} else {
+		jwe.unprotected = unprotected
+	}
+	if j.Recipients != nil {
+		recipients := make([]_EncodedRecipient, 0, len(j.Recipients))
+		for _, recipient := range j.Recipients {
+			encoded := _EncodedRecipient{encrypted_key: maybeRaw(recipient.EncryptedKey)}
+			if header, err := maybeAny(recipient.Header); err != nil {
+				return nil, err
+			} else {
+				encoded.header = header
+			}
+			recipients = append(recipients, encoded)
+		}
+		jwe.recipients = _EncodedRecipients__Maybe{m: schema.Maybe_Value, v: _EncodedRecipients{recipients}}
+	}
+	return jwe, nil
+}
+
+// FromNode sets the JWE from the given node, which may be the output of Decode, or any general or flattened JWE node.
+func (j *JWE) FromNode(n datamodel.Node) error {
+	jwe, err := asDecodedJWE(n)
+	if err != nil {
+		return err
+	}
+	unprotected, err := headerFromMaybeAny(jwe.unprotected)
+	if err != nil {
+		return err
+	}
+	var recipients []JWERecipient
+	if jwe.recipients.Exists() {
+		recipients = make([]JWERecipient, 0, len(jwe.recipients.v.x))
+		for _, recipient := range jwe.recipients.v.x {
+			header, err := headerFromMaybeAny(recipient.header)
+			if err != nil {
+				return err
+			}
+			recipients = append(recipients, JWERecipient{
+				Header:       header,
+				EncryptedKey: bytesFromMaybeBase64Url(recipient.encrypted_key),
+			})
+		}
+	}
+	*j = JWE{
+		Protected:   bytesFromMaybeBase64Url(jwe.protected),
+		Unprotected: unprotected,
+		Recipients:  recipients,
+		AAD:         bytesFromMaybeBase64Url(jwe.aad),
+		IV:          bytesFromMaybeBase64Url(jwe.iv),
+		Ciphertext:  []byte(jwe.ciphertext.x),
+		Tag:         bytesFromMaybeBase64Url(jwe.tag),
+	}
+	return nil
+}
+
+// MarshalBinary encodes the JWE as a dag-jose block.
+func (j *JWE) MarshalBinary() ([]byte, error) {
+	if n, err := j.ToNode(); err != nil {
+		return nil, err
+	} else {
+		buf := bytes.Buffer{}
+		if err := EncodeJWE(n, &buf); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	}
+}
+
+// UnmarshalBinary decodes the JWE from a dag-jose block.
+func (j *JWE) UnmarshalBinary(data []byte) error {
+	nb := Type.DecodedJWE__Repr.NewBuilder()
+	if err := (DecodeOptions{}.DecodeJWE(nb, bytes.NewReader(data))); err != nil {
+		return err
+	}
+	return j.FromNode(nb.Build())
+}
+
+func maybeRaw(b []byte) _Raw__Maybe {
+	if b == nil {
+		return _Raw__Maybe{m: schema.Maybe_Absent}
+	}
+	return _Raw__Maybe{m: schema.Maybe_Value, v: _Raw{b}}
+}
+
+func maybeBase64Url(b []byte) _Base64Url__Maybe {
+	if b == nil {
+		return _Base64Url__Maybe{m: schema.Maybe_Absent}
+	}
+	return _Base64Url__Maybe{m: schema.Maybe_Value, v: _Base64Url{string(b)}}
+}
+
+func bytesFromMaybeBase64Url(m _Base64Url__Maybe) []byte {
+	if !m.Exists() {
+		return nil
+	}
+	return []byte(m.v.x)
+}
+
+func maybeAny(header map[string]interface{}) (_Any__Maybe, error) {
+	if header == nil {
+		return _Any__Maybe{m: schema.Maybe_Absent}, nil
+	}
+	nb := basicnode.Prototype.Any.NewBuilder()
+	if err := assembleGoValue(nb, header); err != nil {
+		return _Any__Maybe{}, err
+	}
+	anyBuilder := Type.Any__Repr.NewBuilder()
+	if err := datamodel.Copy(nb.Build(), anyBuilder); err != nil {
+		return _Any__Maybe{}, err
+	}
+	return _Any__Maybe{m: schema.Maybe_Value, v: anyBuilder.Build().(Any)}, nil
+}
+
+func headerFromMaybeAny(m _Any__Maybe) (map[string]interface{}, error) {
+	if !m.Exists() {
+		return nil, nil
+	}
+	if value, err := goValueFromNode(m.v); err != nil {
+		return nil, err
+	} else if header, castOk := value.(map[string]interface{}); !castOk {
+		return nil, fmt.Errorf("header is not a map: %T", value)
+	} else {
+		return header, nil
+	}
+}
+
+// assembleGoValue assembles a Go value made of the types listed in the JWS documentation. Other scalar, slice and map
+// types, such as []string, are accepted as well, and values implementing json.Marshaler, such as go-jose JSONWebKey,
+// are assembled from their JSON representation.
+func assembleGoValue(na datamodel.NodeAssembler, value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		return na.AssignNull()
+	case bool:
+		return na.AssignBool(v)
+	case int:
+		return na.AssignInt(int64(v))
+	case int64:
+		return na.AssignInt(v)
+	case float64:
+		return na.AssignFloat(v)
+	case string:
+		return na.AssignString(v)
+	case []byte:
+		return na.AssignBytes(v)
+	case cid.Cid:
+		if !v.Defined() {
+			return errors.New("cannot assemble an undefined CID")
+		}
+		return na.AssignLink(cidlink.Link{Cid: v})
+	case []interface{}:
+		la, err := na.BeginList(int64(len(v)))
+		if err != nil {
+			return err
+		}
+		for _, entry := range v {
+			if err := assembleGoValue(la.AssembleValue(), entry); err != nil {
+				return err
+			}
+		}
+		return la.Finish()
+	case map[string]interface{}:
+		ma, err := na.BeginMap(int64(len(v)))
+		if err != nil {
+			return err
+		}
+		// Assemble entries in a stable order, since map iteration order is random
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Slice(keys, func(i, j int) bool { return canonicalKeyLess(keys[i], keys[j]) })
+		for _, key := range keys {
+			if err := ma.AssembleKey().AssignString(key); err != nil {
+				return err
+			} else if err := assembleGoValue(ma.AssembleValue(), v[key]); err != nil {
+				return err
+			}
+		}
+		return ma.Finish()
+	case jsonMarshaler:
+		if jsonBytes, err := v.MarshalJSON(); err != nil {
+			return err
+		} else {
+			return dagjson.Decode(na, bytes.NewReader(jsonBytes))
+		}
+	}
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.Bool:
+		return na.AssignBool(rv.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return na.AssignInt(rv.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if rv.Uint() > math.MaxInt64 {
+			return fmt.Errorf("integer header value out of range: %d", rv.Uint())
+		}
+		return na.AssignInt(int64(rv.Uint()))
+	case reflect.Float32:
+		return na.AssignFloat(rv.Float())
+	case reflect.String:
+		return na.AssignString(rv.String())
+	case reflect.Slice, reflect.Array:
+		list := make([]interface{}, rv.Len())
+		for idx := range list {
+			list[idx] = rv.Index(idx).Interface()
+		}
+		return assembleGoValue(na, list)
+	case reflect.Map:
+		if rv.Type().Key().Kind() == reflect.String {
+			m := make(map[string]interface{}, rv.Len())
+			itr := rv.MapRange()
+			for itr.Next() {
+				m[itr.Key().String()] = itr.Value().Interface()
+			}
+			return assembleGoValue(na, m)
+		}
+	}
+	return fmt.Errorf("unsupported header value type: %T", value)
+}
+
+// jsonMarshaler is implemented by values that know how to serialize themselves to JSON.
+type jsonMarshaler interface {
+	MarshalJSON() ([]byte, error)
+}
+
+// goValueFromNode converts a node to a Go value made of the types listed in the JWS documentation.
+func goValueFromNode(n datamodel.Node) (interface{}, error) {
+	if tn, castOk := n.(schema.TypedNode); castOk {
+		n = tn.Representation()
+	}
+	switch n.Kind() {
+	case datamodel.Kind_Null:
+		return nil, nil
+	case datamodel.Kind_Bool:
+		return n.AsBool()
+	case datamodel.Kind_Int:
+		return n.AsInt()
+	case datamodel.Kind_Float:
+		if f, err := n.AsFloat(); err != nil {
+			return nil, err
+		} else if math.IsNaN(f) || math.IsInf(f, 0) {
+			return nil, fmt.Errorf("unsupported float value: %v", f)
+		} else {
+			return f, nil
+		}
+	case datamodel.Kind_String:
+		return n.AsString()
+	case datamodel.Kind_Bytes:
+		return n.AsBytes()
+	case datamodel.Kind_Link:
+		if lnk, err := n.AsLink(); err != nil {
+			return nil, err
+		} else if cl, castOk := lnk.(cidlink.Link); !castOk {
+			return nil, fmt.Errorf("unsupported link type: %T", lnk)
+		} else {
+			return cl.Cid, nil
+		}
+	case datamodel.Kind_List:
+		list := make([]interface{}, 0, n.Length())
+		itr := n.ListIterator()
+		for !itr.Done() {
+			_, v, err := itr.Next()
+			if err != nil {
+				return nil, err
+			}
+			if value, err := goValueFromNode(v); err != nil {
+				return nil, err
+			} else {
+				list = append(list, value)
+			}
+		}
+		return list, nil
+	case datamodel.Kind_Map:
+		m := make(map[string]interface{}, n.Length())
+		itr := n.MapIterator()
+		for !itr.Done() {
+			k, v, err := itr.Next()
+			if err != nil {
+				return nil, err
+			}
+			key, err := k.AsString()
+			if err != nil {
+				return nil, err
+			}
+			if value, err := goValueFromNode(v); err != nil {
+				return nil, err
+			} else {
+				m[key] = value
+			}
+		}
+		return m, nil
+	default:
+		return nil, fmt.Errorf("unsupported node kind: %s", n.Kind())
+	}
+}
@@ -0,0 +1,99 @@
+package dagjose
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	gojose "github.com/go-jose/go-jose/v4"
+	"github.com/ipld/go-ipld-prime/node/basicnode"
+	"github.com/stretchr/testify/require"
+	"pgregory.net/rapid"
+)
+
+// Blocks decoded into the Go structs should encode back to the same bytes
+func TestStructsRoundTripBlocks(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		buf := bytes.Buffer{}
+		require.NoError(t, Encode(jwsGen(-1).Draw(t, "JWS"), &buf))
+		var jws JWS
+		require.NoError(t, jws.UnmarshalBinary(buf.Bytes()))
+		encoded, err := jws.MarshalBinary()
+		require.NoError(t, err)
+		require.Equal(t, buf.Bytes(), encoded)
+
+		buf.Reset()
+		require.NoError(t, Encode(jweGen(-1).Draw(t, "JWE"), &buf))
+		var jwe JWE
+		require.NoError(t, jwe.UnmarshalBinary(buf.Bytes()))
+		encoded, err = jwe.MarshalBinary()
+		require.NoError(t, err)
+		require.Equal(t, buf.Bytes(), encoded)
+	})
+}
+
+func TestJWSStructHeaderValues(t *testing.T) {
+	link := createCid([]byte("payload"))
+	jws := JWS{
+		Payload: link,
+		Signatures: []Signature{{
+			Protected: []byte(`{"alg":"EdDSA"}`),
+			Header: map[string]interface{}{
+				"int":   int64(-1),
+				"bool":  true,
+				"null":  nil,
+				"link":  link,
+				"bytes": []byte{1, 2, 3},
+				"float": 1.5,
+				"list":  []interface{}{"a", map[string]interface{}{"b": "c"}},
+			},
+			Signature: []byte{4, 5, 6},
+		}},
+	}
+	encoded, err := jws.MarshalBinary()
+	require.NoError(t, err)
+	var decoded JWS
+	require.NoError(t, decoded.UnmarshalBinary(encoded))
+	require.Equal(t, jws, decoded)
+	header, err := decoded.Signatures[0].ProtectedHeader()
+	require.NoError(t, err)
+	require.Equal(t, "EdDSA", header.Alg)
+
+	_, err = (&JWS{}).ToNode()
+	require.Error(t, err)
+	jws.Signatures[0].Header = map[string]interface{}{"unsupported": struct{}{}}
+	_, err = jws.ToNode()
+	require.Error(t, err)
+}
+
+// Signed and encrypted nodes should be usable through the Go structs
+func TestStructsWithSignAndEncrypt(t *testing.T) {
+	privateKey := ed25519PrivateKeyGen().Example()
+	signed, err := SignCID(createCid([]byte("payload")), Signer{Algorithm: gojose.EdDSA, Key: privateKey, KeyID: "key"})
+	require.NoError(t, err)
+	var jws JWS
+	require.NoError(t, jws.FromNode(roundTripJWS(t, signed)))
+	require.Len(t, jws.Signatures, 1)
+	n, err := jws.ToNode()
+	require.NoError(t, err)
+	results, err := VerifyJWS(n, privateKey.Public())
+	require.NoError(t, err)
+	require.True(t, results[0].Valid(), "%v", results[0].Err)
+
+	symmetricKey := bytes.Repeat([]byte{1}, 32)
+	encrypted, err := EncryptNode(cleartextNode(), EncryptOptions{}, Recipient{Algorithm: gojose.A256KW, Key: symmetricKey})
+	require.NoError(t, err)
+	var jwe JWE
+	require.NoError(t, jwe.FromNode(roundTripJWE(t, encrypted)))
+	header, err := jwe.ProtectedHeader()
+	require.NoError(t, err)
+	require.Equal(t, string(gojose.A256GCM), header.Enc)
+	n, err = jwe.ToNode()
+	require.NoError(t, err)
+	nb := basicnode.Prototype.Any.NewBuilder()
+	require.NoError(t, DecryptJWE(context.Background(), n, staticKey(symmetricKey), nb))
+	require.Equal(t, encodeDagCBOR(t, cleartextNode()), encodeDagCBOR(t, nb.Build()))
+
+	require.Error(t, jws.FromNode(roundTripJWE(t, encrypted)))
+	require.Error(t, jwe.FromNode(roundTripJWS(t, signed)))
+}
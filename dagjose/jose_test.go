@@ -2,25 +2,27 @@ package dagjose
 
 import (
 	"bytes"
-	"errors"
+	"encoding/base64"
 	"fmt"
 	gojose "github.com/go-jose/go-jose/v4"
 	"github.com/stretchr/testify/require"
 	"golang.org/x/crypto/ed25519"
 	"io"
+	"math"
 	"pgregory.net/rapid"
 	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/ipfs/go-cid"
 	"github.com/ipld/go-ipld-prime"
-	"github.com/ipld/go-ipld-prime/codec/dagjson"
+	"github.com/ipld/go-ipld-prime/codec/dagcbor"
 	"github.com/ipld/go-ipld-prime/datamodel"
 	"github.com/ipld/go-ipld-prime/fluent"
 	"github.com/ipld/go-ipld-prime/linking/cid"
-	"github.com/ipld/go-ipld-prime/multicodec"
 	"github.com/ipld/go-ipld-prime/node/basicnode"
 	"github.com/ipld/go-ipld-prime/schema"
+	"github.com/ipld/go-ipld-prime/traversal"
 	"github.com/multiformats/go-multihash"
 )
 
@@ -31,96 +33,6 @@ import (
 // In order to test this property we use the `rapid` property testing library. We start by defining a series of
 // generators, used to generate arbitrary JOSE objects.
 
-// A link prototype which will build CIDs using the dag-jose multicodec and the sha-384 multihash
-var dagJOSELink = cidlink.LinkPrototype{Prefix: cid.Prefix{
-	Version:  1,    // Usually '1'.
-	Codec:    0x85, // 0x85 means "dag-jose" -- See the multicodecs table: https://github.com/multiformats/multicodec/
-	MhType:   0x12, // 0x12 means "sha2-256" -- See the multicodecs table: https://github.com/multiformats/multicodec/
-	MhLength: 32,   // sha2-256 hash has a 32-byte sum.
-}}
-
-// storeJOSE is a convenience function that passes the correct DAG-JOSE link prototype and DAG-JOSE object to
-// ipld.LinkSystem.Store
-func storeJOSE(linkContext ipld.LinkContext, jose datamodel.Node, linkSystem ipld.LinkSystem) (ipld.Link, error) {
-	return linkSystem.Store(linkContext, dagJOSELink, jose)
-}
-
-// loadJOSE is a convenience function that provides the DAG-JOSE node prototype to ipld.LinkSystem.Load and attempts to
-// cast the result to a DAG-JOSE object.
-func loadJOSE(lnk ipld.Link, linkContext ipld.LinkContext, linkSystem ipld.LinkSystem) (_ datamodel.Node, err error) {
-	n, err := loadJWE(lnk, linkContext, linkSystem)
-	if err != nil {
-		// If there was an error during JWE decode, try decoding as JWS
-		n, err = loadJWS(lnk, linkContext, linkSystem)
-		if err != nil {
-			return nil, err
-		}
-	}
-	return n.(schema.TypedNode).Representation(), nil
-}
-
-func loadJWE(lnk ipld.Link, linkContext ipld.LinkContext, linkSystem ipld.LinkSystem) (_ datamodel.Node, err error) {
-	defer func() {
-		if r := recover(); r != nil {
-			if rerr, ok := r.(error); ok {
-				err = rerr
-			} else {
-				// A reasonable fallback, for e.g. strings.
-				err = fmt.Errorf("%v", r)
-			}
-		}
-	}()
-	return linkSystem.Load(
-		linkContext,
-		lnk,
-		Type.DecodedJWE__Repr,
-	)
-}
-
-func loadJWS(lnk ipld.Link, linkContext ipld.LinkContext, linkSystem ipld.LinkSystem) (_ datamodel.Node, err error) {
-	defer func() {
-		if r := recover(); r != nil {
-			if rerr, ok := r.(error); ok {
-				err = rerr
-			} else {
-				// A reasonable fallback, for e.g. strings.
-				err = fmt.Errorf("%v", r)
-			}
-		}
-	}()
-	return linkSystem.Load(
-		linkContext,
-		lnk,
-		Type.DecodedJWS__Repr,
-	)
-}
-
-// parseJOSE will return a general form JWE/JWS node given a JSON string representing a JWE/JWS in flattened or general
-// serialization
-func parseJOSE(jsonBytes []byte) (datamodel.Node, error) {
-	buf := bytes.NewReader(jsonBytes)
-	anyBuilder := basicnode.Prototype.Any.NewBuilder()
-	if err := (dagjson.DecodeOptions{
-		ParseLinks: false,
-		ParseBytes: false,
-	}.Decode(anyBuilder, buf)); err != nil {
-		return nil, err
-	} else {
-		anyNode := anyBuilder.Build()
-		if jwe, err := isJWE(anyNode); err != nil {
-			return nil, err
-		} else if jwe {
-			return unflattenJWE(anyNode)
-		} else if jws, err := isJWS(anyNode); err != nil {
-			return nil, err
-		} else if jws {
-			return unflattenJWS(anyNode)
-		} else {
-			return nil, errors.New("invalid JOSE object")
-		}
-	}
-}
-
 // Generate an arbitrary CID
 func cidGen() *rapid.Generator[cid.Cid] {
 	return rapid.Custom(func(t *rapid.T) cid.Cid {
@@ -149,19 +61,10 @@ func validJWSGen() *rapid.Generator[datamodel.Node] {
 	return rapid.Custom(func(t *rapid.T) datamodel.Node {
 		link := cidGen().Draw(t, "Valid DagJOSE payload")
 		privateKey := ed25519PrivateKeyGen().Draw(t, "valid jws private key")
-		if signer, err := gojose.NewSigner(gojose.SigningKey{
-			Algorithm: gojose.EdDSA,
-			Key:       privateKey,
-		}, nil); err != nil {
-			panic(fmt.Errorf("error creating signer for JWS: %v", err))
+		if jws, err := SignCID(link, Signer{Algorithm: gojose.EdDSA, Key: privateKey}); err != nil {
+			panic(fmt.Errorf("error signing JWS: %v", err))
 		} else {
-			if joseJws, err := signer.Sign(link.Bytes()); err != nil {
-				panic(fmt.Errorf("error signing JWS: %v", err))
-			} else if joseNode, err := parseJOSE([]byte(joseJws.FullSerialize())); err != nil {
-				panic(fmt.Errorf("error creating dagjose: %v", err))
-			} else {
-				return joseNode
-			}
+			return jws.Representation()
 		}
 	})
 }
@@ -238,11 +141,11 @@ func mapGen() *rapid.Generator[_Any__Maybe] {
 				return k
 			},
 		).Draw(t, "map keys")
-		header := make(map[_String]Any)
+		header := make(map[_String]MaybeAny)
 		entries := make([]_Map__entry, 0, len(keys))
 		for _, key := range keys {
 			k := _String{key}
-			v := _Any{&_String{string(nonNilSliceOfBytes().Draw(t, "string"))}}
+			v := _Any__Maybe{schema.Maybe_Value, &_Any{&_String{string(nonNilSliceOfBytes().Draw(t, "string"))}}}
 			header[k] = &v
 			entries = append(entries, _Map__entry{k, v})
 		}
@@ -326,25 +229,21 @@ func roundTripJose(storeJose datamodel.Node) (datamodel.Node, error) {
 		return bytes.NewReader(buf.Bytes()), nil
 	}
 
-	// We're going from JOSE -> JOSE, so we don't need to complicate things with `link`.
-	multicodec.RegisterDecoder(0x85, DecodeOptions{AddLink: false}.Decode)
-	multicodec.RegisterEncoder(0x85, Encode)
-
-	if link, err := storeJOSE(
+	if link, err := StoreJOSE(
 		ipld.LinkContext{},
 		storeJose,
 		ls,
 	); err != nil {
 		panic(fmt.Errorf("error storing DagJOSE: %v", err))
 	} else {
-		if loadJose, err := loadJOSE(
+		if loadJose, err := LoadJOSE(
 			link,
 			ipld.LinkContext{},
 			ls,
 		); err != nil {
 			panic(fmt.Errorf("error reading data from datastore: %v", err))
 		} else {
-			return loadJose, nil
+			return loadJose.Node(), nil
 		}
 	}
 }
@@ -357,7 +256,7 @@ func compareJOSE(t *rapid.T, encoded datamodel.Node, decoded datamodel.Node) {
 	compareJOSEField(t, "protected", encoded, decoded)
 	compareJOSEField(t, "tag", encoded, decoded)
 
-	// TODO: `ipldNodeToGoPrimitive` doesn't currently work for IPLD nodes with (nested) `bytes` type fields
+	// TODO: Nested fields are `bytes` in encoded nodes and base64url strings in decoded nodes, and aren't compared yet
 	//compareJOSEField(t, "recipients", encoded, decoded)
 	//compareJOSEField(t, "signatures", encoded, decoded)
 	//compareJOSEField(t, "unprotected", encoded, decoded)
@@ -382,22 +281,15 @@ func compareJOSEField(t *rapid.T, key string, encoded datamodel.Node, decoded da
 }
 
 func compareNodes(t *rapid.T, kind datamodel.Kind, f1 datamodel.Node, f2 datamodel.Node) {
-	var goF1, goF2 interface{}
 	switch kind {
-	case datamodel.Kind_List:
-		goF1 = []interface{}{}
-		goF2 = []interface{}{}
-	case datamodel.Kind_Map:
-	case datamodel.Kind_Link:
-		goF1 = map[string]interface{}{}
-		goF2 = map[string]interface{}{}
+	case datamodel.Kind_List, datamodel.Kind_Map, datamodel.Kind_Link:
 	default:
 		compareJOSEBytes(t, f1, f2)
 		return
 	}
-	if err := ipldNodeToGoPrimitive(f1, &goF1); err != nil {
+	if goF1, err := goValueFromNode(f1); err != nil {
 		t.Errorf("error converting field: %v/%v", f1, err)
-	} else if err := ipldNodeToGoPrimitive(f2, &goF2); err != nil {
+	} else if goF2, err := goValueFromNode(f2); err != nil {
 		t.Errorf("error converting field: %v/%v", f2, err)
 	} else if !reflect.DeepEqual(goF1, goF2) {
 		t.Errorf("fields do not match:\n%s\n%s", goF1, goF2)
@@ -474,7 +366,7 @@ func TestAlwaysDeserializesToEitherJWSOrJWE(t *testing.T) {
 // A JWS without a signature is not valid
 func TestMissingPayloadErrorParsingJWS(t *testing.T) {
 	jsonStr := "{\"signatures\": []}"
-	jws, err := parseJOSE([]byte(jsonStr))
+	jws, err := FromJSON(strings.NewReader(jsonStr))
 	require.NotNil(t, err)
 	require.Nil(t, jws)
 }
@@ -482,7 +374,7 @@ func TestMissingPayloadErrorParsingJWS(t *testing.T) {
 // A JWE without ciphertext is not valid
 func TestMissingCiphertextErrorParsingJWE(t *testing.T) {
 	jsonStr := "{\"header\": {}}"
-	jwe, err := parseJOSE([]byte(jsonStr))
+	jwe, err := FromJSON(strings.NewReader(jsonStr))
 	require.NotNil(t, err)
 	require.Nil(t, jwe)
 }
@@ -491,7 +383,7 @@ func TestMissingCiphertextErrorParsingJWE(t *testing.T) {
 func TestFlattenedJWSErrorIfSignatureAndSignaturesDefined(t *testing.T) {
 	payload := encodeBase64Url(createCid([]byte("payload")).Bytes())
 	jsonStr := "{\"signature\": \"sig\", \"signatures\": [], \"payload\": \"" + payload + "\"}"
-	jws, err := parseJOSE([]byte(jsonStr))
+	jws, err := FromJSON(strings.NewReader(jsonStr))
 	require.NotNil(t, err)
 	require.Contains(t, err.Error(), "invalid JWS serialization")
 	require.Nil(t, jws)
@@ -520,7 +412,7 @@ func TestLoadingJWSWithNonCIDPayloadReturnsError(t *testing.T) {
 		ls.StorageReadOpener = func(lnkCtx ipld.LinkContext, lnk ipld.Link) (io.Reader, error) {
 			return bytes.NewReader(buf.Bytes()), nil
 		}
-		if _, err := storeJOSE(
+		if _, err := StoreJOSE(
 			ipld.LinkContext{},
 			node,
 			ls,
@@ -539,9 +431,130 @@ func TestFlattenedJWEErrorIfEncryptedKeyOrHeaderAndRecipientsDefined(t *testing.
 		[]byte("{\"ciphertext\": \"\", \"header\": {}, \"recipients\": []}"),
 	}
 	for _, scenario := range scenarios {
-		jwe, err := parseJOSE(scenario)
+		jwe, err := FromJSON(bytes.NewReader(scenario))
 		require.NotNil(t, err)
 		require.Contains(t, err.Error(), "invalid JWE serialization")
 		require.Nil(t, jwe)
 	}
 }
+
+// LoadJOSE should report whether the loaded object is a JWS or a JWE
+func TestLoadJOSEReportsType(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		ls := memoryLinkSystem()
+		isJwe := rapid.Bool().Draw(t, "whether this jose is a jwe")
+		var jose datamodel.Node
+		if isJwe {
+			jose = jweGen(-1).Draw(t, "an arbitrary JWE")
+		} else {
+			jose = jwsGen(-1).Draw(t, "an arbitrary JWS")
+		}
+		link, err := StoreJOSE(ipld.LinkContext{}, jose, ls)
+		require.NoError(t, err)
+		loaded, err := LoadJOSE(link, ipld.LinkContext{}, ls)
+		require.NoError(t, err)
+		require.Equal(t, isJwe, loaded.IsJWE())
+		require.Equal(t, !isJwe, loaded.IsJWS())
+		if loaded.IsJWS() {
+			require.True(t, loaded.JWS.FieldLink().Exists())
+		}
+
+		// Links to objects that were not encoded with DAG-JOSE must be rejected
+		_, err = LoadJOSE(cidlink.Link{Cid: createCid([]byte("raw"))}, ipld.LinkContext{}, ls)
+		require.Error(t, err)
+	})
+}
+
+// Headers may contain any kind of data, including booleans, nulls and links, which must survive a round trip
+func TestRoundTripHeaderKinds(t *testing.T) {
+	link := cidlink.Link{Cid: createCid([]byte("header link"))}
+	header := fluent.MustBuildMap(basicnode.Prototype.Map, 4, func(ma fluent.MapAssembler) {
+		ma.AssembleEntry("b64").AssignBool(false)
+		ma.AssembleEntry("cty").AssignNull()
+		ma.AssembleEntry("ref").AssignLink(link)
+		ma.AssembleEntry("list").CreateList(3, func(la fluent.ListAssembler) {
+			la.AssembleValue().AssignBool(true)
+			la.AssembleValue().AssignNull()
+			la.AssembleValue().AssignLink(link)
+		})
+	})
+	jws := fluent.MustBuildMap(basicnode.Prototype.Map, 2, func(ma fluent.MapAssembler) {
+		ma.AssembleEntry("payload").AssignBytes(createCid([]byte("payload")).Bytes())
+		ma.AssembleEntry("signatures").CreateList(1, func(la fluent.ListAssembler) {
+			la.AssembleValue().CreateMap(2, func(ma fluent.MapAssembler) {
+				ma.AssembleEntry("header").AssignNode(header)
+				ma.AssembleEntry("signature").AssignBytes([]byte("signature"))
+			})
+		})
+	})
+	jwe := fluent.MustBuildMap(basicnode.Prototype.Map, 2, func(ma fluent.MapAssembler) {
+		ma.AssembleEntry("ciphertext").AssignBytes([]byte("ciphertext"))
+		ma.AssembleEntry("unprotected").AssignNode(header)
+	})
+
+	for _, scenario := range []struct {
+		jose       datamodel.Node
+		headerPath string
+	}{
+		{jws, "signatures/0/header"},
+		{jwe, "unprotected"},
+	} {
+		encoded := bytes.Buffer{}
+		require.NoError(t, dagcbor.Encode(scenario.jose, &encoded))
+		nb := basicnode.Prototype.Any.NewBuilder()
+		require.NoError(t, DecodeOptions{Strict: true}.Decode(nb, bytes.NewReader(encoded.Bytes())))
+		decoded := nb.Build()
+		decodedHeader, err := traversal.Get(decoded, datamodel.ParsePath(scenario.headerPath))
+		require.NoError(t, err)
+		require.True(t, datamodel.DeepEqual(header, decodedHeader))
+
+		reencoded := bytes.Buffer{}
+		if scenario.jose == jws {
+			require.NoError(t, EncodeJWS(decoded, &reencoded))
+		} else {
+			require.NoError(t, EncodeJWE(decoded, &reencoded))
+		}
+		require.Equal(t, encoded.Bytes(), reencoded.Bytes())
+	}
+}
+
+// Converting flattened objects to the general form must preserve header values that have no exact JSON equivalent
+func TestUnflattenPreservesHeaderValues(t *testing.T) {
+	header := fluent.MustBuildMap(basicnode.Prototype.Map, 4, func(ma fluent.MapAssembler) {
+		// Keys are in the order in which they will be decoded
+		ma.AssembleEntry("int").AssignInt(math.MaxInt64)
+		ma.AssembleEntry("link").AssignLink(cidlink.Link{Cid: createCid([]byte("header link"))})
+		ma.AssembleEntry("null").AssignNull()
+		ma.AssembleEntry("bytes").AssignBytes([]byte{0x00, 0xff})
+	})
+	jws := fluent.MustBuildMap(basicnode.Prototype.Map, 3, func(ma fluent.MapAssembler) {
+		ma.AssembleEntry("payload").AssignString(base64.RawURLEncoding.EncodeToString(createCid([]byte("payload")).Bytes()))
+		ma.AssembleEntry("header").AssignNode(header)
+		ma.AssembleEntry("signature").AssignString("c2lnbmF0dXJl")
+	})
+	jwe := fluent.MustBuildMap(basicnode.Prototype.Map, 4, func(ma fluent.MapAssembler) {
+		ma.AssembleEntry("ciphertext").AssignString("Y2lwaGVydGV4dA")
+		ma.AssembleEntry("encrypted_key").AssignString("a2V5")
+		ma.AssembleEntry("header").AssignNode(header)
+		ma.AssembleEntry("unprotected").AssignNode(header)
+	})
+
+	for _, scenario := range []struct {
+		jose        datamodel.Node
+		headerPaths []string
+	}{
+		{jws, []string{"signatures/0/header"}},
+		{jwe, []string{"recipients/0/header", "unprotected"}},
+	} {
+		buf := bytes.Buffer{}
+		require.NoError(t, Encode(scenario.jose, &buf))
+		nb := basicnode.Prototype.Any.NewBuilder()
+		require.NoError(t, Decode(nb, &buf))
+		decoded := nb.Build()
+		for _, path := range scenario.headerPaths {
+			decodedHeader, err := traversal.Get(decoded, datamodel.ParsePath(path))
+			require.NoError(t, err)
+			require.True(t, datamodel.DeepEqual(header, decodedHeader), path)
+		}
+	}
+}
@@ -0,0 +1,157 @@
+package dagjose
+
+import (
+	"context"
+	"crypto"
+	"errors"
+	"fmt"
+
+	"github.com/ipfs/go-cid"
+	"github.com/ipld/go-ipld-prime/datamodel"
+	"github.com/ipld/go-ipld-prime/node/basicnode"
+	"github.com/ipld/go-ipld-prime/schema"
+)
+
+// understoodCriticalParams lists the header parameters that may appear in `crit`.
+var understoodCriticalParams = map[string]bool{"b64": true}
+
+// AttachPayload returns a copy of the given JWS node with its `payload` set to the given CID. This allows a JWS with a
+// detached payload, i.e. with an absent or empty `payload`, to be encoded, verified or exported like any other JWS. If
+// the node already has a non-empty payload, it must be the same CID.
+// See: https://datatracker.ietf.org/doc/html/rfc7515#appendix-F
+func AttachPayload(n datamodel.Node, payload cid.Cid) (datamodel.Node, error) {
+	if !payload.Defined() {
+		return nil, errors.New("cannot attach an undefined CID")
+	}
+	if tn, castOk := n.(schema.TypedNode); castOk {
+		// The "representation" node gives an accurate view of fields that are actually present
+		n = tn.Representation()
+	}
+	if n.Kind() != datamodel.Kind_Map {
+		return nil, &ErrInvalidSerialization{Object: "JWS", Reason: "not a map"}
+	}
+	payloadString := encodeBase64Url(payload.Bytes())
+	if existing, err := lookupIgnoreAbsent("payload", n); err != nil {
+		return nil, err
+	} else if existing != nil {
+		if existingString, err := existing.AsString(); err != nil {
+			return nil, &ErrInvalidSerialization{Object: "JWS", Field: "payload", Err: err}
+		} else if existingString != "" && existingString != payloadString {
+			if existingCID, err := encodedPayloadCID(existingString); err != nil {
+				return nil, err
+			} else {
+				return nil, &ErrLinkMismatch{Link: payload, Payload: existingCID}
+			}
+		}
+	}
+	nb := basicnode.Prototype.Map.NewBuilder()
+	ma, err := nb.BeginMap(n.Length() + 1)
+	if err != nil {
+		return nil, err
+	}
+	if err := ma.AssembleKey().AssignString("payload"); err != nil {
+		return nil, err
+	} else if err := ma.AssembleValue().AssignString(payloadString); err != nil {
+		return nil, err
+	}
+	itr := n.MapIterator()
+	for !itr.Done() {
+		k, v, err := itr.Next()
+		if err != nil {
+			return nil, err
+		}
+		// `link` is dropped since it could refer to a different payload
+		if key, _ := k.AsString(); key == "payload" || key == "link" {
+			continue
+		} else if err := ma.AssembleKey().AssignNode(k); err != nil {
+			return nil, err
+		} else if err := ma.AssembleValue().AssignNode(v); err != nil {
+			return nil, err
+		}
+	}
+	if err := ma.Finish(); err != nil {
+		return nil, err
+	}
+	return nb.Build(), nil
+}
+
+// VerifyDetachedJWS is like VerifyJWS for a JWS whose payload was supplied separately, e.g. because it was signed with
+// a detached payload. The payload of the node, if not empty, must be the given CID.
+func VerifyDetachedJWS(n datamodel.Node, payload cid.Cid, keys ...crypto.PublicKey) ([]SignatureVerification, error) {
+	if n, err := AttachPayload(n, payload); err != nil {
+		return nil, err
+	} else {
+		return VerifyJWS(n, keys...)
+	}
+}
+
+// VerifyDetachedJWSWithResolver is like VerifyJWSWithResolver for a JWS whose payload was supplied separately. The
+// payload of the node, if not empty, must be the given CID.
+func VerifyDetachedJWSWithResolver(ctx context.Context, n datamodel.Node, payload cid.Cid, resolver KeyResolver) ([]SignatureVerification, error) {
+	if n, err := AttachPayload(n, payload); err != nil {
+		return nil, err
+	} else {
+		return VerifyJWSWithResolver(ctx, n, resolver)
+	}
+}
+
+// jwsSigningInput returns the JWS Signing Input for the given protected header and payload. The input is normally
+// ASCII(BASE64URL(UTF8(JWS Protected Header)) || '.' || BASE64URL(JWS Payload)) but, if the protected header has
+// `b64: false`, the payload is used as is.
+// See: https://datatracker.ietf.org/doc/html/rfc7515#section-5.1 and
+// https://datatracker.ietf.org/doc/html/rfc7797#section-3
+func jwsSigningInput(protected _Base64Url__Maybe, payload []byte) ([]byte, error) {
+	protectedString := ""
+	if protected.Exists() {
+		protectedString, _ = protected.v.AsString()
+	}
+	if unencoded, err := hasUnencodedPayload(protected); err != nil {
+		return nil, err
+	} else if unencoded {
+		return append([]byte(protectedString+"."), payload...), nil
+	}
+	return []byte(protectedString + "." + encodeBase64Url(payload)), nil
+}
+
+// hasUnencodedPayload returns true if the given protected header has `b64: false`. This also validates `crit`, which
+// may only list parameters that are understood and present in the protected header, and must list `b64` if present.
+// See: https://datatracker.ietf.org/doc/html/rfc7515#section-4.1.11
+func hasUnencodedPayload(protected _Base64Url__Maybe) (bool, error) {
+	header, err := parseProtectedHeader(protected)
+	if err != nil {
+		return false, err
+	}
+	critical := make(map[string]bool, len(header.Crit))
+	for _, param := range header.Crit {
+		if !understoodCriticalParams[param] {
+			return false, fmt.Errorf("unsupported critical header parameter: %s", param)
+		} else if param == "b64" && header.B64 == nil {
+			return false, fmt.Errorf("critical header parameter is missing: %s", param)
+		}
+		critical[param] = true
+	}
+	if header.B64 == nil {
+		return false, nil
+	} else if !critical["b64"] {
+		return false, errors.New("`b64` header parameter must be listed in `crit`")
+	}
+	return !*header.B64, nil
+}
+
+// jwsHasUnencodedPayload returns true if the signatures of the given JWS have `b64: false`. All signatures must agree.
+// See: https://datatracker.ietf.org/doc/html/rfc7797#section-3
+func jwsHasUnencodedPayload(jws DecodedJWS) (bool, error) {
+	unencoded := false
+	if jws.signatures.Exists() {
+		for idx, signature := range jws.signatures.v.x {
+			if sigUnencoded, err := hasUnencodedPayload(signature.protected); err != nil {
+				return false, err
+			} else if idx > 0 && sigUnencoded != unencoded {
+				return false, errors.New("all signatures must use the same `b64` header parameter value")
+			} else {
+				unencoded = sigUnencoded
+			}
+		}
+	}
+	return unencoded, nil
+}
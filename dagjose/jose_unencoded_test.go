@@ -0,0 +1,160 @@
+package dagjose
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	gojose "github.com/go-jose/go-jose/v4"
+	"github.com/ipfs/go-cid"
+	"github.com/stretchr/testify/require"
+	"pgregory.net/rapid"
+)
+
+var unencodedPayloadHeader = map[string]interface{}{"b64": false, "crit": []string{"b64"}}
+
+// A JWS signed by go-jose over the unencoded binary CID should be verified with the detached payload
+func TestVerifyUnencodedPayloadFromGoJose(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		link := cidGen().Draw(t, "payload")
+		privateKey := ed25519PrivateKeyGen().Draw(t, "private key")
+		signer, err := gojose.NewSigner(gojose.SigningKey{Algorithm: gojose.EdDSA, Key: privateKey}, (&gojose.SignerOptions{}).WithBase64(false))
+		require.NoError(t, err)
+		joseJws, err := signer.Sign(link.Bytes())
+		require.NoError(t, err)
+		compact, err := joseJws.DetachedCompactSerialize()
+		require.NoError(t, err)
+		parts := strings.Split(compact, ".")
+		require.Equal(t, "", parts[1])
+		jsonBytes, err := json.Marshal(map[string]string{"protected": parts[0], "signature": parts[2]})
+		require.NoError(t, err)
+
+		jws, err := FromDetachedJSON(bytes.NewReader(jsonBytes), link)
+		require.NoError(t, err)
+		results, err := VerifyJWS(jws, privateKey.Public())
+		require.NoError(t, err)
+		require.True(t, results[0].Valid(), "%v", results[0].Err)
+
+		parsed, err := ParseDetachedCompactJWS(compact, link)
+		require.NoError(t, err)
+		results, err = VerifyJWS(parsed, privateKey.Public())
+		require.NoError(t, err)
+		require.True(t, results[0].Valid(), "%v", results[0].Err)
+
+		// The same signature must not verify as a regular JWS over the base64url-encoded payload
+		protected, err := decodeBase64Url(parts[0])
+		require.NoError(t, err)
+		protected = bytes.Replace(protected, []byte(`"crit":["b64"],`), nil, 1)
+		protected = bytes.Replace(protected, []byte(`,"crit":["b64"]`), nil, 1)
+		protected = bytes.Replace(protected, []byte(`"b64":false,`), nil, 1)
+		protected = bytes.Replace(protected, []byte(`,"b64":false`), nil, 1)
+		jsonBytes, err = json.Marshal(map[string]string{"protected": encodeBase64Url(protected), "signature": parts[2]})
+		require.NoError(t, err)
+		jws, err = FromDetachedJSON(bytes.NewReader(jsonBytes), link)
+		require.NoError(t, err)
+		results, err = VerifyJWS(jws, privateKey.Public())
+		require.NoError(t, err)
+		require.False(t, results[0].Valid())
+	})
+}
+
+// A JWS signed with `b64: false` should be exported with a detached payload that go-jose can verify
+func TestSignUnencodedPayload(t *testing.T) {
+	link := createCid([]byte("payload"))
+	privateKey := ed25519PrivateKeyGen().Example()
+	jws, err := SignCID(link, Signer{Algorithm: gojose.EdDSA, Key: privateKey, Protected: unencodedPayloadHeader})
+	require.NoError(t, err)
+	decoded := roundTripJWS(t, jws)
+
+	results, err := VerifyJWS(decoded, privateKey.Public())
+	require.NoError(t, err)
+	require.True(t, results[0].Valid(), "%v", results[0].Err)
+
+	compact, err := ToCompactJWS(decoded)
+	require.NoError(t, err)
+	require.Equal(t, "", strings.Split(compact, ".")[1])
+	joseJws, err := gojose.ParseDetached(compact, link.Bytes(), []gojose.SignatureAlgorithm{gojose.EdDSA})
+	require.NoError(t, err)
+	_, err = joseJws.Verify(privateKey.Public())
+	require.NoError(t, err)
+	_, err = ParseCompactJWS(compact)
+	require.ErrorIs(t, err, ErrPayloadNotCID)
+	parsed, err := ParseDetachedCompactJWS(compact, link)
+	require.NoError(t, err)
+	results, err = VerifyJWS(parsed, privateKey.Public())
+	require.NoError(t, err)
+	require.True(t, results[0].Valid(), "%v", results[0].Err)
+	reexported, err := ToCompactJWS(parsed)
+	require.NoError(t, err)
+	require.Equal(t, compact, reexported)
+	_, err = ParseDetachedCompactJWS(compact, cid.Undef)
+	require.Error(t, err)
+
+	jsonBytes, err := ToJSON(decoded, FlattenAuto)
+	require.NoError(t, err)
+	var jsonMap map[string]interface{}
+	require.NoError(t, json.Unmarshal(jsonBytes, &jsonMap))
+	require.NotContains(t, jsonMap, "payload")
+	_, err = FromJSON(bytes.NewReader(jsonBytes))
+	require.ErrorIs(t, err, ErrNotJOSE)
+	fromJSON, err := FromDetachedJSON(bytes.NewReader(jsonBytes), link)
+	require.NoError(t, err)
+	results, err = VerifyJWS(fromJSON, privateKey.Public())
+	require.NoError(t, err)
+	require.True(t, results[0].Valid(), "%v", results[0].Err)
+	reexportedJSON, err := ToJSON(fromJSON, FlattenAuto)
+	require.NoError(t, err)
+	require.JSONEq(t, string(jsonBytes), string(reexportedJSON))
+
+	// A detached payload must match the payload of the serialization, if present
+	var mismatch *ErrLinkMismatch
+	_, err = ParseDetachedCompactJWS(strings.Replace(compact, "..", "."+encodeBase64Url(link.Bytes())+".", 1), createCid([]byte("other payload")))
+	require.ErrorAs(t, err, &mismatch)
+
+	// The detached payload must match the payload of the JWS, if present
+	_, err = VerifyDetachedJWS(decoded, createCid([]byte("other payload")), privateKey.Public())
+	require.Error(t, err)
+
+	resolver := NewMemoryKeyResolver()
+	resolver.Add("key", privateKey)
+	jws, err = SignCID(link, Signer{Algorithm: gojose.EdDSA, Key: privateKey, KeyID: "key", Protected: unencodedPayloadHeader})
+	require.NoError(t, err)
+	detached, err := ToCompactJWS(jws)
+	require.NoError(t, err)
+	parsed, err = ParseDetachedCompactJWS(detached, link)
+	require.NoError(t, err)
+	results, err = VerifyDetachedJWSWithResolver(context.Background(), parsed, link, resolver)
+	require.NoError(t, err)
+	require.True(t, results[0].Valid(), "%v", results[0].Err)
+	_, err = VerifyDetachedJWSWithResolver(context.Background(), parsed, createCid([]byte("other payload")), resolver)
+	require.Error(t, err)
+}
+
+func TestInvalidCriticalHeaders(t *testing.T) {
+	link := createCid([]byte("payload"))
+	privateKey := ed25519PrivateKeyGen().Example()
+	for _, protected := range []map[string]interface{}{
+		{"crit": []string{"exp"}, "exp": 0},
+		{"crit": []string{"b64"}},
+		{"b64": false},
+	} {
+		_, err := SignCID(link, Signer{Algorithm: gojose.EdDSA, Key: privateKey, Protected: protected})
+		require.Error(t, err, "%v", protected)
+	}
+
+	// Signatures with critical header parameters that are not understood must not be considered valid
+	signer, err := gojose.NewSigner(gojose.SigningKey{Algorithm: gojose.EdDSA, Key: privateKey},
+		(&gojose.SignerOptions{}).WithHeader("exp", 0).WithCritical("exp"))
+	require.NoError(t, err)
+	joseJws, err := signer.Sign(link.Bytes())
+	require.NoError(t, err)
+	results, err := VerifyJWS(decodeJOSEJSON(t, []byte(joseJws.FullSerialize())), privateKey.Public())
+	require.NoError(t, err)
+	require.False(t, results[0].Valid())
+	require.ErrorContains(t, results[0].Err, "unsupported critical header parameter")
+
+	_, err = SignCID(link, Signer{Algorithm: gojose.EdDSA, Key: privateKey, Header: map[string]interface{}{"b64": false}})
+	require.Error(t, err)
+}
@@ -1,18 +1,10 @@
 package dagjose
 
 import (
-	"bytes"
-	"errors"
-	"fmt"
-	"github.com/go-jose/go-jose/v4/json"
-	"reflect"
-
-	"github.com/ipfs/go-cid"
-	"github.com/ipld/go-ipld-prime/codec/dagjson"
 	"github.com/ipld/go-ipld-prime/datamodel"
+	"github.com/ipld/go-ipld-prime/fluent"
 	"github.com/ipld/go-ipld-prime/node/basicnode"
 	"github.com/ipld/go-ipld-prime/schema"
-	"github.com/multiformats/go-multibase"
 )
 
 func unflattenJWE(n datamodel.Node) (datamodel.Node, error) {
@@ -22,111 +14,42 @@ func unflattenJWE(n datamodel.Node) (datamodel.Node, error) {
 		if _, castOk := n.(*_EncodedJWE); !castOk {
 			if ciphertext, err := n.LookupByString("ciphertext"); err != nil {
 				// `ciphertext` is mandatory so if any error occurs, return from here
-				return nil, err
-			} else if ciphertextString, err := ciphertext.AsString(); err != nil {
-				return nil, err
+				return nil, &ErrInvalidSerialization{Object: "JWE", Field: "ciphertext", Reason: "missing", Err: err}
+			} else if _, err := ciphertext.AsString(); err != nil {
+				return nil, &ErrInvalidSerialization{Object: "JWE", Field: "ciphertext", Err: err}
 			} else if recipients, err := lookupIgnoreAbsent("recipients", n); err != nil {
+				return nil, &ErrInvalidSerialization{Object: "JWE", Field: "recipients", Err: err}
+			} else if recipient, err := lookupFields("JWE", n, []string{"encrypted_key"}, []string{"header"}); err != nil {
+				return nil, err
+			} else if fields, err := lookupFields("JWE", n, []string{"aad", "iv", "protected", "tag"}, []string{"unprotected"}); err != nil {
 				return nil, err
 			} else {
-				jwe := map[string]interface{}{
-					"ciphertext": ciphertextString,
-				}
+				fields = append(fields, field{"ciphertext", ciphertext})
 				// If `recipients` is absent, this must be a "flattened" JWE.
 				if recipients == nil {
-					recipientList := make([]map[string]interface{}, 1)
-					recipientList[0] = make(map[string]interface{}, 0) // all recipient fields are optional
-					if encryptedKey, err := lookupIgnoreNoSuchField("encrypted_key", n); err != nil {
-						return nil, err
-					} else if encryptedKey != nil {
-						if encryptedKeyString, err := encryptedKey.AsString(); err != nil {
-							return nil, err
-						} else {
-							recipientList[0]["encrypted_key"] = encryptedKeyString
-						}
-					}
-					if header, err := lookupIgnoreNoSuchField("header", n); err != nil {
-						return nil, err
-					} else if header != nil {
-						var headerMap map[string]interface{}
-						if err := ipldNodeToGoPrimitive(header, &headerMap); err != nil {
-							return nil, err
-						} else {
-							recipientList[0]["header"] = headerMap
-						}
-					}
-					// Only add `recipients` to the JWE if one or more fields were present in the first list entry
-					if len(recipientList[0]) > 0 {
-						jwe["recipients"] = recipientList
+					// Only add `recipients` to the JWE if one or more fields were present (all recipient fields are
+					// optional).
+					if len(recipient) > 0 {
+						fields = append(fields, field{"recipients", nil})
 					}
 				} else {
 					// If `recipients` is present, this must be a "general" JWE and no changes are needed but make sure
 					// that `header` and/or `encrypted_key` are not present since that would be a violation of the spec.
-					if encryptedKey, err := lookupIgnoreNoSuchField("encrypted_key", n); err != nil {
-						return nil, err
-					} else if encryptedKey != nil {
-						return nil, errors.New("invalid JWE serialization")
-					}
-					if header, err := lookupIgnoreNoSuchField("header", n); err != nil {
-						return nil, err
-					} else if header != nil {
-						return nil, errors.New("invalid JWE serialization")
-					}
-					var recipientList []map[string]interface{}
-					if err = ipldNodeToGoPrimitive(recipients, &recipientList); err != nil {
-						return nil, err
+					if len(recipient) > 0 {
+						return nil, &ErrInvalidSerialization{
+							Object: "JWE",
+							Field:  recipient[0].key,
+							Reason: "not allowed alongside `recipients`",
+						}
 					}
 					// Only add `recipients` to the JWE if one or more fields were present in the first list entry
-					if len(recipientList[0]) > 0 {
-						jwe["recipients"] = recipientList
-					}
-				}
-				if aad, err := lookupIgnoreAbsent("aad", n); err != nil {
-					return nil, err
-				} else if aad != nil {
-					if aadString, err := aad.AsString(); err != nil {
-						return nil, err
-					} else {
-						jwe["aad"] = aadString
-					}
-				}
-				if iv, err := lookupIgnoreAbsent("iv", n); err != nil {
-					return nil, err
-				} else if iv != nil {
-					if ivString, err := iv.AsString(); err != nil {
-						return nil, err
-					} else {
-						jwe["iv"] = ivString
-					}
-				}
-				if protected, err := lookupIgnoreAbsent("protected", n); err != nil {
-					return nil, err
-				} else if protected != nil {
-					if protectedString, err := protected.AsString(); err != nil {
-						return nil, err
-					} else {
-						jwe["protected"] = protectedString
-					}
-				}
-				if tag, err := lookupIgnoreAbsent("tag", n); err != nil {
-					return nil, err
-				} else if tag != nil {
-					if tagString, err := tag.AsString(); err != nil {
-						return nil, err
-					} else {
-						jwe["tag"] = tagString
-					}
-				}
-				if unprotected, err := lookupIgnoreAbsent("unprotected", n); err != nil {
-					return nil, err
-				} else if unprotected != nil {
-					var unprotectedMap map[string]interface{}
-					if err := ipldNodeToGoPrimitive(unprotected, &unprotectedMap); err != nil {
-						return nil, err
-					} else {
-						jwe["unprotected"] = unprotectedMap
+					if first, err := recipients.LookupByIndex(0); err != nil {
+						return nil, &ErrInvalidSerialization{Object: "JWE", Field: "recipients", Err: err}
+					} else if first.Length() > 0 {
+						fields = append(fields, field{"recipients", recipients})
 					}
 				}
-				if n, err = goPrimitiveToIpldNode(jwe); err != nil {
+				if n, err = buildUnflattened(fields, "recipients", recipient); err != nil {
 					return nil, err
 				}
 			}
@@ -148,78 +71,34 @@ func unflattenJWS(n datamodel.Node) (datamodel.Node, error) {
 		if _, castOk := n.(*_EncodedJWS); !castOk {
 			if payload, err := n.LookupByString("payload"); err != nil {
 				// `payload` is mandatory so if any error occurs, return from here
-				return nil, err
-			} else if payloadString, err := payload.AsString(); err != nil {
-				return nil, err
-			} else if _, err := cid.Decode(string(multibase.Base64url) + payloadString); err != nil {
-				return nil, errors.New(fmt.Sprintf("payload is not a valid CID: %v", err))
+				return nil, &ErrInvalidSerialization{Object: "JWS", Field: "payload", Reason: "missing", Err: err}
 			} else if payloadString, err := payload.AsString(); err != nil {
+				return nil, &ErrInvalidSerialization{Object: "JWS", Field: "payload", Err: err}
+			} else if _, err := encodedPayloadCID(payloadString); err != nil {
 				return nil, err
 			} else if signatures, err := lookupIgnoreAbsent("signatures", n); err != nil {
+				return nil, &ErrInvalidSerialization{Object: "JWS", Field: "signatures", Err: err}
+			} else if signature, err := lookupFields("JWS", n, []string{"protected", "signature"}, []string{"header"}); err != nil {
 				return nil, err
 			} else {
-				jws := map[string]interface{}{
-					"payload": payloadString,
-				}
+				fields := []field{{"payload", payload}}
 				// If `signatures` is absent, this must be a "flattened" JWS.
 				if signatures == nil {
-					signaturesList := make([]map[string]interface{}, 1)
-					signaturesList[0] = make(map[string]interface{}, 1) // at least one `signature` must be present
-					if header, err := lookupIgnoreNoSuchField("header", n); err != nil {
-						return nil, err
-					} else if header != nil {
-						var headerMap map[string]interface{}
-						if err := ipldNodeToGoPrimitive(header, &headerMap); err != nil {
-							return nil, err
-						} else {
-							signaturesList[0]["header"] = headerMap
-						}
-					}
-					if protected, err := lookupIgnoreNoSuchField("protected", n); err != nil {
-						return nil, err
-					} else if protected != nil {
-						if protectedString, err := protected.AsString(); err != nil {
-							return nil, err
-						} else {
-							signaturesList[0]["protected"] = protectedString
-						}
-					}
-					if signature, err := lookupIgnoreNoSuchField("signature", n); err != nil {
-						return nil, err
-					} else if signature != nil {
-						if signatureString, err := signature.AsString(); err != nil {
-							return nil, err
-						} else {
-							signaturesList[0]["signature"] = signatureString
-						}
-					}
-					jws["signatures"] = signaturesList
+					fields = append(fields, field{"signatures", nil})
 				} else {
 					// If `signatures` is present, this must be a "general" JWS and no changes are needed but make sure
 					// that `header`, `protected`, and/or `signature` are not also present since that would be a
 					// violation of the spec.
-					if header, err := lookupIgnoreNoSuchField("header", n); err != nil {
-						return nil, err
-					} else if header != nil {
-						return nil, errors.New("invalid JWS serialization")
-					}
-					if protected, err := lookupIgnoreNoSuchField("protected", n); err != nil {
-						return nil, err
-					} else if protected != nil {
-						return nil, errors.New("invalid JWS serialization")
-					}
-					if signature, err := lookupIgnoreNoSuchField("signature", n); err != nil {
-						return nil, err
-					} else if signature != nil {
-						return nil, errors.New("invalid JWS serialization")
-					}
-					var signatureList []map[string]interface{}
-					if err = ipldNodeToGoPrimitive(signatures, &signatureList); err != nil {
-						return nil, err
+					if len(signature) > 0 {
+						return nil, &ErrInvalidSerialization{
+							Object: "JWS",
+							Field:  signature[0].key,
+							Reason: "not allowed alongside `signatures`",
+						}
 					}
-					jws["signatures"] = signatureList
+					fields = append(fields, field{"signatures", signatures})
 				}
-				if n, err = goPrimitiveToIpldNode(jws); err != nil {
+				if n, err = buildUnflattened(fields, "signatures", signature); err != nil {
 					return nil, err
 				}
 			}
@@ -232,6 +111,54 @@ func unflattenJWS(n datamodel.Node) (datamodel.Node, error) {
 	return n, nil
 }
 
+// field is a key and value pair from a JOSE object, in the order in which it should be assembled.
+type field struct {
+	key   string
+	value datamodel.Node
+}
+
+// lookupFields returns the fields of the given node that are present out of the given keys. The values of the string
+// fields must be strings, while the values of the node fields are kept as they are. Errors are reported against the
+// given kind of object.
+func lookupFields(object string, n datamodel.Node, stringKeys []string, nodeKeys []string) ([]field, error) {
+	var fields []field
+	for idx, key := range append(stringKeys, nodeKeys...) {
+		if value, err := lookupIgnoreNoSuchField(key, n); err != nil {
+			return nil, &ErrInvalidSerialization{Object: object, Field: key, Err: err}
+		} else if value != nil {
+			if idx < len(stringKeys) {
+				if _, err := value.AsString(); err != nil {
+					return nil, &ErrInvalidSerialization{Object: object, Field: key, Err: err}
+				}
+			}
+			fields = append(fields, field{key, value})
+		}
+	}
+	return fields, nil
+}
+
+// buildUnflattened assembles the "general" form of a JOSE object from the given fields. Values are copied structurally
+// so that all kinds of data, including bytes, links and integers, are preserved exactly. The field with the given list
+// key and a nil value is assembled as a list containing a single map with the given entries, i.e. the "flattened"
+// signature or recipient.
+func buildUnflattened(fields []field, listKey string, entry []field) (datamodel.Node, error) {
+	return fluent.BuildMap(basicnode.Prototype.Map, int64(len(fields)), func(ma fluent.MapAssembler) {
+		for _, f := range fields {
+			if f.key == listKey && f.value == nil {
+				ma.AssembleEntry(f.key).CreateList(1, func(la fluent.ListAssembler) {
+					la.AssembleValue().CreateMap(int64(len(entry)), func(ma fluent.MapAssembler) {
+						for _, e := range entry {
+							ma.AssembleEntry(e.key).AssignNode(e.value)
+						}
+					})
+				})
+			} else {
+				ma.AssembleEntry(f.key).AssignNode(f.value)
+			}
+		}
+	})
+}
+
 func isJWS(n datamodel.Node) (bool, error) {
 	if payload, err := lookupIgnoreNoSuchField("payload", n); err != nil {
 		return false, err
@@ -248,57 +175,6 @@ func isJWE(n datamodel.Node) (bool, error) {
 	}
 }
 
-func goPrimitiveToIpldNode(g interface{}) (datamodel.Node, error) {
-	if jsonBytes, err := json.Marshal(g); err != nil {
-		return nil, err
-	} else {
-		na := basicnode.Prototype.Any.NewBuilder()
-		if err := dagjson.Decode(na, bytes.NewReader(jsonBytes)); err != nil {
-			return nil, err
-		} else {
-			return na.Build(), nil
-		}
-	}
-}
-
-// TODO: Doesn't currently work for IPLD nodes with (nested) `bytes` type fields
-func ipldNodeToGoPrimitive(n datamodel.Node, g interface{}) error {
-	jsonBytes := bytes.NewBuffer([]byte{})
-	if err := (dagjson.EncodeOptions{
-		EncodeLinks: false,
-		EncodeBytes: false,
-	}.Encode(n, jsonBytes)); err != nil {
-		return err
-	} else {
-		if err := json.Unmarshal(jsonBytes.Bytes(), &g); err != nil {
-			return err
-		} else {
-			if reflect.TypeOf(g).Kind() == reflect.Map {
-				g = sanitizeMap(g.(map[string]interface{}))
-			}
-			return nil
-		}
-	}
-}
-
-// Remove all `nil` values from the top-level structure or from within nested maps or slices
-func sanitizeMap(m map[string]interface{}) map[string]interface{} {
-	for key, value := range m {
-		if value == nil {
-			delete(m, key)
-		} else if reflect.ValueOf(value).Kind() == reflect.Slice {
-			for idx, entry := range value.([]interface{}) {
-				if reflect.ValueOf(entry).Kind() == reflect.Map {
-					m[key].([]interface{})[idx] = sanitizeMap(m[key].([]interface{})[idx].(map[string]interface{}))
-				}
-			}
-		} else if reflect.ValueOf(value).Kind() == reflect.Map {
-			m[key] = sanitizeMap(value.(map[string]interface{}))
-		}
-	}
-	return m
-}
-
 func lookupIgnoreAbsent(key string, n datamodel.Node) (datamodel.Node, error) {
 	value, err := n.LookupByString(key)
 	if err != nil {
@@ -321,3 +197,68 @@ func lookupIgnoreNoSuchField(key string, n datamodel.Node) (datamodel.Node, erro
 	}
 	return value, nil
 }
+
+// assembleJOSE copies the given node into the given assembler like datamodel.Copy, but reports any error as an
+// ErrInvalidSerialization for the given kind of object, with the path of the value that couldn't be assembled, e.g.
+// "signatures/0/protected", so that callers can tell which signature or recipient is invalid.
+func assembleJOSE(object string, n datamodel.Node, na datamodel.NodeAssembler) error {
+	return assembleJOSEPath(object, datamodel.Path{}, n, na)
+}
+
+func assembleJOSEPath(object string, path datamodel.Path, n datamodel.Node, na datamodel.NodeAssembler) error {
+	invalid := func(path datamodel.Path, err error) error {
+		return &ErrInvalidSerialization{Object: object, Field: path.String(), Err: err}
+	}
+	switch n.Kind() {
+	case datamodel.Kind_Map:
+		ma, err := na.BeginMap(n.Length())
+		if err != nil {
+			return invalid(path, err)
+		}
+		itr := n.MapIterator()
+		for !itr.Done() {
+			k, v, err := itr.Next()
+			if err != nil {
+				return invalid(path, err)
+			}
+			key, err := k.AsString()
+			if err != nil {
+				return invalid(path, err)
+			}
+			entryPath := path.AppendSegmentString(key)
+			if err := ma.AssembleKey().AssignString(key); err != nil {
+				return invalid(entryPath, err)
+			} else if err := assembleJOSEPath(object, entryPath, v, ma.AssembleValue()); err != nil {
+				return err
+			}
+		}
+		if err := ma.Finish(); err != nil {
+			return invalid(path, err)
+		}
+		return nil
+	case datamodel.Kind_List:
+		la, err := na.BeginList(n.Length())
+		if err != nil {
+			return invalid(path, err)
+		}
+		itr := n.ListIterator()
+		for !itr.Done() {
+			idx, v, err := itr.Next()
+			if err != nil {
+				return invalid(path, err)
+			}
+			if err := assembleJOSEPath(object, path.AppendSegmentInt(idx), v, la.AssembleValue()); err != nil {
+				return err
+			}
+		}
+		if err := la.Finish(); err != nil {
+			return invalid(path, err)
+		}
+		return nil
+	default:
+		if err := na.AssignNode(n); err != nil {
+			return invalid(path, err)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,307 @@
+package dagjose
+
+import (
+	"crypto"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	_ "crypto/sha512" // registers crypto.SHA384 and crypto.SHA512
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	secp256k1ecdsa "github.com/decred/dcrd/dcrec/secp256k1/v4/ecdsa"
+	gojose "github.com/go-jose/go-jose/v4"
+	"github.com/go-jose/go-jose/v4/json"
+	"github.com/ipld/go-ipld-prime/datamodel"
+	"github.com/ipld/go-ipld-prime/schema"
+)
+
+// ES256K is the JWS algorithm for ECDSA using the secp256k1 curve and SHA-256, which go-jose does not define. Keys for
+// this algorithm are *secp256k1.PrivateKey and *secp256k1.PublicKey.
+// See: https://datatracker.ietf.org/doc/html/rfc8812#section-3.2
+const ES256K gojose.SignatureAlgorithm = "ES256K"
+
+// SignatureVerification is the result of verifying a single entry from the `signatures` list of a JWS.
+type SignatureVerification struct {
+	// Index is the position of the signature in the `signatures` list.
+	Index int
+	// Algorithm is the `alg` header parameter of the signature.
+	Algorithm gojose.SignatureAlgorithm
+	// KeyID is the `kid` header parameter of the signature, if present.
+	KeyID string
+	// Key is the public key that verified the signature, or nil if verification failed.
+	Key crypto.PublicKey
+	// Err describes why the signature could not be verified, or is nil if verification succeeded.
+	Err error
+}
+
+// Valid returns true if the signature was verified by one of the supplied keys.
+func (v SignatureVerification) Valid() bool {
+	return v.Err == nil && v.Key != nil
+}
+
+// VerifyJWS verifies every signature of the given JWS node against the supplied public keys and returns one result per
+// signature, in the order they appear in the `signatures` list. A signature is considered valid if any of the supplied
+// keys verifies it, which allows multi-signature JWS objects to be partially trusted.
+//
+// The node may be the output of Decode, or any general or flattened JWS node. Keys may be ed25519.PublicKey,
+// *ecdsa.PublicKey, *secp256k1.PublicKey (for ES256K), *rsa.PublicKey, the corresponding private keys, or go-jose
+// JSONWebKey values wrapping any of these.
+// If a JSONWebKey has a key ID, it is only tried against signatures with the same `kid`.
+//
+// An error is only returned if the node is not a JWS. Failures to verify individual signatures are reported through the
+// returned results.
+func VerifyJWS(n datamodel.Node, keys ...crypto.PublicKey) ([]SignatureVerification, error) {
+	return verifyJWS(n, func(map[string]interface{}) ([]crypto.PublicKey, error) {
+		return keys, nil
+	})
+}
+
+// verificationKeys returns the candidate keys for verifying a signature with the given header parameters.
+type verificationKeys func(headers map[string]interface{}) ([]crypto.PublicKey, error)
+
+func verifyJWS(n datamodel.Node, keysFor verificationKeys) ([]SignatureVerification, error) {
+	jws, err := asDecodedJWS(n)
+	if err != nil {
+		return nil, err
+	}
+	if !jws.signatures.Exists() || len(jws.signatures.v.x) == 0 {
+		return nil, errors.New("JWS has no signatures")
+	}
+	payload, _ := jws.payload.AsBytes()
+	results := make([]SignatureVerification, 0, len(jws.signatures.v.x))
+	itr := jws.signatures.v.Iterator()
+	for !itr.Done() {
+		idx, sig := itr.Next()
+		results = append(results, verifySignature(int(idx), sig, payload, keysFor))
+	}
+	return results, nil
+}
+
+func verifySignature(idx int, sig DecodedSignature, payload []byte, keysFor verificationKeys) SignatureVerification {
+	result := SignatureVerification{Index: idx}
+	headers, err := signatureHeaders(sig)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+	if alg, ok := headers["alg"].(string); !ok {
+		result.Err = errors.New("missing or invalid `alg` header parameter")
+		return result
+	} else {
+		result.Algorithm = gojose.SignatureAlgorithm(alg)
+	}
+	if kid, ok := headers["kid"].(string); ok {
+		result.KeyID = kid
+	}
+	// `crit` and `b64` must be integrity protected
+	// See: https://datatracker.ietf.org/doc/html/rfc7515#section-4.1.11
+	for _, param := range []string{"crit", "b64"} {
+		if _, found := headers[param]; found {
+			if sig.header.Exists() {
+				if value, _ := sig.header.v.Representation().LookupByString(param); value != nil {
+					result.Err = fmt.Errorf("`%s` header parameter must be protected", param)
+					return result
+				}
+			}
+		}
+	}
+	signingInput, err := jwsSigningInput(sig.protected, payload)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+	signature, _ := sig.signature.AsBytes()
+	keys, err := keysFor(headers)
+	if err != nil {
+		result.Err = err
+		return result
+	} else if len(keys) == 0 {
+		result.Err = errors.New("no keys supplied")
+		return result
+	}
+	result.Err = errors.New("no matching key")
+	for _, key := range keys {
+		if kid, key := unwrapKey(key); (kid != "") && (kid != result.KeyID) {
+			continue
+		} else if err := verifyWithKey(result.Algorithm, key, signingInput, signature); err != nil {
+			result.Err = err
+		} else {
+			result.Key = key
+			result.Err = nil
+			break
+		}
+	}
+	return result
+}
+
+// signatureHeaders returns the union of the protected and unprotected header parameters of a signature. Per RFC 7515,
+// the two sets of parameters must be disjoint.
+func signatureHeaders(sig DecodedSignature) (map[string]interface{}, error) {
+	headers := make(map[string]interface{})
+	if sig.protected.Exists() {
+		protected, _ := sig.protected.v.AsBytes()
+		if err := json.Unmarshal(protected, &headers); err != nil {
+			return nil, fmt.Errorf("invalid protected header: %w", err)
+		}
+	}
+	if sig.header.Exists() {
+		unprotected, err := headerFromMaybeAny(sig.header)
+		if err != nil {
+			return nil, fmt.Errorf("invalid unprotected header: %w", err)
+		}
+		for key, value := range unprotected {
+			if _, found := headers[key]; found {
+				return nil, fmt.Errorf("duplicate header parameter: %s", key)
+			}
+			headers[key] = value
+		}
+	}
+	return headers, nil
+}
+
+// unwrapKey returns the key ID (if any) and the public key corresponding to the given key.
+func unwrapKey(key crypto.PublicKey) (string, crypto.PublicKey) {
+	kid := ""
+	switch k := key.(type) {
+	case gojose.JSONWebKey:
+		kid, key = k.KeyID, k.Key
+	case *gojose.JSONWebKey:
+		kid, key = k.KeyID, k.Key
+	}
+	if signer, castOk := key.(crypto.Signer); castOk {
+		key = signer.Public()
+	} else if privateKey, castOk := key.(*secp256k1.PrivateKey); castOk {
+		key = privateKey.PubKey()
+	} else if privateKey, castOk := key.(*ecdh.PrivateKey); castOk {
+		key = privateKey.PublicKey()
+	}
+	return kid, key
+}
+
+func verifyWithKey(alg gojose.SignatureAlgorithm, key crypto.PublicKey, signingInput []byte, signature []byte) error {
+	switch alg {
+	case gojose.EdDSA:
+		if publicKey, castOk := key.(ed25519.PublicKey); !castOk {
+			return fmt.Errorf("invalid key type for %s: %T", alg, key)
+		} else if !ed25519.Verify(publicKey, signingInput, signature) {
+			return errors.New("signature verification failed")
+		}
+		return nil
+	case gojose.ES256, gojose.ES384, gojose.ES512:
+		if publicKey, castOk := key.(*ecdsa.PublicKey); !castOk {
+			return fmt.Errorf("invalid key type for %s: %T", alg, key)
+		} else {
+			return verifyECDSA(publicKey, hashFor(alg), signingInput, signature)
+		}
+	case ES256K:
+		if publicKey, castOk := key.(*secp256k1.PublicKey); !castOk {
+			return fmt.Errorf("invalid key type for %s: %T", alg, key)
+		} else {
+			return verifyES256K(publicKey, signingInput, signature)
+		}
+	case gojose.RS256, gojose.RS384, gojose.RS512, gojose.PS256, gojose.PS384, gojose.PS512:
+		if publicKey, castOk := key.(*rsa.PublicKey); !castOk {
+			return fmt.Errorf("invalid key type for %s: %T", alg, key)
+		} else {
+			return verifyRSA(alg, publicKey, signingInput, signature)
+		}
+	default:
+		return fmt.Errorf("unsupported signature algorithm: %s", alg)
+	}
+}
+
+func verifyECDSA(publicKey *ecdsa.PublicKey, hash crypto.Hash, signingInput []byte, signature []byte) error {
+	// ECDSA signatures are the fixed-length concatenation of the big-endian R and S values.
+	// See: https://datatracker.ietf.org/doc/html/rfc7518#section-3.4
+	keySize := (publicKey.Curve.Params().BitSize + 7) / 8
+	if len(signature) != 2*keySize {
+		return fmt.Errorf("invalid signature length: %d", len(signature))
+	}
+	r := new(big.Int).SetBytes(signature[:keySize])
+	s := new(big.Int).SetBytes(signature[keySize:])
+	hasher := hash.New()
+	hasher.Write(signingInput)
+	if !ecdsa.Verify(publicKey, hasher.Sum(nil), r, s) {
+		return errors.New("signature verification failed")
+	}
+	return nil
+}
+
+// verifyES256K verifies a 64-byte R||S signature of the signing input. Signatures with a high S value are rejected,
+// since for every valid signature (R, S) the signature (R, N-S) is also valid.
+func verifyES256K(publicKey *secp256k1.PublicKey, signingInput []byte, signature []byte) error {
+	if len(signature) != 2*secp256k1CoordinateSize {
+		return fmt.Errorf("invalid signature length: %d", len(signature))
+	}
+	var r, s secp256k1.ModNScalar
+	if overflow := r.SetByteSlice(signature[:secp256k1CoordinateSize]); overflow || r.IsZero() {
+		return errors.New("invalid signature: R is out of range")
+	} else if overflow := s.SetByteSlice(signature[secp256k1CoordinateSize:]); overflow || s.IsZero() {
+		return errors.New("invalid signature: S is out of range")
+	} else if s.IsOverHalfOrder() {
+		return errors.New("invalid signature: S is not normalized to the lower half of the curve order")
+	}
+	hash := sha256.Sum256(signingInput)
+	if !secp256k1ecdsa.NewSignature(&r, &s).Verify(hash[:], publicKey) {
+		return errors.New("signature verification failed")
+	}
+	return nil
+}
+
+func verifyRSA(alg gojose.SignatureAlgorithm, publicKey *rsa.PublicKey, signingInput []byte, signature []byte) error {
+	hash := hashFor(alg)
+	hasher := hash.New()
+	hasher.Write(signingInput)
+	switch alg {
+	case gojose.PS256, gojose.PS384, gojose.PS512:
+		return rsa.VerifyPSS(publicKey, hash, hasher.Sum(nil), signature, &rsa.PSSOptions{
+			SaltLength: rsa.PSSSaltLengthEqualsHash,
+		})
+	default:
+		return rsa.VerifyPKCS1v15(publicKey, hash, hasher.Sum(nil), signature)
+	}
+}
+
+func hashFor(alg gojose.SignatureAlgorithm) crypto.Hash {
+	switch alg {
+	case gojose.ES384, gojose.RS384, gojose.PS384:
+		return crypto.SHA384
+	case gojose.ES512, gojose.RS512, gojose.PS512:
+		return crypto.SHA512
+	default:
+		return crypto.SHA256
+	}
+}
+
+// asDecodedJWS returns a typed JWS node corresponding to the given node, which may be in general or flattened form.
+func asDecodedJWS(n datamodel.Node) (DecodedJWS, error) {
+	switch jws := n.(type) {
+	case *_DecodedJWS:
+		return jws, nil
+	case *_DecodedJWS__Repr:
+		return (*_DecodedJWS)(jws), nil
+	}
+	if tn, castOk := n.(schema.TypedNode); castOk {
+		// The "representation" node gives an accurate view of fields that are actually present
+		n = tn.Representation()
+	}
+	if jws, err := isJWS(n); err != nil {
+		return nil, err
+	} else if !jws {
+		return nil, &ErrInvalidSerialization{Object: "JWS", Reason: "not a JWS"}
+	}
+	if n, err := unflattenJWS(n); err != nil {
+		return nil, err
+	} else {
+		jwsBuilder := Type.DecodedJWS__Repr.NewBuilder()
+		if err := assembleJOSE("JWS", n, jwsBuilder); err != nil {
+			return nil, err
+		}
+		return jwsBuilder.Build().(DecodedJWS), nil
+	}
+}
@@ -0,0 +1,183 @@
+package dagjose
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/hex"
+	"testing"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	gojose "github.com/go-jose/go-jose/v4"
+	"github.com/ipld/go-ipld-prime/datamodel"
+	"github.com/ipld/go-ipld-prime/node/basicnode"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ed25519"
+	"pgregory.net/rapid"
+)
+
+// decodeJOSEJSON converts a JSON serialized JWS/JWE into DAG-JOSE bytes and back, returning the decoded node.
+func decodeJOSEJSON(t require.TestingT, jsonBytes []byte) datamodel.Node {
+	jose, err := FromJSON(bytes.NewReader(jsonBytes))
+	require.NoError(t, err)
+	buf := bytes.Buffer{}
+	require.NoError(t, Encode(jose, &buf))
+	nb := basicnode.Prototype.Any.NewBuilder()
+	require.NoError(t, Decode(nb, &buf))
+	return nb.Build()
+}
+
+func TestVerifyValidJWS(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		link := cidGen().Draw(t, "payload")
+		privateKey := ed25519PrivateKeyGen().Draw(t, "private key")
+		signer, err := gojose.NewSigner(gojose.SigningKey{Algorithm: gojose.EdDSA, Key: privateKey}, nil)
+		require.NoError(t, err)
+		joseJws, err := signer.Sign(link.Bytes())
+		require.NoError(t, err)
+		results, err := VerifyJWS(decodeJOSEJSON(t, []byte(joseJws.FullSerialize())), privateKey.Public())
+		require.NoError(t, err)
+		require.Len(t, results, 1)
+		require.True(t, results[0].Valid(), "%v", results[0].Err)
+		require.Equal(t, gojose.EdDSA, results[0].Algorithm)
+	})
+}
+
+func TestVerifyJWSWithWrongKey(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		link := cidGen().Draw(t, "payload")
+		privateKey := ed25519PrivateKeyGen().Draw(t, "private key")
+		otherKey := ed25519PrivateKeyGen().Filter(func(k ed25519.PrivateKey) bool {
+			return !k.Equal(privateKey)
+		}).Draw(t, "other private key")
+		signer, err := gojose.NewSigner(gojose.SigningKey{Algorithm: gojose.EdDSA, Key: privateKey}, nil)
+		require.NoError(t, err)
+		joseJws, err := signer.Sign(link.Bytes())
+		require.NoError(t, err)
+		results, err := VerifyJWS(decodeJOSEJSON(t, []byte(joseJws.FullSerialize())), otherKey.Public())
+		require.NoError(t, err)
+		require.Len(t, results, 1)
+		require.False(t, results[0].Valid())
+	})
+}
+
+// A multi-signature JWS should be partially trusted if only some of the keys are known
+func TestVerifyMultiSignatureJWS(t *testing.T) {
+	link := createCid([]byte("payload"))
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	signer, err := gojose.NewMultiSigner([]gojose.SigningKey{
+		{Algorithm: gojose.EdDSA, Key: edKey},
+		{Algorithm: gojose.ES256, Key: ecKey},
+		{Algorithm: gojose.RS256, Key: rsaKey},
+	}, nil)
+	require.NoError(t, err)
+	joseJws, err := signer.Sign(link.Bytes())
+	require.NoError(t, err)
+	jws := decodeJOSEJSON(t, []byte(joseJws.FullSerialize()))
+
+	results, err := VerifyJWS(jws, edKey.Public(), &rsaKey.PublicKey)
+	require.NoError(t, err)
+	require.Len(t, results, 3)
+	require.True(t, results[0].Valid(), "%v", results[0].Err)
+	require.False(t, results[1].Valid())
+	require.True(t, results[2].Valid(), "%v", results[2].Err)
+
+	results, err = VerifyJWS(jws, edKey, ecKey, rsaKey)
+	require.NoError(t, err)
+	for _, result := range results {
+		require.True(t, result.Valid(), "%v", result.Err)
+	}
+}
+
+// A JSONWebKey with a key ID should only be used for signatures with a matching `kid`
+func TestVerifyJWSMatchesKeyID(t *testing.T) {
+	link := createCid([]byte("payload"))
+	_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	signer, err := gojose.NewSigner(gojose.SigningKey{
+		Algorithm: gojose.EdDSA,
+		Key:       gojose.JSONWebKey{Key: privateKey, KeyID: "key-1"},
+	}, nil)
+	require.NoError(t, err)
+	joseJws, err := signer.Sign(link.Bytes())
+	require.NoError(t, err)
+	jws := decodeJOSEJSON(t, []byte(joseJws.FullSerialize()))
+
+	results, err := VerifyJWS(jws, gojose.JSONWebKey{Key: privateKey.Public(), KeyID: "key-2"})
+	require.NoError(t, err)
+	require.False(t, results[0].Valid())
+	require.Equal(t, "key-1", results[0].KeyID)
+
+	results, err = VerifyJWS(jws, gojose.JSONWebKey{Key: privateKey.Public(), KeyID: "key-1"})
+	require.NoError(t, err)
+	require.True(t, results[0].Valid(), "%v", results[0].Err)
+}
+
+func TestVerifyJWSRejectsJWE(t *testing.T) {
+	jwe := decodeJOSEJSON(t, []byte("{\"ciphertext\": \"YWJj\"}"))
+	_, err := VerifyJWS(jwe)
+	require.Error(t, err)
+}
+
+// An ES256K JWS produced by an independent implementation (Node.js crypto) should verify
+func TestVerifyES256KJWS(t *testing.T) {
+	const compactJWS = "eyJhbGciOiJFUzI1NksifQ.AXESICOfWe1V5zfHcUfPVa0MGwMLbX7nSKdCaVL5uFLVqTXl." +
+		"QWpnjMC4d-WCj6Noor8JAxdKPtOhjgjmn_mPpKVyviF7YIyxNBHgcqVzDpD6nQh7UYbybD_XKVbLPJpnzO8niQ"
+	publicKeyBytes, err := hex.DecodeString("042c8c31fc9f990c6b55e3865a184a4ce50e09481f2eaeb3e60ec1cea13a6ae645" +
+		"64b95e4fdb6948c0386e189b006a29f686769b011704275e4459822dc3328085")
+	require.NoError(t, err)
+	publicKey, err := secp256k1.ParsePubKey(publicKeyBytes)
+	require.NoError(t, err)
+	jws, err := ParseCompactJWS(compactJWS)
+	require.NoError(t, err)
+
+	results, err := VerifyJWS(jws, publicKey)
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	require.True(t, results[0].Valid(), "%v", results[0].Err)
+	require.Equal(t, ES256K, results[0].Algorithm)
+}
+
+func TestVerifyES256KRejectsMalformedSignatures(t *testing.T) {
+	privateKey, err := secp256k1.GeneratePrivateKey()
+	require.NoError(t, err)
+	signingInput := []byte("header.payload")
+	signature := signES256K(privateKey, signingInput)
+	require.NoError(t, verifyES256K(privateKey.PubKey(), signingInput, signature))
+
+	// (R, N-S) is a valid but malleated signature
+	var s secp256k1.ModNScalar
+	s.SetByteSlice(signature[32:])
+	s.Negate()
+	highS := append([]byte{}, signature...)
+	s.PutBytesUnchecked(highS[32:])
+	require.ErrorContains(t, verifyES256K(privateKey.PubKey(), signingInput, highS), "normalized")
+
+	overflowR := append([]byte{}, signature...)
+	copy(overflowR[:32], bytes.Repeat([]byte{0xff}, 32))
+	zeroS := append(append([]byte{}, signature[:32]...), make([]byte, 32)...)
+	for _, invalid := range [][]byte{signature[:63], append(signature, 0), overflowR, zeroS} {
+		require.Error(t, verifyES256K(privateKey.PubKey(), signingInput, invalid))
+	}
+	require.Error(t, verifyES256K(privateKey.PubKey(), []byte("other input"), signature))
+}
+
+// ES256K signatures with a secp256k1 did:key `kid` should be verified by the did:key resolver
+func TestES256KWithDIDKeyResolver(t *testing.T) {
+	privateKey, err := secp256k1.GeneratePrivateKey()
+	require.NoError(t, err)
+	did := didKey(t, multicodecSecp256k1Pub, privateKey.PubKey().SerializeCompressed())
+	jws, err := SignCID(createCid([]byte("payload")), Signer{Algorithm: ES256K, Key: privateKey, KeyID: did})
+	require.NoError(t, err)
+	results, err := VerifyJWSWithResolver(context.Background(), roundTripJWS(t, jws), DIDKeyResolver{})
+	require.NoError(t, err)
+	require.True(t, results[0].Valid(), "%v", results[0].Err)
+}